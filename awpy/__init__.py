@@ -1,6 +1,6 @@
 """Provides data parsing, analytics and visualization capabilities for CSGO data."""
 
-from awpy.demo import Demo
+from awpy.demo import Demo, DemoOptions
 
 __version__ = "2.0.0-alpha"
-__all__ = ["Demo"]
+__all__ = ["Demo", "DemoOptions"]
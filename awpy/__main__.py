@@ -0,0 +1,6 @@
+"""Entry point for `python -m awpy`, e.g., in frozen or script-less installs."""
+
+from awpy.cli import awpy
+
+if __name__ == "__main__":
+    awpy(prog_name="awpy")
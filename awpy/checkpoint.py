@@ -0,0 +1,131 @@
+"""Checkpoints of intermediate parser outputs, to resume interrupted parses."""
+
+import json
+import zipfile
+from pathlib import Path
+from typing import Optional
+
+import pandas as pd
+
+FINGERPRINT_FILE = "fingerprint.json"
+
+
+class Checkpoint:
+    """Zip file with the parser outputs done so far for a demo.
+
+    Every output is written as soon as it is parsed, so a parse that crashes
+    or is killed can pick up from the last finished output. A checkpoint is
+    only used for the same demo file parsed with the same options, as given by
+    its fingerprint, and is started over otherwise.
+    """
+
+    def __init__(self, path: Path, fingerprint: dict) -> None:
+        """Open a checkpoint, or start a new one.
+
+        Args:
+            path (Path): Path to the checkpoint zip file.
+            fingerprint (dict): The demo file and parse options that the
+                outputs depend on. Must be JSON serializable.
+        """
+        self.path = Path(path)
+        self.fingerprint = json.loads(json.dumps(fingerprint))
+        self.was_unreadable = False  # Whether a cut off checkpoint was replaced
+
+        if self.path.exists():
+            fingerprint_on_disk = self._read_fingerprint()
+            self.was_unreadable = fingerprint_on_disk is None
+            if fingerprint_on_disk != self.fingerprint:
+                self.path.unlink()
+        if not self.path.exists():
+            with zipfile.ZipFile(self.path, "w") as zipf:
+                zipf.writestr(FINGERPRINT_FILE, json.dumps(self.fingerprint))
+
+    def _read_fingerprint(self) -> Optional[dict]:
+        """Read the fingerprint of the checkpoint on disk.
+
+        Returns:
+            Optional[dict]: The fingerprint, or None if the checkpoint is
+                unreadable, e.g., because it was cut off mid-write.
+        """
+        try:
+            with zipfile.ZipFile(self.path, "r") as zipf:
+                return json.loads(zipf.read(FINGERPRINT_FILE))
+        except (zipfile.BadZipFile, KeyError, json.JSONDecodeError):
+            return None
+
+    def _names(self) -> list[str]:
+        """Get the names of the files in the checkpoint."""
+        with zipfile.ZipFile(self.path, "r") as zipf:
+            return zipf.namelist()
+
+    def has(self, name: str) -> bool:
+        """Whether an output is in the checkpoint.
+
+        Args:
+            name (str): Name of the output, e.g., `ticks`.
+
+        Returns:
+            bool: True if the output was saved.
+        """
+        return f"{name}.data" in self._names()
+
+    def load(self, name: str) -> Optional[pd.DataFrame]:
+        """Load an output from the checkpoint.
+
+        Args:
+            name (str): Name of the output, e.g., `ticks`.
+
+        Returns:
+            Optional[pd.DataFrame]: The output, or None if it was not saved.
+        """
+        if not self.has(name):
+            return None
+        with zipfile.ZipFile(self.path, "r") as zipf, zipf.open(f"{name}.data") as f:
+            return pd.read_parquet(f)
+
+    def save(self, name: str, df: pd.DataFrame) -> None:
+        """Save an output to the checkpoint.
+
+        Args:
+            name (str): Name of the output, e.g., `ticks`.
+            df (pd.DataFrame): The output.
+        """
+        if self.has(name):
+            return
+        with zipfile.ZipFile(self.path, "a") as zipf:
+            zipf.writestr(
+                f"{name}.data",
+                df.to_parquet(index=False),
+                compress_type=zipfile.ZIP_STORED,
+            )
+
+    def load_events(self) -> Optional[dict[str, pd.DataFrame]]:
+        """Load the raw events from the checkpoint.
+
+        Returns:
+            Optional[dict[str, pd.DataFrame]]: The events, or None if they were
+                not all saved.
+        """
+        names = self._names()
+        if "events/complete" not in names:
+            return None
+        return {
+            name.removeprefix("events/").removesuffix(".data"): self.load(
+                name.removesuffix(".data")
+            )
+            for name in names
+            if name.startswith("events/") and name.endswith(".data")
+        }
+
+    def save_events(self, events: dict[str, pd.DataFrame]) -> None:
+        """Save the raw events to the checkpoint.
+
+        Args:
+            events (dict[str, pd.DataFrame]): The events, by name.
+        """
+        for event_name, event in events.items():
+            self.save(f"events/{event_name}", event)
+
+        # Mark the events as done, so partially saved events are not loaded
+        with zipfile.ZipFile(self.path, "a") as zipf:
+            zipf.writestr("events/complete", "")
@@ -1,12 +1,455 @@
 """Command-line interface for Awpy."""
 
+import functools
+import json
+import tempfile
+import urllib.error
+import urllib.request
+import zipfile
+from collections.abc import Callable
+from dataclasses import fields
 from pathlib import Path
 from typing import Literal, Optional
 
 import click
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
 from loguru import logger
 
-from awpy import Demo
+from awpy import Demo, DemoOptions
+from awpy.data.equipment_data import GAME_VERSIONS
+from awpy.demo import parse_header
+from awpy.parsers.economy import get_prices
+from awpy.parsers.highlights import (
+    POST_KILL_SECONDS,
+    PRE_KILL_SECONDS,
+    parse_kill_cameras,
+    to_hlae_script,
+)
+from awpy.parsers.places import (
+    PLACE_POLYGON_CELL_SIZE,
+    extract_place_polygons,
+    to_geojson,
+    to_place_json,
+)
+from awpy.parsers.rounds import parse_tick_range
+from awpy.parsers.utils import get_events_in_range
+
+# Seconds to wait for the notification endpoint to respond
+NOTIFY_TIMEOUT_SECONDS = 10
+
+# `awpy parse` options passed on to Demo.compress
+COMPRESS_OPTIONS = [
+    "field_case",
+    "split_events",
+    "partition_by",
+    "delta_ticks",
+    "float32",
+]
+
+
+def split_commas(
+    _ctx: click.Context, _param: click.Parameter, value: Optional[str]
+) -> Optional[list[str]]:
+    """Split a comma-separated option, e.g., `--players`, into a list.
+
+    Args:
+        _ctx (click.Context): The click context.
+        _param (click.Parameter): The option.
+        value (str, optional): The option's value.
+
+    Returns:
+        list[str]: The comma-separated values, or None if the option is unset.
+    """
+    return value.split(",") if value else None
+
+
+# Options of the commands that parse a demo, passed to them as one DemoOptions
+DEMO_OPTIONS = [
+    click.option(
+        "--sanitize",
+        is_flag=True,
+        default=False,
+        help="Remove invalid positions from ticks and events.",
+    ),
+    click.option(
+        "--scrim",
+        is_flag=True,
+        default=False,
+        help="Drop junk rounds from practice plugins on scrim servers.",
+    ),
+    click.option(
+        "--frame-interval",
+        type=str,
+        help="Interval between parsed ticks, in ticks (e.g., 16) or Hz (e.g., 4hz).",
+    ),
+    click.option(
+        "--chat-commands",
+        is_flag=True,
+        default=False,
+        help="Parse pug and match bot commands (e.g., .ready) from chat.",
+    ),
+    click.option(
+        "--bomb-markers",
+        is_flag=True,
+        default=False,
+        help="Parse the ticks of bomb sounds, e.g., to sync a demo to VOD audio.",
+    ),
+    click.option(
+        "--view-rays",
+        is_flag=True,
+        default=False,
+        help="Add each alive player's view ray and field of view to the ticks.",
+    ),
+    click.option(
+        "--near-misses",
+        is_flag=True,
+        default=False,
+        help="Parse shots that went close to an enemy without hitting them.",
+    ),
+    click.option(
+        "--spawns",
+        is_flag=True,
+        default=False,
+        help="Parse spawns, and the team keys and alive counts based on them.",
+    ),
+    click.option(
+        "--survival",
+        is_flag=True,
+        default=False,
+        help="Parse how long each player survived each round. Implies --spawns.",
+    ),
+    click.option(
+        "--network",
+        is_flag=True,
+        default=False,
+        help="Parse a timeline of each player's ping.",
+    ),
+    click.option(
+        "--activity",
+        is_flag=True,
+        default=False,
+        help="Flag bots, AFK players and players controlling a bot.",
+    ),
+    click.option(
+        "--dropped-weapons",
+        is_flag=True,
+        default=False,
+        help="Track the guns dropped by dead players until they are picked up.",
+    ),
+    click.option(
+        "--agents",
+        is_flag=True,
+        default=False,
+        help="Parse the agent model each player uses on each side.",
+    ),
+    click.option(
+        "--zones",
+        is_flag=True,
+        default=False,
+        help="Parse the bounds of each bombsite and buy zone, and executes.",
+    ),
+    click.option(
+        "--place-times",
+        is_flag=True,
+        default=False,
+        help="Parse the seconds each player spent in each place of each round.",
+    ),
+    click.option(
+        "--bomb-warnings",
+        is_flag=True,
+        default=False,
+        help="Parse warnings for dropped bombs that were neglected.",
+    ),
+    click.option(
+        "--buy-log",
+        is_flag=True,
+        default=False,
+        help="Parse the buys and refunds of each player in the buy menu.",
+    ),
+    click.option(
+        "--anonymize",
+        is_flag=True,
+        default=False,
+        help="Replace Steam IDs with salted hashes and names with aliases.",
+    ),
+    click.option("--salt", type=str, help="Salt to keep aliases stable across demos."),
+    click.option(
+        "--redact",
+        type=click.Path(exists=True),
+        help="JSON redaction policy, to share output without chat text or names.",
+    ),
+    click.option(
+        "--workers",
+        type=int,
+        default=1,
+        help="Number of processes to parse ticks and grenades in.",
+    ),
+    click.option("--round-range", type=str, help="Rounds to keep, e.g., 5-12."),
+    click.option("--from-tick", type=int, help="First tick to keep."),
+    click.option("--to-tick", type=int, help="Last tick to keep."),
+    click.option(
+        "--players",
+        type=str,
+        callback=split_commas,
+        help="Comma-separated Steam IDs of the players to keep.",
+    ),
+    click.option(
+        "--checkpoint",
+        type=click.Path(),
+        help="Zip file to save progress to, to resume an interrupted parse.",
+    ),
+    click.option(
+        "--place-polygons",
+        type=click.Path(exists=True),
+        help="JSON file of place polygons, for maps without place names.",
+    ),
+    click.option(
+        "--crash-dump",
+        type=click.Path(),
+        help="JSON file to write the parser's state to if the parse fails.",
+    ),
+    click.option(
+        "--recording-end",
+        type=str,
+        help="When the recording ended, e.g., 2024-03-01T12:00:00Z, for wall times.",
+    ),
+]
+
+
+def pass_options(
+    name: str, option_names: list[str], build: Callable[..., object]
+) -> Callable:
+    """Pass some of a command's options to it as one argument.
+
+    This keeps commands with many options, like `awpy parse`, to a few
+    arguments, e.g., every DemoOptions field is passed as one DemoOptions.
+
+    Args:
+        name (str): Name of the argument to pass the options as.
+        option_names (list[str]): Names of the options to group. Names that
+            the command has no option for are skipped.
+        build (Callable[..., object]): Called with the options as keyword
+            arguments to build the argument, e.g., DemoOptions.
+
+    Returns:
+        Callable: Decorator for the command's function.
+    """
+
+    def decorator(command: Callable) -> Callable:
+        @functools.wraps(command)
+        def wrapper(*args: object, **kwargs: object) -> object:
+            options = {
+                option_name: kwargs.pop(option_name)
+                for option_name in option_names
+                if option_name in kwargs
+            }
+            return command(*args, **kwargs, **{name: build(**options)})
+
+        return wrapper
+
+    return decorator
+
+
+def demo_options(command: Callable) -> Callable:
+    """Add the DEMO_OPTIONS to a command, passed to it as `options`.
+
+    Args:
+        command (Callable): The command's function.
+
+    Returns:
+        Callable: The command's function, taking a DemoOptions.
+    """
+    command = pass_options(
+        "options", [field.name for field in fields(DemoOptions)], DemoOptions
+    )(command)
+    for option in reversed(DEMO_OPTIONS):
+        command = option(command)
+    return command
+
+
+def post_notification(
+    url: str, payload: dict, timeout: float = NOTIFY_TIMEOUT_SECONDS
+) -> None:
+    """POST a JSON notification, e.g., to a webhook that queues the next job.
+
+    A failed notification is logged, and doesn't fail the parse. Only `http`
+    and `https` URLs are allowed, which `parse` checks before parsing.
+
+    Args:
+        url (str): The URL to POST to.
+        payload (dict): The JSON body.
+        timeout (float, optional): Seconds to wait for a response. Defaults to
+            NOTIFY_TIMEOUT_SECONDS.
+    """
+    request = urllib.request.Request(  # noqa: S310
+        url,
+        data=json.dumps(payload, default=str).encode(),
+        headers={"Content-Type": "application/json"},
+        method="POST",
+    )
+    try:
+        with urllib.request.urlopen(request, timeout=timeout):  # noqa: S310
+            pass
+    except (urllib.error.URLError, TimeoutError) as e:
+        notify_failed_msg = f"Could not notify {url}: {e}"
+        logger.warning(notify_failed_msg)
+
+
+def format_parse_metrics(
+    demo_path: Path, parse_stats: dict, *, succeeded: bool = True
+) -> str:
+    """Format parse stats as Prometheus metrics, in the text exposition format.
+
+    Write them to the directory of node_exporter's textfile collector to scrape
+    the parses of a worker.
+
+    Args:
+        demo_path (Path): Path to the parsed demo.
+        parse_stats (dict): The parse stats, from `Demo.parse_stats`. Empty if
+            the parse failed.
+        succeeded (bool, optional): Whether the parse succeeded. Defaults to
+            True.
+
+    Returns:
+        str: The metrics, one per line.
+    """
+    metrics = [
+        ("awpy_parse_success", "Whether the last parse succeeded.", int(succeeded)),
+        (
+            "awpy_demo_size_bytes",
+            "Size of the last parsed demo file.",
+            demo_path.stat().st_size if demo_path.exists() else 0,
+        ),
+        (
+            "awpy_parse_duration_seconds",
+            "Wall time of the last parse.",
+            parse_stats.get("wall_time"),
+        ),
+        (
+            "awpy_parse_ticks",
+            "Ticks in the last parsed demo.",
+            parse_stats.get("n_ticks"),
+        ),
+        (
+            "awpy_parse_max_memory_mb",
+            "Peak memory of the last parse.",
+            parse_stats.get("max_memory_mb"),
+        ),
+        (
+            "awpy_parse_recovered_errors",
+            "Errors recovered from in the last parse.",
+            parse_stats.get("recovered_errors"),
+        ),
+    ]
+    lines = []
+    for name, description, value in metrics:
+        if value is None:
+            continue
+        lines.extend(
+            [f"# HELP {name} {description}", f"# TYPE {name} gauge", f"{name} {value}"]
+        )
+
+    event_counts = parse_stats.get("event_counts", {})
+    if len(event_counts) > 0:
+        lines.extend(
+            [
+                "# HELP awpy_parse_events Events of each type in the last parsed demo.",
+                "# TYPE awpy_parse_events gauge",
+            ]
+        )
+        lines.extend(
+            f'awpy_parse_events{{event="{event_name}"}} {count}'
+            for event_name, count in sorted(event_counts.items())
+        )
+    return "\n".join(lines) + "\n"
+
+
+def read_zip_tables(zip_path: Path) -> tuple[dict[str, pd.DataFrame], dict]:
+    """Read the tables and header of a parsed demo zip.
+
+    Args:
+        zip_path (Path): Path to a zip written by `awpy parse`.
+
+    Returns:
+        tuple[dict[str, pd.DataFrame], dict]: The tables, by their name without
+            extension, e.g., `events/player_death`, and the header.
+    """
+    tables = {}
+    with zipfile.ZipFile(zip_path, "r") as zipf:
+        for name in sorted(zipf.namelist()):
+            if not name.endswith(".data"):
+                continue
+            with zipf.open(name) as f:
+                tables[name.removesuffix(".data")] = pd.read_parquet(f)
+        header = (
+            json.loads(zipf.read("header.json"))
+            if "header.json" in zipf.namelist()
+            else {}
+        )
+    return tables, header
+
+
+def compare_tables(
+    previous_tables: dict[str, pd.DataFrame], current_tables: dict[str, pd.DataFrame]
+) -> dict:
+    """Compare the tables of two parses of a demo, e.g., across awpy versions.
+
+    Values are compared row by row, as strings, so only tables with the same
+    number of rows have their values compared.
+
+    Args:
+        previous_tables (dict[str, pd.DataFrame]): Tables of the previous parse.
+        current_tables (dict[str, pd.DataFrame]): Tables of the current parse.
+
+    Returns:
+        dict: The `added_tables` and `removed_tables`, and the `changed_tables`
+            with their row counts, added and removed columns, changed dtypes
+            and the number of changed values in each column.
+    """
+    changed_tables = {}
+    for name in sorted(set(previous_tables) & set(current_tables)):
+        previous_df = previous_tables[name].reset_index(drop=True)
+        current_df = current_tables[name].reset_index(drop=True)
+        common_columns = [col for col in previous_df.columns if col in current_df]
+        table_diff = {
+            "rows": [previous_df.shape[0], current_df.shape[0]],
+            "added_columns": [
+                col for col in current_df.columns if col not in previous_df
+            ],
+            "removed_columns": [
+                col for col in previous_df.columns if col not in current_df
+            ],
+            "changed_dtypes": {
+                col: [str(previous_df[col].dtype), str(current_df[col].dtype)]
+                for col in common_columns
+                if previous_df[col].dtype != current_df[col].dtype
+            },
+            "changed_values": {},
+        }
+        if previous_df.shape[0] == current_df.shape[0]:
+            for col in common_columns:
+                n_changed = int(
+                    (previous_df[col].astype(str) != current_df[col].astype(str)).sum()
+                )
+                if n_changed > 0:
+                    table_diff["changed_values"][col] = n_changed
+        if table_diff["rows"][0] != table_diff["rows"][1] or any(
+            len(table_diff[key]) > 0
+            for key in [
+                "added_columns",
+                "removed_columns",
+                "changed_dtypes",
+                "changed_values",
+            ]
+        ):
+            changed_tables[name] = table_diff
+
+    return {
+        "added_tables": sorted(set(current_tables) - set(previous_tables)),
+        "removed_tables": sorted(set(previous_tables) - set(current_tables)),
+        "changed_tables": changed_tables,
+    }
 
 
 @click.group()
@@ -29,7 +472,40 @@ def get(resource_type: Literal["usd"], resource_name: str) -> None:
 
 @awpy.command(help="Parse a Counter-Strike 2 demo file.")
 @click.argument("demo", type=click.Path(exists=True))
-@click.option("--outpath", type=click.Path(), help="Path to save the compressed demo.")
+@click.option(
+    "--outpath",
+    type=click.Path(),
+    help="Path or s3:// or gs:// URL to save the compressed demo to.",
+)
+@click.option(
+    "--field-case",
+    type=click.Choice(["snake", "camel"]),
+    default="snake",
+    help="Naming convention of the output columns.",
+)
+@click.option(
+    "--split-events",
+    is_flag=True,
+    default=False,
+    help="Save each table as its own parquet file in a directory, not a zip.",
+)
+@click.option(
+    "--partition-by",
+    callback=split_commas,
+    help="Comma-separated keys of a Hive-style layout, e.g., map,match.",
+)
+@click.option(
+    "--delta-ticks",
+    is_flag=True,
+    default=False,
+    help="Only save the tick fields that changed since each player's last frame.",
+)
+@click.option(
+    "--float32",
+    is_flag=True,
+    default=False,
+    help="Save floats and ints as 32-bit numbers to halve their size.",
+)
 @click.option("--verbose", is_flag=True, default=False, help="Enable verbose mode.")
 @click.option("--noticks", is_flag=True, default=False, help="Disable tick parsing.")
 @click.option(
@@ -38,30 +514,244 @@ def get(resource_type: Literal["usd"], resource_name: str) -> None:
     default=False,
     help="Get round information for every event.",
 )
+@demo_options
+@click.option(
+    "--debug-ticks",
+    type=str,
+    help="Print the raw events between two ticks to stderr, e.g., 1000:2000.",
+)
+@click.option(
+    "--notify-url",
+    type=str,
+    help="URL to POST a JSON summary to when the parse succeeds or fails.",
+)
+@click.option(
+    "--metrics-file",
+    type=click.Path(),
+    help="File to write Prometheus metrics of the parse to, e.g., for node_exporter.",
+)
 @click.option(
     "--player-props", multiple=True, help="List of player properties to include."
 )
 @click.option(
     "--other-props", multiple=True, help="List of other properties to include."
 )
+@pass_options("compress_options", COMPRESS_OPTIONS, dict)
 def parse(
     demo: Path,
     *,
+    options: DemoOptions,
+    compress_options: dict,
     outpath: Optional[Path] = None,
     verbose: bool = False,
     noticks: bool = False,
     norounds: bool = True,
+    debug_ticks: Optional[str] = None,
+    notify_url: Optional[str] = None,
+    metrics_file: Optional[Path] = None,
     player_props: Optional[tuple[str]] = None,
     other_props: Optional[tuple[str]] = None,
 ) -> None:
     """Parse a file given its path."""
+    if notify_url is not None and not notify_url.startswith(("http://", "https://")):
+        bad_notify_url_msg = "Notification URLs must start with http:// or https://."
+        raise click.BadParameter(bad_notify_url_msg, param_hint="--notify-url")
+    demo_path = Path(demo)  # Pathify
+    try:
+        debug_tick_range = parse_tick_range(debug_ticks) if debug_ticks else None
+        demo = Demo(
+            path=demo_path,
+            verbose=verbose,
+            ticks=not noticks,
+            rounds=not norounds,
+            player_props=player_props[0].split(",") if player_props else None,
+            other_props=other_props[0].split(",") if other_props else None,
+            options=options,
+        )
+        if debug_tick_range is not None:
+            for event in get_events_in_range(
+                demo.events, *debug_tick_range
+            ).itertuples():
+                click.echo(
+                    f"{event.tick} {event.event} "
+                    f"{json.dumps(event.fields, default=str)}",
+                    err=True,
+                )
+        demo.compress(outpath=outpath, **compress_options)
+    except Exception as e:
+        if metrics_file is not None:
+            Path(metrics_file).write_text(
+                format_parse_metrics(demo_path, {}, succeeded=False)
+            )
+        if notify_url is not None:
+            post_notification(
+                notify_url,
+                {"status": "failed", "demo": demo_path.name, "error": repr(e)},
+            )
+        raise
+
+    if metrics_file is not None:
+        Path(metrics_file).write_text(format_parse_metrics(demo_path, demo.parse_stats))
+    if notify_url is not None:
+        post_notification(
+            notify_url,
+            {
+                "status": "succeeded",
+                "demo": demo_path.name,
+                "outpath": str(outpath if outpath is not None else Path.cwd()),
+                "header": demo.header,
+                "parse_stats": demo.parse_stats,
+                "warnings": demo.warnings,
+            },
+        )
+
+
+@awpy.command(name="dump-prices", help="Print the weapon and item price table.")
+@click.option(
+    "--game",
+    type=click.Choice(GAME_VERSIONS),
+    default="cs2",
+    help="Game version of the price table.",
+)
+def dump_prices(game: str = "cs2") -> None:
+    """Print the price table for a game version as JSON."""
+    click.echo(json.dumps(get_prices(game), indent=2))
+
+
+@awpy.command(help="Print the header and game events of a demo file.")
+@click.argument("demo", type=click.Path(exists=True))
+def info(demo: Path) -> None:
+    """Print demo metadata as JSON, without parsing the whole demo."""
+    parser = DemoParser(str(demo))
+    demo_info = parse_header(parser.parse_header())
+    demo_info["game_events"] = sorted(parser.list_game_events())
+    click.echo(json.dumps(demo_info, indent=2))
+
+
+@awpy.command(help="Print the columns of each table in a parsed demo zip.")
+@click.argument("zip_path", type=click.Path(exists=True))
+def schema(zip_path: Path) -> None:
+    """Print the column types of every table written by `awpy parse`."""
+    tables = {
+        name: {col: str(dtype) for col, dtype in table.dtypes.items()}
+        for name, table in read_zip_tables(zip_path)[0].items()
+    }
+    click.echo(json.dumps(tables, indent=2))
+
+
+@awpy.command(help="Compare a new parse of a demo to a parsed demo zip.")
+@click.argument("demo", type=click.Path(exists=True))
+@click.argument("zip_path", type=click.Path(exists=True))
+@click.option("--noticks", is_flag=True, default=False, help="Disable tick parsing.")
+@demo_options
+def compare(
+    demo: Path, zip_path: Path, *, options: DemoOptions, noticks: bool = False
+) -> None:
+    """Print the differences from a previous `awpy parse` output, as JSON.
+
+    Parse the demo with the options the zip was made with, e.g., `--noticks`
+    or `--spawns`, or the skipped tables show up as removed.
+    """
+    previous_tables, previous_header = read_zip_tables(zip_path)
+
+    # Round trip through a zip, so both parses are read back the same way
+    current_demo = Demo(path=Path(demo), ticks=not noticks, options=options)
+    with tempfile.TemporaryDirectory() as tmp_dir:
+        current_demo.compress(outpath=tmp_dir)
+        current_tables, current_header = read_zip_tables(
+            Path(tmp_dir) / f"{current_demo.path.stem}.zip"
+        )
+    diff = compare_tables(previous_tables, current_tables)
+    diff["changed_header"] = {
+        key: [previous_header.get(key), current_header.get(key)]
+        for key in sorted(set(previous_header) | set(current_header))
+        if previous_header.get(key) != current_header.get(key)
+    }
+    click.echo(json.dumps(diff, indent=2, default=str))
+
+
+@awpy.command(help="Write an HLAE config that plays every kill from the killer's POV.")
+@click.argument("demo", type=click.Path(exists=True))
+@click.option("--outpath", type=click.Path(), help="Path to save the config.")
+@click.option(
+    "--pre-seconds",
+    type=float,
+    default=PRE_KILL_SECONDS,
+    help="Seconds to show before each kill.",
+)
+@click.option(
+    "--post-seconds",
+    type=float,
+    default=POST_KILL_SECONDS,
+    help="Seconds to show after each kill.",
+)
+def highlights(
+    demo: Path,
+    *,
+    outpath: Optional[Path] = None,
+    pre_seconds: float = PRE_KILL_SECONDS,
+    post_seconds: float = POST_KILL_SECONDS,
+) -> None:
+    """Export a camera clip for every kill as an HLAE config."""
     demo_path = Path(demo)  # Pathify
-    demo = Demo(
-        path=demo_path,
-        verbose=verbose,
-        ticks=not noticks,
-        rounds=not norounds,
-        player_props=player_props[0].split(",") if player_props else None,
-        other_props=other_props[0].split(",") if other_props else None,
+    parsed_demo = Demo(path=demo_path, ticks=False)
+    cameras = parse_kill_cameras(
+        parsed_demo.kills,
+        parsed_demo.tick_rate,
+        pre_seconds=pre_seconds,
+        post_seconds=post_seconds,
+    )
+    script = to_hlae_script(cameras)
+    if outpath is None:
+        click.echo(script, nl=False)
+    else:
+        Path(outpath).write_text(script)
+
+
+@awpy.command(help="Extract a polygon for each place from the positions in a demo.")
+@click.argument("demo", type=click.Path(exists=True))
+@click.option("--outpath", type=click.Path(), help="Path to save the polygons.")
+@click.option(
+    "--geojson", is_flag=True, default=False, help="Write GeoJSON instead of JSON."
+)
+@click.option(
+    "--cell-size",
+    type=float,
+    default=PLACE_POLYGON_CELL_SIZE,
+    help="Grid size, in units, to snap positions to.",
+)
+def places(
+    demo: Path,
+    *,
+    outpath: Optional[Path] = None,
+    geojson: bool = False,
+    cell_size: float = PLACE_POLYGON_CELL_SIZE,
+) -> None:
+    """Export place polygons, e.g., for `awpy parse --place-polygons`."""
+    parser = DemoParser(str(demo))
+    ticks = parser.parse_ticks(wanted_props=["X", "Y", "last_place_name"])
+    polygons = extract_place_polygons(ticks, cell_size=cell_size)
+    output = json.dumps(
+        to_geojson(polygons) if geojson else to_place_json(polygons), indent=2
+    )
+    if outpath is None:
+        click.echo(output)
+    else:
+        Path(outpath).write_text(output)
+
+
+@awpy.command(name="map-info", help="Print the bombsites and buy zones of a demo.")
+@click.argument("demo", type=click.Path(exists=True))
+def map_info(demo: Path) -> None:
+    """Print the map name and the bounding box of each zone as JSON."""
+    parsed_demo = Demo(path=Path(demo), ticks=False, options=DemoOptions(zones=True))
+    click.echo(
+        json.dumps(
+            {
+                "map_name": parsed_demo.header["map_name"],
+                "zones": parsed_demo.zones.to_dict(orient="records"),
+            },
+            indent=2,
+            default=str,
+        )
     )
-    demo.compress(outpath=outpath)
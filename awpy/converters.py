@@ -1,7 +1,130 @@
 """Converters for index-based fields."""
 
+from typing import Optional
+
 import pandas as pd
 
+from awpy.data.equipment_data import EQUIPMENT_DATA, KNIFE_PREFIXES
+
+HITGROUP_MAPPING = {
+    0: "generic",
+    1: "head",
+    2: "chest",
+    3: "stomach",
+    4: "left arm",
+    5: "right arm",
+    6: "left leg",
+    7: "right leg",
+    8: "neck",
+    9: "unused",
+    10: "gear",
+    11: "special",
+}
+
+ROUND_END_REASON_MAPPING = {
+    0: "still_in_progress",
+    1: "target_bombed",
+    2: "vip_escaped",
+    3: "vip_killed",
+    4: "t_escaped",
+    5: "ct_stopped_escape",
+    6: "t_stopped",
+    7: "bomb_defused",
+    8: "ct_win",
+    9: "t_win",
+    10: "draw",
+    11: "hostages_rescued",
+    12: "target_saved",
+    13: "hostages_not_rescued",
+    14: "t_not_escaped",
+    15: "vip_not_escaped",
+    16: "game_start",
+    17: "t_surrender",
+    18: "ct_surrender",
+    19: "t_planted",
+    20: "cts_reached_hostage",
+}
+
+GAME_PHASE_MAPPING = {
+    0: "init",
+    1: "pregame",
+    2: "startgame",
+    3: "preround",
+    4: "teamwin",
+    5: "restart",
+    6: "stalemate",
+    7: "gameover",
+}
+
+# Competitive skill groups, used by the legacy and per-map CS2 ladders
+RANK_MAPPING = {
+    0: "Unranked",
+    1: "Silver I",
+    2: "Silver II",
+    3: "Silver III",
+    4: "Silver IV",
+    5: "Silver Elite",
+    6: "Silver Elite Master",
+    7: "Gold Nova I",
+    8: "Gold Nova II",
+    9: "Gold Nova III",
+    10: "Gold Nova Master",
+    11: "Master Guardian I",
+    12: "Master Guardian II",
+    13: "Master Guardian Elite",
+    14: "Distinguished Master Guardian",
+    15: "Legendary Eagle",
+    16: "Legendary Eagle Master",
+    17: "Supreme Master First Class",
+    18: "The Global Elite",
+}
+
+RANK_TYPE_MAPPING = {
+    0: "none",
+    6: "legacy_competitive",
+    7: "wingman",
+    10: "danger_zone",
+    11: "premier",
+    12: "competitive",
+}
+
+# Lower bound of each Premier CS Rating color tier
+PREMIER_TIER_MAPPING = {
+    0: "gray",
+    5000: "light_blue",
+    10000: "blue",
+    15000: "purple",
+    20000: "pink",
+    25000: "red",
+    30000: "gold",
+}
+
+
+def invert_mapping(mapping: dict) -> dict:
+    """Invert a mapping so that names can be converted back to their indices.
+
+    Args:
+        mapping (dict): A one-to-one mapping of indices to names.
+
+    Returns:
+        dict: A mapping of names to indices.
+
+    Raises:
+        ValueError: If the mapping is not one-to-one.
+    """
+    inverted_mapping = {value: key for key, value in mapping.items()}
+    if len(inverted_mapping) != len(mapping):
+        mapping_not_invertible_msg = "Mapping has duplicate values."
+        raise ValueError(mapping_not_invertible_msg)
+    return inverted_mapping
+
+
+HITGROUP_REVERSE_MAPPING = invert_mapping(HITGROUP_MAPPING)
+ROUND_END_REASON_REVERSE_MAPPING = invert_mapping(ROUND_END_REASON_MAPPING)
+GAME_PHASE_REVERSE_MAPPING = invert_mapping(GAME_PHASE_MAPPING)
+RANK_REVERSE_MAPPING = invert_mapping(RANK_MAPPING)
+RANK_TYPE_REVERSE_MAPPING = invert_mapping(RANK_TYPE_MAPPING)
+
 
 def map_hitgroup(series: pd.Series) -> pd.Series:
     """Map hitgroups to their names.
@@ -12,20 +135,8 @@ def map_hitgroup(series: pd.Series) -> pd.Series:
     Returns:
         pd.Series: Series of hitgroup names.
     """
-    hitgroup_mapping = {
-        0: "generic",
-        1: "head",
-        2: "chest",
-        3: "stomach",
-        4: "left arm",
-        5: "right arm",
-        6: "left leg",
-        7: "right leg",
-        8: "neck",
-        10: "gear",
-    }
     return series.map(
-        lambda x: hitgroup_mapping.get(x)  # pylint: disable=unnecessary-lambda
+        lambda x: HITGROUP_MAPPING.get(x)  # pylint: disable=unnecessary-lambda
     )
 
 
@@ -38,31 +149,8 @@ def map_round_end_reasons(series: pd.Series) -> pd.Series:
     Returns:
         pd.Series: Series of round end reason names.
     """
-    round_end_reason_mapping = {
-        0: "still_in_progress",
-        1: "target_bombed",
-        2: "vip_escaped",
-        3: "vip_killed",
-        4: "t_escaped",
-        5: "ct_stopped_escape",
-        6: "t_stopped",
-        7: "bomb_defused",
-        8: "ct_win",
-        9: "t_win",
-        10: "draw",
-        11: "hostages_rescued",
-        12: "target_saved",
-        13: "hostages_not_rescued",
-        14: "t_not_escaped",
-        15: "vip_not_escaped",
-        16: "game_start",
-        17: "t_surrender",
-        18: "ct_surrender",
-        19: "t_planted",
-        20: "cts_reached_hostage",
-    }
     return series.map(
-        lambda x: round_end_reason_mapping.get(x)  # pylint: disable=unnecessary-lambda
+        lambda x: ROUND_END_REASON_MAPPING.get(x)  # pylint: disable=unnecessary-lambda
     )
 
 
@@ -75,16 +163,76 @@ def map_game_phase(series: pd.Series) -> pd.Series:
     Returns:
         pd.Series: Series of game phase names.
     """
-    game_phase_mapping = {
-        0: "init",
-        1: "pregame",
-        2: "startgame",
-        3: "preround",
-        4: "teamwin",
-        5: "restart",
-        6: "stalemate",
-        7: "gameover",
-    }
     return series.map(
-        lambda x: game_phase_mapping.get(x)  # pylint: disable=unnecessary-lambda
+        lambda x: GAME_PHASE_MAPPING.get(x)  # pylint: disable=unnecessary-lambda
+    )
+
+
+def map_rank(series: pd.Series) -> pd.Series:
+    """Map competitive skill groups to their names.
+
+    Args:
+        series (pd.Series): Series of skill group integers.
+
+    Returns:
+        pd.Series: Series of skill group names.
+    """
+    return series.map(
+        lambda x: RANK_MAPPING.get(x)  # pylint: disable=unnecessary-lambda
     )
+
+
+def map_rank_type(series: pd.Series) -> pd.Series:
+    """Map rank types (e.g., premier or wingman) to their names.
+
+    Args:
+        series (pd.Series): Series of rank type integers.
+
+    Returns:
+        pd.Series: Series of rank type names.
+    """
+    return series.map(
+        lambda x: RANK_TYPE_MAPPING.get(x)  # pylint: disable=unnecessary-lambda
+    )
+
+
+def map_premier_tier(series: pd.Series) -> pd.Series:
+    """Map Premier CS Ratings to their color tiers.
+
+    Args:
+        series (pd.Series): Series of Premier CS Ratings.
+
+    Returns:
+        pd.Series: Series of color tier names.
+    """
+
+    def _find_tier(rating: float) -> Optional[str]:
+        if pd.isna(rating) or rating <= 0:
+            return None
+        tier_floor = max(floor for floor in PREMIER_TIER_MAPPING if floor <= rating)
+        return PREMIER_TIER_MAPPING[tier_floor]
+
+    return series.map(_find_tier)
+
+
+def map_weapon_class(series: pd.Series) -> pd.Series:
+    """Map weapon names to their classes (e.g., pistol or rifle).
+
+    Args:
+        series (pd.Series): Series of weapon names, e.g., `ak47`.
+
+    Returns:
+        pd.Series: Series of weapon classes.
+    """
+
+    def _find_class(weapon: str) -> Optional[str]:
+        if not isinstance(weapon, str):
+            return None
+        weapon = weapon.removeprefix("weapon_")
+        if weapon in EQUIPMENT_DATA:
+            return EQUIPMENT_DATA[weapon]["class"]
+        if weapon.startswith(KNIFE_PREFIXES):
+            return "equipment"
+        return None
+
+    return series.map(_find_class)
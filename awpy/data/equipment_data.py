@@ -0,0 +1,151 @@
+"""Dictionary that holds equipment data for Counter-Strike 2."""
+
+# Keys are the weapon names as reported in game events, without `weapon_`
+EQUIPMENT_DATA = {
+    # Pistols
+    "glock": {"name": "Glock-18", "class": "pistol", "price": 200},
+    "hkp2000": {"name": "P2000", "class": "pistol", "price": 200},
+    "usp_silencer": {"name": "USP-S", "class": "pistol", "price": 200},
+    "elite": {"name": "Dual Berettas", "class": "pistol", "price": 300},
+    "p250": {"name": "P250", "class": "pistol", "price": 300},
+    "tec9": {"name": "Tec-9", "class": "pistol", "price": 500},
+    "fiveseven": {"name": "Five-SeveN", "class": "pistol", "price": 500},
+    "cz75a": {"name": "CZ75-Auto", "class": "pistol", "price": 500},
+    "deagle": {"name": "Desert Eagle", "class": "pistol", "price": 700},
+    "revolver": {"name": "R8 Revolver", "class": "pistol", "price": 600},
+    # SMGs
+    "mac10": {"name": "MAC-10", "class": "smg", "price": 1050},
+    "mp9": {"name": "MP9", "class": "smg", "price": 1250},
+    "mp7": {"name": "MP7", "class": "smg", "price": 1500},
+    "mp5sd": {"name": "MP5-SD", "class": "smg", "price": 1500},
+    "ump45": {"name": "UMP-45", "class": "smg", "price": 1200},
+    "p90": {"name": "P90", "class": "smg", "price": 2350},
+    "bizon": {"name": "PP-Bizon", "class": "smg", "price": 1400},
+    # Heavy
+    "nova": {"name": "Nova", "class": "heavy", "price": 1050},
+    "xm1014": {"name": "XM1014", "class": "heavy", "price": 2000},
+    "sawedoff": {"name": "Sawed-Off", "class": "heavy", "price": 1100},
+    "mag7": {"name": "MAG-7", "class": "heavy", "price": 1300},
+    "m249": {"name": "M249", "class": "heavy", "price": 5200},
+    "negev": {"name": "Negev", "class": "heavy", "price": 1700},
+    # Rifles
+    "galilar": {"name": "Galil AR", "class": "rifle", "price": 1800},
+    "famas": {"name": "FAMAS", "class": "rifle", "price": 2050},
+    "ak47": {"name": "AK-47", "class": "rifle", "price": 2700},
+    "m4a1": {"name": "M4A4", "class": "rifle", "price": 3100},
+    "m4a1_silencer": {"name": "M4A1-S", "class": "rifle", "price": 2900},
+    "ssg08": {"name": "SSG 08", "class": "rifle", "price": 1700},
+    "sg556": {"name": "SG 553", "class": "rifle", "price": 3000},
+    "aug": {"name": "AUG", "class": "rifle", "price": 3300},
+    "awp": {"name": "AWP", "class": "rifle", "price": 4750},
+    "g3sg1": {"name": "G3SG1", "class": "rifle", "price": 5000},
+    "scar20": {"name": "SCAR-20", "class": "rifle", "price": 5000},
+    # Grenades
+    "hegrenade": {"name": "High Explosive Grenade", "class": "grenade", "price": 300},
+    "flashbang": {"name": "Flashbang", "class": "grenade", "price": 200},
+    "smokegrenade": {"name": "Smoke Grenade", "class": "grenade", "price": 300},
+    "decoy": {"name": "Decoy Grenade", "class": "grenade", "price": 50},
+    "molotov": {"name": "Molotov", "class": "grenade", "price": 400},
+    "incgrenade": {"name": "Incendiary Grenade", "class": "grenade", "price": 500},
+    "inferno": {"name": "Molotov", "class": "grenade", "price": 400},
+    # Equipment
+    "knife": {"name": "Knife", "class": "equipment", "price": 0},
+    "knife_t": {"name": "Knife", "class": "equipment", "price": 0},
+    "taser": {"name": "Zeus x27", "class": "equipment", "price": 200},
+    "c4": {"name": "C4 Explosive", "class": "equipment", "price": 0},
+    "planted_c4": {"name": "C4 Explosive", "class": "equipment", "price": 0},
+    # World
+    "world": {"name": "World", "class": "world", "price": 0},
+    "worldspawn": {"name": "World", "class": "world", "price": 0},
+    "trigger_hurt": {"name": "World", "class": "world", "price": 0},
+}
+
+# Skinned knives are reported by their model, e.g., `knife_karambit` or `bayonet`
+KNIFE_PREFIXES = ("knife", "bayonet")
+
+# Inventories list display names, e.g., `AK-47`, rather than event names
+EQUIPMENT_NAMES = {
+    data["name"]: weapon for weapon, data in reversed(EQUIPMENT_DATA.items())
+}
+
+# Armor and kits are not inventory items, so they are priced separately
+ITEM_PRICES = {"kevlar": 650, "assaultsuit": 1000, "defuser": 400}
+
+# Prices above are for CS2, older games only list the items that differ
+GAME_VERSIONS = ("cs2", "csgo")
+PRICE_OVERRIDES = {
+    "cs2": {},
+    "csgo": {"incgrenade": 600},
+}
+
+# Seconds between shots when holding down fire, for guns
+WEAPON_CYCLE_TIMES = {
+    "glock": 0.15,
+    "hkp2000": 0.17,
+    "usp_silencer": 0.17,
+    "elite": 0.12,
+    "p250": 0.15,
+    "tec9": 0.12,
+    "fiveseven": 0.15,
+    "cz75a": 0.1,
+    "deagle": 0.225,
+    "revolver": 0.5,
+    "mac10": 0.075,
+    "mp9": 0.07,
+    "mp7": 0.08,
+    "mp5sd": 0.08,
+    "ump45": 0.09,
+    "p90": 0.07,
+    "bizon": 0.08,
+    "nova": 0.88,
+    "xm1014": 0.35,
+    "sawedoff": 0.85,
+    "mag7": 0.85,
+    "m249": 0.08,
+    "negev": 0.075,
+    "galilar": 0.09,
+    "famas": 0.09,
+    "ak47": 0.1,
+    "m4a1": 0.09,
+    "m4a1_silencer": 0.1,
+    "ssg08": 1.25,
+    "sg556": 0.09,
+    "aug": 0.09,
+    "awp": 1.455,
+    "g3sg1": 0.25,
+    "scar20": 0.25,
+}
+
+# Default round rewards and half lengths, e.g., `mp_startmoney` and `mp_maxrounds`
+ECONOMY_SETTINGS = {
+    "cs2": {
+        "half_rounds": 12,
+        "overtime_half_rounds": 3,
+        "start_money": 800,
+        "overtime_start_money": 12500,
+        "max_money": 16000,
+        "starting_losses": 1,
+        "loss_bonus": 1400,
+        "loss_bonus_increment": 500,
+        "max_loss_bonus": 3400,
+        "win_bonus": 3250,
+        "objective_win_bonus": 3500,
+        "bomb_plant_bonus": 800,
+        "reset_losses_on_win": False,
+    },
+    "csgo": {
+        "half_rounds": 15,
+        "overtime_half_rounds": 3,
+        "start_money": 800,
+        "overtime_start_money": 10000,
+        "max_money": 16000,
+        "starting_losses": 1,
+        "loss_bonus": 1400,
+        "loss_bonus_increment": 500,
+        "max_loss_bonus": 3400,
+        "win_bonus": 3250,
+        "objective_win_bonus": 3500,
+        "bomb_plant_bonus": 800,
+        "reset_losses_on_win": True,
+    },
+}
@@ -2,29 +2,137 @@
 
 import json
 import os
-import tempfile
+import secrets
+import sys
+import time
+import traceback
 import zipfile
+from collections.abc import Callable, Iterator
+from concurrent.futures import Future, ProcessPoolExecutor
+from dataclasses import dataclass
 from pathlib import Path
-from typing import Optional
+from typing import IO, Literal, Optional, Union
 
+import pandas as pd
 from demoparser2 import DemoParser  # pylint: disable=E0611
 from loguru import logger
 
-from awpy.parsers.clock import parse_times
+from awpy.checkpoint import Checkpoint
+from awpy.parsers.anonymize import anonymize_players
+from awpy.parsers.chat import parse_admin_events, parse_chat
+from awpy.parsers.clock import (
+    DEFAULT_TICK_RATE,
+    parse_c4_timer,
+    parse_frame_rate,
+    parse_tick_gaps,
+    parse_tick_rate,
+    parse_times,
+    parse_timing,
+    parse_wall_times,
+)
+from awpy.parsers.convars import parse_convar_changes
+from awpy.parsers.economy import (
+    build_economy_seeds,
+    forecast_economy,
+    get_game_version,
+    parse_buy_log,
+    parse_equipment_values,
+    parse_round_balances,
+    parse_victim_equipment,
+    parse_zeus_purchases,
+)
 from awpy.parsers.events import (
+    parse_attacker_ammo,
+    parse_blinds,
     parse_bomb,
+    parse_bomb_markers,
+    parse_bomb_state,
+    parse_bomb_warnings,
+    parse_bursts,
     parse_damages,
+    parse_death_recaps,
+    parse_defuse_progress,
+    parse_defuses,
+    parse_dropped_weapons,
+    parse_grenade_effects,
     parse_grenades,
     parse_infernos,
+    parse_kill_contributions,
     parse_kills,
+    parse_melee_kills,
+    parse_scopes,
     parse_smokes,
+    parse_utility_timings,
     parse_weapon_fires,
 )
-from awpy.parsers.rounds import parse_rounds
-from awpy.parsers.ticks import parse_ticks
-from awpy.utils import apply_round_num
+from awpy.parsers.executes import parse_executes
+from awpy.parsers.places import (
+    fill_place_names,
+    load_place_polygons,
+    parse_place_times,
+    parse_zones,
+)
+from awpy.parsers.players import (
+    apply_team_keys,
+    get_spectating_steamids,
+    parse_activity,
+    parse_agents,
+    parse_alive_counts,
+    parse_kill_advantages,
+    parse_network,
+    parse_ranks,
+    parse_scoreboard,
+    parse_spawns,
+    parse_spectators,
+    parse_survival,
+    parse_team_keys,
+    parse_teams,
+)
+from awpy.parsers.positions import (
+    parse_crosshair_offsets,
+    parse_line_through_smoke,
+    parse_near_misses,
+    parse_nearest_players,
+    parse_smoke_places,
+    parse_supporting_teammates,
+    parse_team_shapes,
+    parse_victim_positions,
+    parse_view_rays,
+)
+from awpy.parsers.redact import load_redaction_policy, redact_chat, redact_header
+from awpy.parsers.rounds import (
+    get_tick_window,
+    parse_map_segments,
+    parse_restart_delays,
+    parse_round_range,
+    parse_round_summaries,
+    parse_rounds,
+    parse_win_streaks,
+)
+from awpy.parsers.sanitize import sanitize_positions
+from awpy.parsers.scrim import drop_junk_rounds, flag_junk_rounds
+from awpy.parsers.ticks import (
+    DELTA_KEYFRAME_SECONDS,
+    delta_encode_ticks,
+    downsample_ticks,
+    parse_frame_interval,
+    parse_keyframes,
+    parse_kill_ticks,
+    parse_ticks,
+    parse_view_velocity,
+)
+from awpy.parsers.utils import find_unknown_weapons, get_events_in_range
+from awpy.utils import (
+    apply_round_num,
+    convert_field_case,
+    downcast_numbers,
+    to_camel_case,
+)
 
 PROP_WARNING_LIMIT = 40
+PARTITION_KEYS = ("map", "game", "match")
+CRASH_DUMP_EVENTS = 100  # Raw events to keep in crash dumps
+REMOTE_PREFIXES = ("s3://", "gs://")  # Written with fsspec, e.g., s3fs or gcsfs
 DEFAULT_PLAYER_PROPS = [
     "team_name",
     "team_clan_name",
@@ -47,6 +155,10 @@ DEFAULT_PLAYER_PROPS = [
     "ping",
 ]
 
+# Player props that only the events need, e.g., for the stances in the kills
+# and the inaccuracy of weapon fires, so they don't widen every tick
+EVENT_PLAYER_PROPS = ["duck_amount", "velocity_Z", "recoil_index", "velo_modifier"]
+
 DEFAULT_WORLD_PROPS = [
     "game_time",
     "is_bomb_planted",
@@ -62,6 +174,128 @@ DEFAULT_WORLD_PROPS = [
 ]
 
 
+@dataclass
+class DemoOptions:
+    """Opt-in parsing options for `Demo`, e.g., `DemoOptions(sanitize=True)`.
+
+    Attributes:
+        sanitize (bool): Whether to remove invalid positions (e.g., (0, 0, 0)
+            or teleports) from ticks, kills and damages. Defaults to False.
+        scrim (bool): Whether to drop the junk rounds that practice plugins
+            create on scrim servers, e.g., from restart spam. Junk rounds are
+            flagged either way. Defaults to False.
+        frame_interval (Union[int, str], optional): Interval between parsed
+            ticks, either in ticks (e.g., `16`) or in Hz (e.g., `"4hz"`).
+            Defaults to None, which keeps every tick.
+        chat_commands (bool): Whether to parse pug and match bot commands
+            (e.g., `.ready`) from chat. Defaults to False.
+        bomb_markers (bool): Whether to parse the ticks of bomb sounds, like
+            beeps and the 10 second warning, e.g., to sync a demo to VOD audio.
+            Defaults to False.
+        view_rays (bool): Whether to add the eye position, view direction and
+            field of view of alive players to the ticks, e.g., to draw vision
+            cones in a 2D replay. Defaults to False.
+        near_misses (bool): Whether to parse shots that went close to an enemy
+            without hitting them. This parses the player positions at every
+            shot. Defaults to False.
+        spawns (bool): Whether to parse where each player spawned, and the
+            roster-based team keys, alive counts and kill advantages, which are
+            based on the spawns. Defaults to False.
+        survival (bool): Whether to parse how long each player survived each
+            round and where they died. Also parses the spawns. Defaults to
+            False.
+        network (bool): Whether to parse a timeline of each player's ping.
+            Defaults to False.
+        activity (bool): Whether to flag bots, AFK players and players who
+            spent a round controlling a bot. Defaults to False.
+        dropped_weapons (bool): Whether to track the guns dropped by dead
+            players until someone picks them up. Defaults to False.
+        agents (bool): Whether to parse the agent model each player uses on
+            each side. Defaults to False.
+        zones (bool): Whether to parse the bounding box of each bombsite and
+            buy zone, and the executes onto the bombsites, which are found with
+            them. Defaults to False.
+        place_times (bool): Whether to parse the seconds each player spent in
+            each place of each round. Defaults to False.
+        bomb_warnings (bool): Whether to parse warnings for dropped bombs
+            that the Terrorists neglected. Defaults to False.
+        buy_log (bool): Whether to parse what each player bought and refunded
+            in the buy menu, and the Zeus purchases in the economy seeds, which
+            are counted from it. Defaults to False.
+        anonymize (bool): Whether to replace Steam IDs with salted hashes and
+            names with aliases. Defaults to False.
+        salt (str, optional): Salt for anonymization. Use the same salt to get
+            the same aliases across demos. Defaults to None, which uses a
+            random salt.
+        redact (Path, optional): JSON redaction policy to apply, e.g., to share
+            a dataset without chat text or real names. See
+            `awpy.parsers.redact.load_redaction_policy`. Defaults to None.
+        workers (int): Number of processes to parse ticks, grenades, scopes and
+            chat in while events are processed. Defaults to 1, which parses
+            everything sequentially.
+        round_range (Union[str, int, tuple[int, int]], optional): Rounds to
+            keep, e.g., `"5-12"`. Rounds are still numbered as in the full
+            demo. Defaults to None, which keeps every round.
+        from_tick (int, optional): First tick to keep. Defaults to None.
+        to_tick (int, optional): Last tick to keep. Defaults to None.
+        players (list[str], optional): Steam IDs of the players to keep events
+            and ticks for. Defaults to None, which keeps everyone.
+        on_round_complete (Callable[[int, dict[str, pd.DataFrame]], None],
+            optional): Function called with the round number and the round's
+            dataframes for every round, e.g., to index rounds into a database.
+            See `Demo.iter_rounds`. Defaults to None.
+        checkpoint (Path, optional): Path to a zip file to save the raw events,
+            ticks and other parser outputs to as they are parsed. If the parse
+            is interrupted, parsing the same demo with the same options picks
+            up from there. Defaults to None.
+        place_polygons (Path, optional): Path to a JSON file of named place
+            polygons, to fill in place names on maps that ship without them.
+            See `awpy.parsers.places.load_place_polygons`. Defaults to None.
+        crash_dump (Path, optional): Path to a JSON file to write if the parse
+            fails, with the error, the demo header, the last tick and round
+            reached and the last CRASH_DUMP_EVENTS raw events, to report the
+            failure without sharing the demo. Defaults to None.
+        recording_end (Union[str, pd.Timestamp], optional): When the recording
+            ended, e.g., `"2024-03-01T12:00:00Z"` from the match page or the
+            server logs, to add the real-world start and end times of each
+            round. Demos don't store when they were recorded. Defaults to None,
+            which leaves the wall times empty.
+    """
+
+    sanitize: bool = False
+    scrim: bool = False
+    frame_interval: Optional[Union[int, str]] = None
+    chat_commands: bool = False
+    bomb_markers: bool = False
+    view_rays: bool = False
+    near_misses: bool = False
+    spawns: bool = False
+    survival: bool = False
+    network: bool = False
+    activity: bool = False
+    dropped_weapons: bool = False
+    agents: bool = False
+    zones: bool = False
+    place_times: bool = False
+    bomb_warnings: bool = False
+    buy_log: bool = False
+    anonymize: bool = False
+    salt: Optional[str] = None
+    redact: Optional[Path] = None
+    workers: int = 1
+    round_range: Optional[Union[str, int, tuple[int, int]]] = None
+    from_tick: Optional[int] = None
+    to_tick: Optional[int] = None
+    players: Optional[list[str]] = None
+    on_round_complete: Optional[Callable[[int, dict[str, pd.DataFrame]], None]] = (
+        None
+    )
+    checkpoint: Optional[Path] = None
+    place_polygons: Optional[Path] = None
+    crash_dump: Optional[Path] = None
+    recording_end: Optional[Union[str, pd.Timestamp]] = None
+
+
 class Demo:
     """Class to store a demo's data. Called with `Demo(file="...")`."""
 
@@ -74,6 +308,7 @@ class Demo:
         rounds: bool = True,
         player_props: Optional[list[str]] = None,
         other_props: Optional[list[str]] = None,
+        options: Optional[DemoOptions] = None,
     ) -> None:
         """Instantiate a Demo object using the `demoparser2` backend.
 
@@ -86,6 +321,9 @@ class Demo:
                 get with each event type. See `demoparser2`.
             other_props(list[str], optional): List of other props to
                 get with each event type. See `demoparser2`.
+            options (DemoOptions, optional): Opt-in parsing options, e.g.,
+                `DemoOptions(sanitize=True)`. Defaults to None, which uses the
+                DemoOptions defaults.
 
         Raises:
             FileNotFoundError: If the specified `path` to demo does not exist.
@@ -97,11 +335,78 @@ class Demo:
         self.verbose = verbose
         self.parse_ticks = ticks if ticks else False
         self.parse_rounds = rounds if rounds else False
+        self.options = options if options is not None else DemoOptions()
+        self.sanitize = self.options.sanitize
+        self.scrim = self.options.scrim
+        self.frame_interval = self.options.frame_interval
+        self.chat_commands = self.options.chat_commands
+        self.parse_bomb_markers = self.options.bomb_markers
+        self.view_rays = self.options.view_rays
+        self.parse_near_misses = self.options.near_misses
+        self.parse_survival = self.options.survival
+        self.parse_spawns = self.options.spawns or self.parse_survival
+        self.parse_network = self.options.network
+        self.parse_activity = self.options.activity
+        self.parse_dropped_weapons = self.options.dropped_weapons
+        self.parse_agents = self.options.agents
+        self.parse_zones = self.options.zones
+        self.parse_place_times = self.options.place_times
+        self.parse_bomb_warnings = self.options.bomb_warnings
+        self.parse_buy_log = self.options.buy_log
+        self.redaction_policy = (
+            load_redaction_policy(self.options.redact)
+            if self.options.redact is not None
+            else None
+        )
+        anonymize, salt = self.options.anonymize, self.options.salt
+        if self.redaction_policy is not None:
+            anonymize = anonymize or self.redaction_policy["anonymize"]
+            salt = salt if salt is not None else self.redaction_policy["salt"]
+        self.anonymize = anonymize if anonymize else False
+        self.salt = salt if salt is not None else secrets.token_hex(16)
+        self.workers = max(1, self.options.workers)
+        self.round_range = (
+            parse_round_range(self.options.round_range)
+            if self.options.round_range is not None
+            else None
+        )
+        self.from_tick = self.options.from_tick
+        self.to_tick = self.options.to_tick
+        self.tick_window = None  # First and last tick to keep
+        self.players = (
+            [str(steamid) for steamid in self.options.players]
+            if self.options.players is not None
+            else None
+        )
+        self.on_round_complete = self.options.on_round_complete
+        self.checkpoint_path = (
+            Path(self.options.checkpoint)
+            if self.options.checkpoint is not None
+            else None
+        )
+        self.place_polygons = (
+            Path(self.options.place_polygons)
+            if self.options.place_polygons is not None
+            else None
+        )
+        self.crash_dump_path = (
+            Path(self.options.crash_dump)
+            if self.options.crash_dump is not None
+            else None
+        )
 
         # Parser & Metadata
         self.parser = None  # DemoParser
         self.header = None  # DemoHeader
+        self.tick_rate = DEFAULT_TICK_RATE  # Server tick rate
+        self.c4_timer = None  # Seconds from bomb plant to explosion
         self.events = {}  # Dictionary of [event, dataframe]
+        self.warnings = {}  # Dictionary of [warning type, counts]
+        self._parser_jobs = {}  # Dictionary of [job name, future]
+        self.checkpoint = None  # Checkpoint of parser outputs
+        self.parse_stats = {}  # Dictionary of [stat, value]
+        self.timing = {}  # Dictionary of [tick timing diagnostic, value]
+        self._n_recovered_errors = 0
 
         # Set the prop lists. Always include default props
         self.player_props = (
@@ -117,30 +422,152 @@ class Demo:
         )
         self.other_props = list(set(self.other_props))
 
+        # View rays lower the eyes of crouching players
+        if self.view_rays:
+            self.player_props = list({*self.player_props, "duck_amount"})
+
         # Data (pandas dataframes)
         self.kills = None
         self.damages = None
+        self.kill_contributions = None
+        self.death_recaps = None
         self.bomb = None
+        self.dropped_weapons = None
+        self.defuses = None
         self.smokes = None
         self.infernos = None
         self.weapon_fires = None
+        self.utility_timings = None
+        self.bursts = None
+        self.near_misses = None
         self.rounds = None
         self.grenades = None
+        self.blinds = None
+        self.grenade_effects = None
+        self.scopes = None
+        self.chat = None
+        self.admin_events = None
+        self.bomb_markers = None
+        self.bomb_warnings = None
         self.ticks = None
+        self.team_shapes = None
+        self.tick_gaps = None
+        self.keyframes = None
+        self.ranks = None
+        self.scoreboard = None
+        self.teams = None
+        self.spawns = None
+        self.zones = None
+        self.executes = None
+        self.economy = None
+        self.economy_seeds = None
+        self.buy_log = None
+        self.alive_counts = None
+        self.survival = None
+        self.activity = None
+        self.place_times = None
+        self.team_keys = None
+        self.spectators = None
+        self.convar_changes = None
+        self.network = None
+        self.agents = None
+        self.pre_match = {}  # Rows before the first round, by dataframe name
 
         if self.path.exists():
-            self.parser = DemoParser(str(self.path))
-            self._success(f"Created parser for {self.path}")
+            try:
+                start_time = time.perf_counter()
+                self.parser = DemoParser(str(self.path))
+                self._success(f"Created parser for {self.path}")
+
+                if self.checkpoint_path is not None:
+                    self.checkpoint = Checkpoint(
+                        self.checkpoint_path, self._get_fingerprint()
+                    )
+                    if self.checkpoint.was_unreadable:
+                        self._recover(
+                            f"Started over the unreadable checkpoint "
+                            f"{self.checkpoint_path}"
+                        )
+
+                self._parse_demo()
+                self._success(f"Parsed raw events for {self.path}")
+
+                self._parse_events()
+                self._success(f"Processed events for {self.path}")
+
+                if self.parse_rounds and self.rounds.shape[0] > 0:
+                    self._split_pre_match()
+
+                if self.sanitize:
+                    self._sanitize_positions()
+                    self._success(f"Sanitized positions for {self.path}")
+
+                if self.place_polygons is not None:
+                    self._fill_place_names()
+                    self._success(f"Filled place names for {self.path}")
+
+                if self._has_range_filter() and self.tick_window is not None:
+                    self._filter_range()
+
+                if self.players is not None:
+                    self._filter_players()
+
+                if self.anonymize:
+                    self._anonymize_players()
+                    self._success(f"Anonymized players for {self.path}")
+
+                if self.redaction_policy is not None:
+                    self._redact()
+                    self._success(f"Redacted {self.path}")
 
-            self._parse_demo()
-            self._success(f"Parsed raw events for {self.path}")
+                self._parse_warnings()
+                self._parse_stats(start_time)
 
-            self._parse_events()
-            self._success(f"Processed events for {self.path}")
+                if self.on_round_complete is not None:
+                    for round_num, round_data in self.iter_rounds():
+                        self.on_round_complete(round_num, round_data)
+            except Exception as e:
+                if self.crash_dump_path is not None:
+                    self._write_crash_dump(e)
+                raise
         else:
             demo_path_not_found_msg = f"{path} does not exist!"
             raise FileNotFoundError(demo_path_not_found_msg)
 
+    def _write_crash_dump(self, error: Exception) -> None:
+        """Write what the parser had reached when it failed to a JSON file.
+
+        Args:
+            error (Exception): The error the parse failed with.
+        """
+        event_ticks = [
+            int(event["tick"].max())
+            for event in self.events.values()
+            if "tick" in event.columns and event.shape[0] > 0
+        ]
+        last_tick = max(event_ticks) if len(event_ticks) > 0 else None
+        last_events = (
+            get_events_in_range(self.events, 0, last_tick).tail(CRASH_DUMP_EVENTS)
+            if last_tick is not None
+            else pd.DataFrame(columns=["tick", "event", "fields"])
+        )
+        crash_dump = {
+            "error": repr(error),
+            "traceback": traceback.format_exception(
+                type(error), error, error.__traceback__
+            ),
+            "header": self.header,
+            "last_tick": last_tick,
+            "last_round": (
+                int(self.rounds["round"].max())
+                if self.rounds is not None and self.rounds.shape[0] > 0
+                else None
+            ),
+            "last_events": last_events.to_dict("records"),
+        }
+        self.crash_dump_path.write_text(json.dumps(crash_dump, default=str))
+        self._warn(f"Wrote crash dump to {self.crash_dump_path}")
+
     def _success(self, msg: str) -> None:
         """Log a success message.
 
@@ -159,6 +586,15 @@ class Demo:
         if self.verbose:
             logger.warning(msg)
 
+    def _recover(self, msg: str) -> None:
+        """Log an error that the parse recovered from, and count it.
+
+        Args:
+            msg (str): The error message to log.
+        """
+        self._n_recovered_errors += 1
+        self._warn(msg)
+
     def _debug(self, msg: str) -> None:
         """Log a debug message.
 
@@ -175,17 +611,48 @@ class Demo:
             raise ValueError(no_parser_error_msg)
 
         self.header = parse_header(self.parser.parse_header())
+        self.header["game"] = get_game_version(self.header)
+
+        # Demos don't store a recording time, so it can only be passed in
+        self.header["recording_end"] = (
+            pd.Timestamp(self.options.recording_end).isoformat()
+            if self.options.recording_end is not None
+            else None
+        )
 
         self._debug(
             f"Found the following game events: {self.parser.list_game_events()}"
         )
-        self.events = dict(
-            self.parser.parse_events(
-                self.parser.list_game_events(),
-                player=self.player_props,
-                other=self.other_props,
+        checkpoint_events = (
+            self.checkpoint.load_events() if self.checkpoint is not None else None
+        )
+        if checkpoint_events is not None:
+            self.events = checkpoint_events
+            self._debug(f"Loaded raw events from {self.checkpoint_path}")
+        else:
+            self.events = dict(
+                self.parser.parse_events(
+                    self.parser.list_game_events(),
+                    player=list({*self.player_props, *EVENT_PLAYER_PROPS}),
+                    other=self.other_props,
+                )
             )
+            if self.checkpoint is not None:
+                self.checkpoint.save_events(self.events)
+
+        # Estimate the tick rate from the timings of every event
+        event_timings = [
+            event[["tick", "game_time"]]
+            for event in self.events.values()
+            if {"tick", "game_time"}.issubset(event.columns)
+        ]
+        self.tick_rate = (
+            parse_tick_rate(pd.concat(event_timings))
+            if len(event_timings) > 0
+            else DEFAULT_TICK_RATE
         )
+        self.header["tick_rate"] = self.tick_rate
+        self.header["frame_rate"] = None  # Set when parsing ticks
 
     def _parse_events(self) -> None:
         """Process the raw parsed data."""
@@ -193,41 +660,413 @@ class Demo:
             no_events_error_msg = "No events found!"
             raise ValueError(no_events_error_msg)
 
+        if self.workers == 1 or self.parse_rounds is False:
+            self._process_events()
+            return
+
+        # Parse the slowest parser outputs in other processes in the meantime
+        with ProcessPoolExecutor(max_workers=self.workers) as executor:
+            if self.parse_ticks is True and not self._has_range_filter():
+                self._submit_parser_job(
+                    executor, "ticks", parse_ticks, self.player_props, self.other_props
+                )
+            self._submit_parser_job(executor, "grenades", parse_grenades)
+            self._submit_parser_job(executor, "scopes", parse_scopes)
+            self._submit_parser_job(executor, "chat", parse_chat)
+            self._process_events()
+
+    def _get_window_ticks(self) -> Optional[list[int]]:
+        """Get the ticks in the requested range, so that only those are parsed.
+
+        Returns:
+            Optional[list[int]]: The ticks to parse, or None to parse them all.
+        """
+        if not self._has_range_filter():
+            return None
+        start_tick, end_tick = self.tick_window
+        last_tick = min(end_tick, int(self.rounds["official_end"].max()))
+        return list(range(start_tick, last_tick + 1))
+
+    def _has_range_filter(self) -> bool:
+        """Whether only some rounds or ticks should be kept."""
+        return (
+            self.round_range is not None
+            or self.from_tick is not None
+            or self.to_tick is not None
+        )
+
+    def _apply_team_keys(self) -> None:
+        """Add roster-based team keys to the rounds and every team column."""
+        self.team_keys = parse_team_keys(self.spawns, game=self.header["game"])
+        for attr_name, df in list(vars(self).items()):
+            if isinstance(df, pd.DataFrame) and attr_name != "team_keys":
+                setattr(self, attr_name, apply_team_keys(df, self.team_keys))
+
+        for team_name, key_col in [("CT", "ct_team_key"), ("TERRORIST", "t_team_key")]:
+            side_keys = self.team_keys[self.team_keys["team_name"] == team_name]
+            self.rounds[key_col] = self.rounds["round"].map(
+                side_keys.set_index("round")["team_key"]
+            )
+
+    def _split_pre_match(self) -> None:
+        """Move the rows before the first round start, e.g., warmup kills.
+
+        These rows have no round, so they are kept in `pre_match` rather than
+        mixed with the rounds as round 0.
+        """
+        first_start = self.rounds["start"].min()
+        for attr_name, df in list(vars(self).items()):
+            if not isinstance(df, pd.DataFrame) or "round" not in df.columns:
+                continue
+            tick_col = next(
+                (col for col in ["tick", "start_tick"] if col in df.columns), None
+            )
+            if tick_col is None:
+                continue
+            is_pre_match = (df["round"] == 0) & (df[tick_col] < first_start)
+            if is_pre_match.any():
+                self.pre_match[attr_name] = df[is_pre_match].reset_index(drop=True)
+                setattr(self, attr_name, df[~is_pre_match].reset_index(drop=True))
+        self._debug(f"Moved pre-match rows of {list(self.pre_match)}")
+
+    def _filter_range(self) -> None:
+        """Drop the events and ticks outside of the requested rounds and ticks."""
+        start_tick, end_tick = self.tick_window
+        for attr_name, df in list(vars(self).items()):
+            if isinstance(df, pd.DataFrame) and attr_name != "ranks":
+                setattr(self, attr_name, self._filter_df_range(df))
+        self.events = {
+            event_name: self._filter_df_range(event)
+            for event_name, event in self.events.items()
+        }
+        self._debug(f"Kept ticks {start_tick} to {end_tick}")
+
+    def _filter_df_range(self, df: pd.DataFrame) -> pd.DataFrame:
+        """Keep the rows of a dataframe in the requested rounds and ticks.
+
+        Args:
+            df (pd.DataFrame): Dataframe with a `tick`, `start_tick` or `round`.
+
+        Returns:
+            pd.DataFrame: The rows in range.
+        """
+        start_tick, end_tick = self.tick_window
+        if "round" in df.columns and self.round_range is not None:
+            first_round, last_round = self.round_range
+            df = df[df["round"].between(first_round, last_round)]
+        for tick_col in ["tick", "start_tick", "start"]:
+            if tick_col in df.columns:
+                df = df[df[tick_col].between(start_tick, end_tick)]
+                break
+        return df.reset_index(drop=True)
+
+    def _filter_players(self) -> None:
+        """Drop the rows that don't involve any of the requested players."""
+        for attr_name, df in list(vars(self).items()):
+            if isinstance(df, pd.DataFrame):
+                setattr(self, attr_name, self._filter_df_players(df))
+        self.pre_match = {
+            df_name: self._filter_df_players(df)
+            for df_name, df in self.pre_match.items()
+        }
+        self.events = {
+            event_name: self._filter_df_players(event)
+            for event_name, event in self.events.items()
+        }
+
+    def _filter_df_players(self, df: pd.DataFrame) -> pd.DataFrame:
+        """Keep the rows of a dataframe where a requested player takes part.
+
+        Args:
+            df (pd.DataFrame): Dataframe with Steam ID columns, e.g.,
+                `attacker_steamid`. Dataframes without them are kept as is.
+
+        Returns:
+            pd.DataFrame: The rows involving the requested players.
+        """
+        steamid_cols = [
+            col
+            for col in df.columns
+            if col.endswith("steamid") and not col.startswith("nearest_")
+        ]
+        if len(steamid_cols) == 0:
+            return df
+        involves_player = (
+            df[steamid_cols].astype(str).isin(self.players).any(axis="columns")
+        )
+        return df[involves_player].reset_index(drop=True)
+
+    def _submit_parser_job(
+        self,
+        executor: ProcessPoolExecutor,
+        name: str,
+        parse_func: Callable[..., pd.DataFrame],
+        *args: list[str],
+    ) -> None:
+        """Submit a parsing function to run with its own parser in another process.
+
+        Args:
+            executor (ProcessPoolExecutor): The executor to submit the job to.
+            name (str): Name of the job, used to get its result.
+            parse_func (Callable[..., pd.DataFrame]): Function that takes a
+                parser as its first argument.
+            *args (list[str]): Other arguments to `parse_func`.
+        """
+        if self.checkpoint is not None and self.checkpoint.has(name):
+            return
+        self._parser_jobs[name] = executor.submit(
+            _parse_with_new_parser, str(self.path), parse_func, *args
+        )
+
+    def _run_parser_job(
+        self,
+        name: str,
+        parse_func: Callable[..., pd.DataFrame],
+        *args: list[str],
+    ) -> pd.DataFrame:
+        """Get the result of a parser job, or run it now if it was not submitted.
+
+        Args:
+            name (str): Name of the job.
+            parse_func (Callable[..., pd.DataFrame]): Function that takes a
+                parser as its first argument.
+            *args (list[str]): Other arguments to `parse_func`.
+
+        Returns:
+            pd.DataFrame: The output of `parse_func`.
+        """
+        if self.checkpoint is not None and self.checkpoint.has(name):
+            self._debug(f"Loaded {name} from {self.checkpoint_path}")
+            return self.checkpoint.load(name)
+        job: Optional[Future] = self._parser_jobs.pop(name, None)
+        parsed_df = job.result() if job is not None else parse_func(self.parser, *args)
+        if self.checkpoint is not None:
+            self.checkpoint.save(name, parsed_df)
+        return parsed_df
+
+    def _get_fingerprint(self) -> dict:
+        """Get the demo file and options that the parser outputs depend on.
+
+        Returns:
+            dict: The fingerprint of the parse, for checkpoints.
+        """
+        demo_stat = self.path.stat()
+        return {
+            "demo_path": str(self.path.resolve()),
+            "demo_size": demo_stat.st_size,
+            "demo_mtime": demo_stat.st_mtime,
+            "player_props": sorted(self.player_props),
+            "other_props": sorted(self.other_props),
+            "round_range": self.round_range,
+            "from_tick": self.from_tick,
+            "to_tick": self.to_tick,
+        }
+
+    def _process_events(self) -> None:
+        """Process the parsed events into rounds, kills, ticks and more."""
+        self.convar_changes = parse_convar_changes(self.events)
         if self.parse_rounds is True:
             self.rounds = parse_rounds(
                 self.parser, self.events
             )  # Must pass parser for round start/end events
+            self.rounds = parse_restart_delays(
+                self.rounds, self.events, self.tick_rate
+            )
+            self.rounds = flag_junk_rounds(self.rounds, self.events, self.tick_rate)
+            if self.scrim:
+                n_junk_rounds = int(self.rounds["is_junk"].sum())
+                self.rounds = drop_junk_rounds(self.rounds)
+                if n_junk_rounds > 0:
+                    self.warnings["junk_rounds"] = n_junk_rounds
+                    self._warn(f"Dropped {n_junk_rounds} junk rounds")
+            self.rounds = parse_map_segments(self.rounds, self.events)
+            self.header["n_map_segments"] = int(self.rounds["map_segment"].max())
+            if self.header["n_map_segments"] > 1:
+                self._warn(
+                    f"Found {self.header['n_map_segments']} matches in one demo, "
+                    "see `map_segment` in the rounds"
+                )
+            self.c4_timer = parse_c4_timer(self.rounds, self.tick_rate)
+            self.header["c4_timer"] = self.c4_timer
+            self.rounds = parse_wall_times(
+                self.rounds,
+                (
+                    pd.Timestamp(self.options.recording_end)
+                    if self.options.recording_end is not None
+                    else None
+                ),
+                self._get_last_tick(),
+                self.tick_rate,
+            )
+            self.tick_window = get_tick_window(
+                self.rounds, self.round_range, self.from_tick, self.to_tick
+            )
 
-            self.kills = parse_times(
-                apply_round_num(self.rounds, parse_kills(self.events)), self.rounds
+            self.kills = parse_kills(self.events)
+            kill_ticks = parse_kill_ticks(self.parser, self.kills)
+            self.kills = self._parse_times(
+                parse_supporting_teammates(kill_ticks, self.kills)
+            )
+            self.kills = parse_victim_equipment(
+                kill_ticks, self.kills, game=self.header["game"]
             )
-            self.damages = parse_times(
-                apply_round_num(self.rounds, parse_damages(self.events)), self.rounds
+            self.kills = parse_victim_positions(kill_ticks, self.kills)
+            self.kills = parse_attacker_ammo(kill_ticks, self.kills)
+            self.kills = parse_melee_kills(self.kills)
+            if self.parse_dropped_weapons:
+                self.dropped_weapons = parse_dropped_weapons(
+                    kill_ticks, self.kills, self.events, self.rounds
+                )
+            self.damages = self._parse_times(parse_damages(self.events))
+            self.kill_contributions = parse_kill_contributions(
+                self.kills, self.damages
             )
-            self.bomb = parse_times(
-                apply_round_num(self.rounds, parse_bomb(self.events)), self.rounds
+            self.death_recaps = parse_death_recaps(self.kills, self.damages)
+            self.bomb = self._parse_times(parse_bomb(self.events))
+            self.defuses = self._parse_times(
+                parse_defuses(self.events, self.tick_rate), tick_col="start_tick"
             )
-            self.smokes = parse_times(
-                apply_round_num(
-                    self.rounds, parse_smokes(self.events), tick_col="start_tick"
-                ),
-                self.rounds,
-                tick_col="start_tick",
+            self.smokes = self._parse_times(
+                parse_smokes(self.events), tick_col="start_tick"
             )
-            self.infernos = parse_times(
-                apply_round_num(
-                    self.rounds, parse_infernos(self.events), tick_col="start_tick"
-                ),
+            self.infernos = self._parse_times(
+                parse_infernos(self.events), tick_col="start_tick"
+            )
+            self.kills = parse_line_through_smoke(self.kills, self.smokes)
+            self.damages = parse_line_through_smoke(self.damages, self.smokes)
+            self.kills = parse_crosshair_offsets(self.kills)
+            self.damages = parse_crosshair_offsets(self.damages)
+            self.weapon_fires = self._parse_times(parse_weapon_fires(self.events))
+            self.bursts = parse_bursts(self.weapon_fires, self.tick_rate)
+            if self.parse_near_misses:
+                self.near_misses = parse_near_misses(
+                    self.parser, self.weapon_fires, self.damages
+                )
+            self.utility_timings = parse_utility_timings(
+                self.weapon_fires, self.tick_rate, game=self.header["game"]
+            )
+            self.grenades = self._parse_times(
+                self._run_parser_job("grenades", parse_grenades)
+            )
+            self.blinds = self._parse_times(
+                parse_blinds(self.events, self.tick_rate), tick_col="start_tick"
+            )
+            self.grenade_effects = self._parse_times(
+                parse_grenade_effects(self.events, self.damages, self.infernos)
+            )
+            self.scopes = self._parse_times(
+                self._run_parser_job("scopes", parse_scopes)
+            )
+            self.chat = self._parse_times(self._run_parser_job("chat", parse_chat))
+            if self.chat_commands:
+                self.admin_events = parse_admin_events(self.chat)
+            if self.parse_bomb_markers:
+                self.bomb_markers = self._parse_times(
+                    parse_bomb_markers(self.events, self.tick_rate, self.c4_timer)
+                )
+            self.teams = parse_teams(self.parser, self.rounds)
+            if self.parse_spawns:
+                self.spawns = parse_spawns(self.parser, self.rounds)
+            if self.parse_zones:
+                self.zones = parse_zones(self.parser, self.rounds, self.tick_rate)
+                self.executes = parse_executes(
+                    self.smokes,
+                    self.infernos,
+                    self.events,
+                    self.zones,
+                    self.bomb,
+                    self.rounds,
+                    self.tick_rate,
+                )
+            if self.spawns is not None:
+                disconnects = self.events.get("player_disconnect")
+                self.alive_counts = parse_alive_counts(
+                    self.spawns,
+                    self.kills,
+                    apply_round_num(self.rounds, disconnects.copy())
+                    if disconnects is not None
+                    else None,
+                )
+                self.kills = parse_kill_advantages(self.kills, self.alive_counts)
+            if self.parse_survival:
+                self.survival = parse_survival(
+                    self.parser, self.rounds, self.spawns, self.kills, self.tick_rate
+                )
+            if self.parse_activity:
+                self.activity = parse_activity(
+                    self.parser, self.rounds, self.weapon_fires, self.tick_rate
+                )
+            if self.parse_place_times:
+                self.place_times = parse_place_times(
+                    self.parser, self.rounds, self.tick_rate
+                )
+            if self.parse_bomb_warnings:
+                self.bomb_warnings = parse_bomb_warnings(
+                    self.parser, self.events, self.rounds, self.tick_rate
+                )
+            if self.parse_network:
+                self.network = self._parse_times(
+                    parse_network(self.parser, self.rounds, self.tick_rate)
+                )
+            if self.parse_agents:
+                self.agents = parse_agents(self.parser, self.rounds)
+            if self.parse_buy_log:
+                self.buy_log = self._parse_times(
+                    parse_buy_log(
+                        self.parser, self.events, self.rounds, game=self.header["game"]
+                    )
+                )
+            round_balances = parse_round_balances(self.parser, self.rounds)
+            self.economy = forecast_economy(
+                round_balances,
                 self.rounds,
-                tick_col="start_tick",
+                self.convar_changes,
+                game=self.header["game"],
             )
-            self.weapon_fires = parse_times(
-                apply_round_num(self.rounds, parse_weapon_fires(self.events)),
+            self.economy_seeds = build_economy_seeds(
+                round_balances,
                 self.rounds,
+                self.economy,
+                self.convar_changes,
+                game=self.header["game"],
             )
-            self.grenades = parse_times(
-                apply_round_num(self.rounds, parse_grenades(self.parser)), self.rounds
+            if self.buy_log is not None:
+                self.economy_seeds = parse_zeus_purchases(
+                    self.economy_seeds, self.buy_log
+                )
+            self.keyframes = parse_keyframes(
+                self.parser, self.rounds, self.player_props
             )
+            if "inventory" in self.keyframes.columns:
+                self.keyframes = parse_equipment_values(
+                    self.keyframes, game=self.header["game"]
+                )
+
+        # Casters and GOTV relays are connected, but are not players
+        self.spectators = parse_spectators(self.events)
+        is_caster = ~self.spectators["is_gotv"].astype(bool)
+        self.header["n_spectators"] = int(
+            self.spectators.loc[is_caster, "steamid"].nunique()
+        )
+
+        # Parse ranks at the last round end, when the ranks are final
+        round_end = self.events.get("round_end")
+        if round_end is not None and round_end.shape[0] > 0:
+            last_round_end = int(round_end["tick"].max())
+            self.ranks = parse_ranks(self.parser, last_round_end)
+            self.ranks = self.ranks[
+                ~self.ranks["steamid"].isin(
+                    get_spectating_steamids(self.spectators, last_round_end)
+                )
+            ].reset_index(drop=True)
+            self.scoreboard = parse_scoreboard(self.parser, last_round_end)
+            self.scoreboard = self.scoreboard[
+                ~self.scoreboard["steamid"].isin(
+                    get_spectating_steamids(self.spectators, last_round_end)
+                )
+            ].reset_index(drop=True)
+        else:
+            self._debug("Skipping rank parsing, no round_end events...")
 
         # Parse ticks
         if self.parse_ticks is True:
@@ -242,13 +1081,51 @@ class Demo:
                     """
                 )
             if self.parse_rounds:
-                self.ticks = apply_round_num(
-                    self.rounds,
-                    parse_ticks(self.parser, self.player_props, self.other_props),
+                ticks = self._run_parser_job(
+                    "ticks",
+                    parse_ticks,
+                    self.player_props,
+                    self.other_props,
+                    self._get_window_ticks(),
                 )
+                self.header["frame_rate"] = parse_frame_rate(ticks, self.tick_rate)
+                self.timing = parse_timing(ticks, self.tick_rate)
+                self.tick_gaps = parse_tick_gaps(ticks, self.rounds)
+                if self.tick_gaps.shape[0] > 0:
+                    self.warnings["tick_gaps"] = self.tick_gaps.shape[0]
+                if {"pitch", "yaw"}.issubset(ticks.columns):
+                    ticks = parse_view_velocity(ticks, self.tick_rate)
+                if self.frame_interval is not None:
+                    ticks = downsample_ticks(
+                        ticks,
+                        parse_frame_interval(self.frame_interval, self.tick_rate),
+                    )
+                self.ticks = self._parse_times(ticks, include_clock=False)
+                self.team_shapes = parse_team_shapes(self.ticks)
+                self.ticks = parse_nearest_players(self.ticks)
+                if self.view_rays and {"pitch", "yaw"}.issubset(self.ticks.columns):
+                    self.ticks = parse_view_rays(self.ticks)
+                if "last_place_name" in self.ticks.columns:
+                    self.smokes = parse_smoke_places(
+                        self.smokes, self.ticks, self.header.get("map_name")
+                    )
+                self.ticks = parse_defuse_progress(
+                    self.ticks, self.defuses, self.tick_rate
+                )
+                if "inventory" in self.ticks.columns:
+                    self.ticks = parse_bomb_state(self.ticks, self.events, self.rounds)
         else:
             self._debug("Skipping tick parsing...")
 
+        # Join teams by roster, since clan names are often empty
+        if self.parse_rounds is True:
+            if self.spawns is not None:
+                self._apply_team_keys()
+            self.rounds = parse_win_streaks(self.rounds)
+            self.rounds = parse_round_summaries(
+                self.rounds, self.kills, self.bomb, self.smokes, self.executes
+            )
+
         # Get round info for every event
         if self.parse_rounds is True:
             for event_name, event in self.events.items():
@@ -259,55 +1136,514 @@ class Demo:
         else:
             self._debug("Skipping round number parsing for events...")
 
-    def compress(self, outpath: Optional[Path] = None) -> None:
-        """Saves the demo data to a zip file.
+    def _parse_times(
+        self,
+        df: pd.DataFrame,
+        tick_col: str = "tick",
+        *,
+        include_clock: bool = True,
+    ) -> pd.DataFrame:
+        """Add the round number and round times to a dataframe.
 
         Args:
-            outpath (Path): Path to save the zip file. Defaults to cwd.
+            df (pd.DataFrame): The dataframe to add the round information to.
+            tick_col (str, optional): Name of tick column. Defaults to "tick".
+            include_clock (bool, optional): Whether to add the clock string.
+                Defaults to True.
+
+        Returns:
+            pd.DataFrame: The dataframe with round and time columns.
         """
-        outpath = Path.cwd() if outpath is None else Path(outpath)
-        zip_name = outpath / Path(self.path.stem + ".zip")
-
-        with (
-            tempfile.TemporaryDirectory() as tmpdirname,
-            zipfile.ZipFile(zip_name, "w", zipfile.ZIP_DEFLATED) as zipf,
-        ):
-            # Get the main dataframes
-            if self.parse_rounds:
+        return parse_times(
+            apply_round_num(self.rounds, df, tick_col=tick_col),
+            self.rounds,
+            tick_col=tick_col,
+            tick_rate=self.tick_rate,
+            c4_timer=self.c4_timer,
+            include_clock=include_clock,
+        )
+
+    def _sanitize_positions(self) -> None:
+        """Remove invalid positions and count them in the warnings."""
+        map_name = self.header.get("map_name")
+        invalid_positions = {}
+        for df, prefix in [
+            (self.ticks, ""),
+            (self.kills, "attacker_"),
+            (self.kills, "victim_"),
+            (self.damages, "attacker_"),
+            (self.damages, "victim_"),
+        ]:
+            if df is None:
+                continue
+            sanitize_positions(
+                df,
+                map_name,
+                prefix=prefix,
+                check_teleports=df is self.ticks,
+                fix=True,
+            )
+            for flag, count in df[f"{prefix}position_flag"].value_counts().items():
+                invalid_positions[flag] = invalid_positions.get(flag, 0) + int(count)
+
+        if len(invalid_positions) > 0:
+            self.warnings["invalid_positions"] = invalid_positions
+            self._warn(f"Removed invalid positions: {invalid_positions}")
+
+    def _get_last_tick(self) -> int:
+        """Get the last tick with an event, which is about the end of the demo.
+
+        Returns:
+            int: The last tick, or 0 if there are no events.
+        """
+        event_ticks = [
+            int(event["tick"].max())
+            for event in self.events.values()
+            if "tick" in event.columns and event.shape[0] > 0
+        ]
+        return max(event_ticks, default=0)
+
+    def _parse_stats(self, start_time: float) -> None:
+        """Collect statistics on the parse, like wall time and memory use.
+
+        Args:
+            start_time (float): `time.perf_counter()` when parsing started.
+        """
+        wall_time = time.perf_counter() - start_time
+        n_ticks = self._get_last_tick()
+        self.parse_stats = {
+            "wall_time": wall_time,
+            "n_ticks": n_ticks,
+            "ticks_per_second": n_ticks / wall_time if wall_time > 0 else None,
+            "event_counts": {
+                event_name: int(event.shape[0])
+                for event_name, event in self.events.items()
+            },
+            "max_memory_mb": get_max_memory_mb(),
+            "recovered_errors": self._n_recovered_errors,
+        }
+
+    def _fill_place_names(self) -> None:
+        """Fill in missing place names in every dataframe from place polygons."""
+        polygons = load_place_polygons(self.place_polygons)
+        for df in [*vars(self).values(), *self.events.values()]:
+            if isinstance(df, pd.DataFrame):
+                fill_place_names(df, polygons)
+
+    def _anonymize_players(self) -> None:
+        """Replace player names and Steam IDs in every dataframe."""
+        for df in [*vars(self).values(), *self.events.values()]:
+            if isinstance(df, pd.DataFrame):
+                anonymize_players(df, self.salt)
+
+    def _redact(self) -> None:
+        """Remove chat text and header keys according to the redaction policy."""
+        chat_mode = self.redaction_policy["chat"]
+        if self.chat is not None:
+            self.chat = redact_chat(self.chat, chat_mode)
+        if self.admin_events is not None:
+            self.admin_events = redact_chat(self.admin_events, chat_mode)
+        if "player_chat" in self.events:
+            self.events["player_chat"] = redact_chat(
+                self.events["player_chat"], chat_mode
+            )
+        self.header = redact_header(self.header, self.redaction_policy["header"])
+
+    def _parse_warnings(self) -> None:
+        """Collect data quality warnings, like unknown weapons."""
+        unknown_weapons = {}
+        for df in [
+            self.events.get("player_death"),
+            self.events.get("player_hurt"),
+            self.events.get("weapon_fire"),
+        ]:
+            for weapon, count in find_unknown_weapons(df).items():
+                unknown_weapons[weapon] = unknown_weapons.get(weapon, 0) + count
+
+        if len(unknown_weapons) > 0:
+            self.warnings["unknown_weapons"] = unknown_weapons
+            self._warn(f"Found unknown weapons: {unknown_weapons}")
+
+    def iter_rounds(self) -> Iterator[tuple[int, dict[str, pd.DataFrame]]]:
+        """Iterate over the rounds, with the rows of every dataframe in each.
+
+        Yields:
+            tuple[int, dict[str, pd.DataFrame]]: The round number and a
+                dictionary of [dataframe name, rows in the round].
+        """
+        if self.rounds is None:
+            return
+        for round_num in self.rounds["round"].tolist():
+            yield round_num, self._get_rounds_data([round_num])
+
+    def iter_segments(self) -> Iterator[tuple[int, dict[str, pd.DataFrame]]]:
+        """Iterate over the matches of a demo recorded over several matches.
+
+        Yields:
+            tuple[int, dict[str, pd.DataFrame]]: The map segment and a
+                dictionary of [dataframe name, rows in the segment's rounds].
+        """
+        if self.rounds is None:
+            return
+        for map_segment, segment_rounds in self.rounds.groupby("map_segment"):
+            yield int(map_segment), self._get_rounds_data(
+                segment_rounds["round"].tolist()
+            )
+
+    def _get_rounds_data(self, round_nums: list[int]) -> dict[str, pd.DataFrame]:
+        """Get the rows of every dataframe with a round number in some rounds.
+
+        Args:
+            round_nums (list[int]): The rounds to keep.
+
+        Returns:
+            dict[str, pd.DataFrame]: Dictionary of [dataframe name, rows in the
+                rounds].
+        """
+        return {
+            attr_name: df[df["round"].isin(round_nums)].reset_index(drop=True)
+            for attr_name, df in vars(self).items()
+            if isinstance(df, pd.DataFrame) and "round" in df.columns
+        }
+
+    def _get_tables(self) -> list[tuple[str, pd.DataFrame]]:
+        """Get the dataframes to save, by their file name without extension.
+
+        Returns:
+            list[tuple[str, pd.DataFrame]]: The file names and dataframes.
+        """
+        tables = []
+
+        # Get the main dataframes, without the opt-in ones that weren't parsed
+        if self.parse_rounds:
+            tables.extend(
+                (df_name, df)
                 for df_name, df in [
                     ("kills", self.kills),
                     ("damages", self.damages),
+                    ("kill_contributions", self.kill_contributions),
+                    ("death_recaps", self.death_recaps),
                     ("bomb", self.bomb),
+                    ("dropped_weapons", self.dropped_weapons),
+                    ("defuses", self.defuses),
                     ("smokes", self.smokes),
                     ("infernos", self.infernos),
                     ("weapon_fires", self.weapon_fires),
+                    ("utility_timings", self.utility_timings),
+                    ("bursts", self.bursts),
+                    ("near_misses", self.near_misses),
                     ("rounds", self.rounds),
                     ("grenades", self.grenades),
-                ]:
-                    df_filename = os.path.join(tmpdirname, f"{df_name}.data")
-                    df.to_parquet(df_filename, index=False)
-                    zipf.write(df_filename, f"{df_name}.data")
+                    ("blinds", self.blinds),
+                    ("grenade_effects", self.grenade_effects),
+                    ("scopes", self.scopes),
+                    ("chat", self.chat),
+                    ("teams", self.teams),
+                    ("spawns", self.spawns),
+                    ("zones", self.zones),
+                    ("executes", self.executes),
+                    ("bomb_warnings", self.bomb_warnings),
+                    ("team_keys", self.team_keys),
+                    ("economy", self.economy),
+                    ("economy_seeds", self.economy_seeds),
+                    ("buy_log", self.buy_log),
+                    ("alive_counts", self.alive_counts),
+                    ("survival", self.survival),
+                    ("activity", self.activity),
+                    ("place_times", self.place_times),
+                    ("network", self.network),
+                    ("agents", self.agents),
+                    ("keyframes", self.keyframes),
+                ]
+                if df is not None
+            )
 
-            # Write all events
-            for event_name, event in self.events.items():
-                event_filename = os.path.join(tmpdirname, f"{event_name}-event.data")
-                event.to_parquet(event_filename, index=False)
-                zipf.write(event_filename, os.path.join("events", f"{event_name}.data"))
+        # Get the rows before the first round
+        tables.extend(
+            (os.path.join("pre_match", df_name), df)
+            for df_name, df in self.pre_match.items()
+        )
+
+        # Get all events
+        tables.extend(
+            (os.path.join("events", event_name), event)
+            for event_name, event in self.events.items()
+        )
+
+        # Get spectators
+        if self.spectators is not None:
+            tables.append(("spectators", self.spectators))
+
+        # Get convar changes
+        if self.convar_changes is not None:
+            tables.append(("convar_changes", self.convar_changes))
+
+        # Get ranks
+        if self.ranks is not None:
+            tables.append(("ranks", self.ranks))
+
+        # Get the scoreboard
+        if self.scoreboard is not None:
+            tables.append(("scoreboard", self.scoreboard))
+
+        # Get admin events
+        if self.admin_events is not None:
+            tables.append(("admin_events", self.admin_events))
+
+        # Get bomb markers
+        if self.bomb_markers is not None:
+            tables.append(("bomb_markers", self.bomb_markers))
+
+        # Get ticks
+        if self.ticks is not None:
+            tables.append(("ticks", self.ticks))
+            tables.append(("team_shapes", self.team_shapes))
+            tables.append(("tick_gaps", self.tick_gaps))
+
+        return tables
+
+    def _get_partition_dir(self, partition_by: list[str]) -> Path:
+        """Get the Hive-style partition directory of the demo.
+
+        Args:
+            partition_by (list[str]): Keys of the partitions, in order.
+
+        Returns:
+            Path: The partition directory, e.g., `map=de_mirage/match=<demo>`.
+
+        Raises:
+            ValueError: If a key is not in PARTITION_KEYS.
+        """
+        values = {
+            "map": self.header.get("map_name"),
+            "game": self.header.get("game"),
+            "match": self.path.stem,
+        }
+        partition_dir = Path()
+        for key in partition_by:
+            if key not in PARTITION_KEYS:
+                unknown_key_msg = (
+                    f"Unknown partition key '{key}', expected one of {PARTITION_KEYS}"
+                )
+                raise ValueError(unknown_key_msg)
+            # Separators would split the value into extra partitions
+            value = str(values[key]).replace("/", "_").replace("=", "_")
+            partition_dir = partition_dir / f"{key}={value}"
+        return partition_dir
+
+    def compress(
+        self,
+        outpath: Optional[Union[Path, str]] = None,
+        field_case: Literal["snake", "camel"] = "snake",
+        split_events: bool = False,
+        partition_by: Optional[list[str]] = None,
+        delta_ticks: bool = False,
+        float32: bool = False,
+    ) -> None:
+        """Saves the demo data to a zip file.
+
+        Args:
+            outpath (Union[Path, str]): Path to save the zip file. Can be an
+                `s3://` or `gs://` URL, which streams the output to the bucket
+                with `fsspec` and needs `s3fs` or `gcsfs` installed. Defaults
+                to cwd.
+            field_case (Literal["snake", "camel"], optional): Naming convention
+                of the columns, e.g., `victim_steamid` or `victimSteamid`.
+                Defaults to "snake".
+            split_events (bool, optional): Whether to save every dataframe as
+                its own parquet file in a directory named after the demo, e.g.,
+                `<demo>/kills.parquet`, instead of a zip file. This lets a
+                single dataframe be read without opening the rest. Defaults to
+                False.
+            partition_by (list[str], optional): Keys of a Hive-style directory
+                layout, from PARTITION_KEYS, e.g., `["map", "match"]` saves to
+                `map=de_mirage/match=<demo>/kills.parquet`. Implies
+                `split_events`, so a folder of demos can be queried in place
+                with DuckDB or Spark. Defaults to None.
+            delta_ticks (bool, optional): Whether to delta-encode the ticks,
+                keeping only the fields that changed since each player's last
+                frame, with a full keyframe every DELTA_KEYFRAME_SECONDS. Read
+                them back with `delta_decode_ticks`. Defaults to False.
+            float32 (bool, optional): Whether to save floats, like positions,
+                as 32-bit floats and ints as 32-bit ints, which halves the size
+                of numeric columns. Defaults to False.
+        """
+        outpath = Path.cwd() if outpath is None else outpath
+        if not is_remote_path(outpath):
+            outpath = Path(outpath)
+        tables = self._get_tables()
+        if float32:
+            tables = [(df_name, downcast_numbers(df)) for df_name, df in tables]
+        if delta_ticks:
+            keyframe_ticks = DELTA_KEYFRAME_SECONDS * self.tick_rate
+            tables = [
+                (df_name, delta_encode_ticks(df, keyframe_ticks))
+                if df_name == "ticks"
+                else (df_name, df)
+                for df_name, df in tables
+            ]
+
+        header = (
+            {to_camel_case(key): value for key, value in self.header.items()}
+            if field_case == "camel"
+            else self.header
+        )
+        json_files = {
+            "header.json": header,
+            "parse_stats.json": self.parse_stats,
+            "timing.json": self.timing,
+            "warnings.json": self.warnings,
+        }
+
+        if split_events or partition_by:
+            out_dir = _join_outpath(
+                outpath,
+                self._get_partition_dir(partition_by).as_posix()
+                if partition_by
+                else self.path.stem,
+            )
+            for df_name, df in tables:
+                with _open_outpath(_join_outpath(out_dir, f"{df_name}.parquet")) as f:
+                    convert_field_case(df, field_case).to_parquet(f, index=False)
+            for file_name, content in json_files.items():
+                with _open_outpath(_join_outpath(out_dir, file_name), "w") as f:
+                    f.write(json.dumps(content))
 
-            # Write ticks
-            if self.ticks is not None:
-                ticks_filename = os.path.join(tmpdirname, "ticks.data")
-                self.ticks.to_parquet(ticks_filename, index=False)
-                zipf.write(ticks_filename, "ticks.data")
+            self._success(f"Saved demo data to {out_dir}")
+            return
 
-            header_filename = os.path.join(tmpdirname, "header.json")
-            with open(header_filename, "w", encoding="utf-8") as f:
-                json.dump(self.header, f)
-            zipf.write(header_filename, "header.json")
+        zip_name = _join_outpath(outpath, self.path.stem + ".zip")
+        with _open_outpath(zip_name) as f, zipfile.ZipFile(
+            f, "w", zipfile.ZIP_DEFLATED
+        ) as zipf:
+            for df_name, df in tables:
+                _write_parquet(zipf, f"{df_name}.data", df, field_case)
+            for file_name, content in json_files.items():
+                zipf.writestr(file_name, json.dumps(content))
 
             self._success(f"Zipped demo data to {zip_name}")
 
 
+def is_remote_path(path: Union[Path, str]) -> bool:
+    """Check whether an output path is a cloud storage URL, from REMOTE_PREFIXES.
+
+    Args:
+        path (Union[Path, str]): The output path.
+
+    Returns:
+        bool: True for `s3://` and `gs://` URLs.
+    """
+    return isinstance(path, str) and path.startswith(REMOTE_PREFIXES)
+
+
+def _join_outpath(outpath: Union[Path, str], name: str) -> Union[Path, str]:
+    """Join a name to an output path, keeping URLs as strings.
+
+    `Path` would collapse the `//` of a URL, e.g., to `s3:/bucket`.
+
+    Args:
+        outpath (Union[Path, str]): The output directory or URL.
+        name (str): The file or directory name, which can have `/` in it.
+
+    Returns:
+        Union[Path, str]: The joined path.
+    """
+    if is_remote_path(outpath):
+        return f"{outpath.rstrip('/')}/{name}"
+    return Path(outpath) / name
+
+
+def _open_outpath(path: Union[Path, str], mode: str = "wb") -> IO:
+    """Open an output file, locally or in cloud storage.
+
+    Cloud files are uploaded in parts as they are written, so large outputs
+    don't need local disk.
+
+    Args:
+        path (Union[Path, str]): The file path or URL.
+        mode (str, optional): The file mode. Defaults to "wb".
+
+    Returns:
+        IO: The opened file, to use as a context manager.
+
+    Raises:
+        ImportError: If `path` is a URL and `fsspec` isn't installed.
+    """
+    if is_remote_path(path):
+        try:
+            import fsspec  # pylint: disable=import-outside-toplevel
+        except ImportError as e:
+            no_fsspec_msg = (
+                f"Writing to {path} needs fsspec, e.g., `pip install s3fs` or "
+                "`pip install gcsfs`."
+            )
+            raise ImportError(no_fsspec_msg) from e
+        return fsspec.open(path, mode)
+
+    Path(path).parent.mkdir(parents=True, exist_ok=True)
+    return Path(path).open(mode)
+
+
+def _write_parquet(
+    zipf: zipfile.ZipFile,
+    arcname: str,
+    df: pd.DataFrame,
+    field_case: Literal["snake", "camel"] = "snake",
+) -> None:
+    """Write a dataframe as parquet straight into a zip file.
+
+    Parquet is already compressed, so deflating it again only costs time.
+
+    Args:
+        zipf (zipfile.ZipFile): The zip file to write to.
+        arcname (str): Name of the file in the zip.
+        df (pd.DataFrame): The dataframe to write.
+        field_case (Literal["snake", "camel"], optional): Naming convention of
+            the columns. Defaults to "snake".
+    """
+    zipf.writestr(
+        arcname,
+        convert_field_case(df, field_case).to_parquet(index=False),
+        compress_type=zipfile.ZIP_STORED,
+    )
+
+
+def get_max_memory_mb() -> Optional[float]:
+    """Get the peak memory use of the current process.
+
+    Returns:
+        Optional[float]: The peak resident memory in MB, or None if the platform
+            doesn't report it (e.g., Windows).
+    """
+    try:
+        import resource  # pylint: disable=import-outside-toplevel
+    except ImportError:
+        return None
+    max_rss = resource.getrusage(resource.RUSAGE_SELF).ru_maxrss
+
+    # Linux reports kilobytes, while macOS reports bytes
+    if sys.platform == "darwin":
+        return max_rss / 1024**2
+    return max_rss / 1024
+
+
+def _parse_with_new_parser(
+    path: str, parse_func: Callable[..., pd.DataFrame], *args: list[str]
+) -> pd.DataFrame:
+    """Run a parsing function with a new parser, since parsers can't be pickled.
+
+    Args:
+        path (str): Path to the demofile.
+        parse_func (Callable[..., pd.DataFrame]): Function that takes a parser as
+            its first argument.
+        *args (list[str]): Other arguments to `parse_func`.
+
+    Returns:
+        pd.DataFrame: The output of `parse_func`.
+    """
+    return parse_func(DemoParser(path), *args)
+
+
 def parse_header(parsed_header: dict) -> dict:
     """Parse the header of the demofile to a dictionary.
 
@@ -316,7 +1652,8 @@ def parse_header(parsed_header: dict) -> dict:
             of `parser.parse_header()`.
 
     Returns:
-        dict: The parsed header of the demofile.
+        dict: The parsed header of the demofile, including the `demo_protocol`
+            and `network_protocol` versions.
     """
     for key, value in parsed_header.items():
         if value == "true":
@@ -325,4 +1662,12 @@ def parse_header(parsed_header: dict) -> dict:
             parsed_header[key] = False
         else:
             pass  # Loop through and convert strings to bools
+
+    # Versions let downstream code branch on patch-specific behavior
+    demo_file_stamp = str(parsed_header.get("demo_file_stamp", "")).rstrip("\x00")
+    parsed_header["demo_protocol"] = demo_file_stamp or None
+    network_protocol = str(parsed_header.get("network_protocol", ""))
+    parsed_header["network_protocol"] = (
+        int(network_protocol) if network_protocol.isdigit() else None
+    )
     return parsed_header
@@ -0,0 +1,84 @@
+"""Module for replacing player identities with stable pseudonyms."""
+
+import hashlib
+from typing import Optional
+
+import pandas as pd
+
+# Prefixes of the player columns, e.g., `attacker_name` and `attacker_steamid`
+PLAYER_PREFIXES = (
+    "",
+    "user_",
+    "player_",
+    "attacker_",
+    "victim_",
+    "assister_",
+    "flasher_",
+    "sender_",
+    "contributor_",
+    "thrower_",
+    "nearest_teammate_",
+    "nearest_enemy_",
+)
+
+# Number of hash characters to keep in pseudonyms
+HASH_LENGTH = 16
+ALIAS_LENGTH = 8
+
+
+def hash_steamid(steamid: Optional[str], salt: str) -> Optional[str]:
+    """Hash a Steam ID with a salt.
+
+    Args:
+        steamid (str): The Steam ID to hash.
+        salt (str): The salt. Use the same salt to get the same hashes across
+            demos.
+
+    Returns:
+        Optional[str]: The salted hash, or None if there is no Steam ID.
+    """
+    if steamid is None or pd.isna(steamid) or str(steamid) in ("", "nan", "None"):
+        return None
+    return hashlib.sha256(f"{salt}{steamid}".encode()).hexdigest()[:HASH_LENGTH]
+
+
+def _alias(hashed_steamid: Optional[str]) -> Optional[str]:
+    """Get the player alias for a hashed Steam ID."""
+    if hashed_steamid is None or pd.isna(hashed_steamid):
+        return None
+    return f"Player_{hashed_steamid[:ALIAS_LENGTH]}"
+
+
+def anonymize_players(df: pd.DataFrame, salt: str) -> pd.DataFrame:
+    """Replace the player names and Steam IDs in a dataframe.
+
+    Steam IDs become salted hashes and names become aliases derived from those
+    hashes, so a player keeps the same alias wherever they appear. Names without
+    a Steam ID column are hashed directly.
+
+    Args:
+        df (pd.DataFrame): Dataframe with player columns, e.g., `attacker_name`.
+        salt (str): The salt for the hashes.
+
+    Returns:
+        pd.DataFrame: `df` with anonymized player columns.
+    """
+    for prefix in PLAYER_PREFIXES:
+        name_col, steamid_col = f"{prefix}name", f"{prefix}steamid"
+        if steamid_col in df.columns:
+            df[steamid_col] = df[steamid_col].map(lambda x: hash_steamid(x, salt))
+            if name_col in df.columns:
+                df[name_col] = df[steamid_col].map(_alias)
+        elif name_col in df.columns:
+            df[name_col] = df[name_col].map(lambda x: _alias(hash_steamid(x, salt)))
+
+    # Lists of players, e.g., the supporting teammates of a kill
+    for steamids_col in [col for col in df.columns if col.endswith("_steamids")]:
+        df[steamids_col] = df[steamids_col].map(
+            lambda steamids: [hash_steamid(steamid, salt) for steamid in steamids]
+        )
+
+    # Grenades name their thrower without a suffix
+    if "thrower" in df.columns and "thrower_steamid" in df.columns:
+        df["thrower"] = df["thrower_steamid"].map(_alias)
+    return df
@@ -0,0 +1,174 @@
+"""Module for chat message parsing functions."""
+
+import numpy as np
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
+from loguru import logger
+
+from awpy.parsers.utils import parse_col_types
+
+COACHING_TEAM_PROP = "CCSPlayerController.m_iCoachingTeam"
+COACHING_TEAM_MAPPING = {2: "TERRORIST", 3: "CT"}
+
+CHAT_COLUMNS = [
+    "tick",
+    "chat_type",
+    "sender_name",
+    "sender_steamid",
+    "sender_team_name",
+    "is_team_chat",
+    "is_dead_chat",
+    "message",
+]
+
+
+def classify_chat(chat_df: pd.DataFrame) -> pd.DataFrame:
+    """Classify chat messages by who sent them and who could read them.
+
+    Messages without a sender come from the server console. Coaches are
+    spectators with a coaching team, and they are assigned to that team.
+
+    Args:
+        chat_df (pd.DataFrame): Chat messages with `sender_steamid`,
+            `sender_team_name`, `sender_is_alive` and `coaching_team` columns.
+
+    Returns:
+        pd.DataFrame: `chat_df` with `chat_type`, `sender_team_name` resolved
+            for coaches and `is_dead_chat`.
+    """
+    is_server = chat_df["sender_steamid"].isna()
+    coaching_team = chat_df["coaching_team"].map(COACHING_TEAM_MAPPING)
+    is_coach = ~is_server & coaching_team.notna()
+    is_player = (
+        ~is_server & ~is_coach & chat_df["sender_team_name"].isin(["CT", "TERRORIST"])
+    )
+
+    chat_df["chat_type"] = np.select(
+        [is_server, is_coach, is_player],
+        ["server", "coach", "player"],
+        default="spectator",
+    )
+    chat_df["sender_team_name"] = chat_df["sender_team_name"].where(
+        ~is_coach, coaching_team
+    )
+    chat_df.loc[is_server, "sender_team_name"] = None
+
+    # Only players can be dead, since spectators and coaches never spawn
+    sender_is_alive = chat_df["sender_is_alive"].fillna(True).astype(bool)
+    chat_df["is_dead_chat"] = is_player & ~sender_is_alive
+    return chat_df
+
+
+def parse_chat(parser: DemoParser) -> pd.DataFrame:
+    """Parse the chat messages of the demofile.
+
+    Args:
+        parser (DemoParser): The parser object.
+
+    Returns:
+        pd.DataFrame: The chat messages, with the sender's team resolved at the
+            time of the message.
+    """
+    chat_df = parser.parse_event(
+        "player_chat", player=["team_name", "is_alive"], other=["game_time"]
+    )
+    if chat_df.shape[0] == 0:
+        return pd.DataFrame(columns=CHAT_COLUMNS)
+
+    chat_df = chat_df.rename(
+        columns={
+            "user_name": "sender_name",
+            "user_steamid": "sender_steamid",
+            "user_team_name": "sender_team_name",
+            "user_is_alive": "sender_is_alive",
+            "teamonly": "is_team_chat",
+            "text": "message",
+        }
+    )
+
+    # Coaches are only in their team's chat through the coaching team prop
+    chat_df["coaching_team"] = np.nan
+    try:
+        coaches_df = parser.parse_ticks(
+            wanted_props=[COACHING_TEAM_PROP],
+            ticks=chat_df["tick"].unique().tolist(),
+        ).rename(columns={COACHING_TEAM_PROP: "coaching_team"})
+        chat_df = chat_df.drop(columns=["coaching_team"]).merge(
+            coaches_df[["tick", "steamid", "coaching_team"]],
+            left_on=["tick", "sender_steamid"],
+            right_on=["tick", "steamid"],
+            how="left",
+        )
+    except Exception:
+        logger.debug("Coaching teams are not available in this demo.")
+
+    chat_df = parse_col_types(classify_chat(chat_df))
+    chat_df["is_team_chat"] = chat_df["is_team_chat"].fillna(False).astype(bool)
+    return chat_df[CHAT_COLUMNS].sort_values("tick").reset_index(drop=True)
+
+
+# Common pug and match bot commands, e.g., from Get5, MatchZy and PugSharp
+CHAT_COMMANDS = {
+    "ready": "ready",
+    "r": "ready",
+    "rdy": "ready",
+    "unready": "unready",
+    "notready": "unready",
+    "ur": "unready",
+    "pause": "pause",
+    "p": "pause",
+    "tac": "tactical_pause",
+    "tech": "technical_pause",
+    "unpause": "unpause",
+    "up": "unpause",
+    "stay": "stay",
+    "switch": "switch",
+    "swap": "switch",
+    "ct": "pick_ct",
+    "t": "pick_t",
+    "restore": "restore",
+    "backup": "restore",
+    "stop": "stop",
+    "forcestart": "force_start",
+    "gg": "surrender",
+    "forfeit": "surrender",
+}
+
+
+def parse_admin_events(
+    chat_df: pd.DataFrame, commands: dict[str, str] = CHAT_COMMANDS
+) -> pd.DataFrame:
+    """Parse pug and match bot commands (e.g., `.ready` or `!pause`) from chat.
+
+    Args:
+        chat_df (pd.DataFrame): The parsed chat messages.
+        commands (dict[str, str], optional): Mapping of commands, without the
+            `.` or `!` prefix, to actions. Defaults to CHAT_COMMANDS.
+
+    Returns:
+        pd.DataFrame: The commands, with the `action` they map to and any
+            arguments given after the command.
+    """
+    admin_columns = [
+        "tick",
+        "action",
+        "command",
+        "args",
+        "sender_name",
+        "sender_steamid",
+        "sender_team_name",
+    ]
+    if chat_df.shape[0] == 0:
+        return pd.DataFrame(columns=admin_columns)
+
+    command_parts = (
+        chat_df["message"]
+        .fillna("")
+        .str.strip()
+        .str.extract(r"^[.!](?P<command>\S+)\s*(?P<args>.*)$")
+    )
+    admin_df = pd.concat([chat_df, command_parts], axis=1)
+    admin_df["command"] = admin_df["command"].str.lower()
+    admin_df["action"] = admin_df["command"].map(commands)
+    admin_df = admin_df[admin_df["action"].notna()]
+    return admin_df[admin_columns].reset_index(drop=True)
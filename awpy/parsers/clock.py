@@ -1,13 +1,41 @@
 """Module for time and clock parsing functions."""
 
 import math
-from typing import Literal, Union
+from typing import Literal, Optional, Union
 
+import numpy as np
 import pandas as pd
 
+from awpy.utils import apply_round_num
+
 ROUND_START_DEFAULT_TIME_IN_SECS = 20
 FREEZE_DEFAULT_TIME_IN_SECS = 115
 BOMB_DEFAULT_TIME_IN_SECS = 40
+DEFAULT_TICK_RATE = 64
+MIN_MISSING_FRAMES = 2
+
+
+def _get_max_time_ticks(
+    max_time_ticks: Union[Literal["start", "freeze", "bomb"], int],
+    tick_rate: int = 64,
+) -> int:
+    """Get the maximum time in ticks for a phase.
+
+    Args:
+        max_time_ticks (Union[Literal['start', 'freeze', 'bomb'], int]): The phase,
+            or the maximum time in ticks for the phase.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        int: The maximum time in ticks for the phase.
+    """
+    if max_time_ticks == "start":
+        return ROUND_START_DEFAULT_TIME_IN_SECS * tick_rate
+    if max_time_ticks == "freeze":
+        return FREEZE_DEFAULT_TIME_IN_SECS * tick_rate
+    if max_time_ticks == "bomb":
+        return BOMB_DEFAULT_TIME_IN_SECS * tick_rate
+    return max_time_ticks
 
 
 def parse_clock(
@@ -26,12 +54,7 @@ def parse_clock(
     Returns:
         str: The remaining time in MM:SS format.
     """
-    if max_time_ticks == "start":
-        max_time_ticks = ROUND_START_DEFAULT_TIME_IN_SECS * tick_rate
-    elif max_time_ticks == "freeze":
-        max_time_ticks = FREEZE_DEFAULT_TIME_IN_SECS * tick_rate
-    elif max_time_ticks == "bomb":
-        max_time_ticks = BOMB_DEFAULT_TIME_IN_SECS * tick_rate
+    max_time_ticks = _get_max_time_ticks(max_time_ticks, tick_rate)
 
     # Calculate the remaining time in ticks
     remaining_ticks = max_time_ticks - seconds_since_phase_change
@@ -50,11 +73,94 @@ def parse_clock(
     return f"{int(minutes):02}:{int(seconds):02}"
 
 
-def _find_clock_time(row: pd.Series) -> str:
+def parse_time_remaining(
+    df: pd.DataFrame,
+    tick_rate: int = 64,
+    c4_timer: int = BOMB_DEFAULT_TIME_IN_SECS,
+) -> pd.Series:
+    """Get the remaining time in the current phase, in seconds, for each row.
+
+    Unlike the clock string, this keeps sub-second resolution and goes negative
+    once the phase's time has expired.
+
+    Args:
+        df (pd.DataFrame): A dataframe with ticks_since_* columns.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        c4_timer (int, optional): Seconds from a bomb plant to its explosion,
+            i.e., `mp_c4timer`. Defaults to 40.
+
+    Returns:
+        pd.Series: The remaining time in seconds.
+    """
+    # The most recent phase change is the one with the fewest ticks since
+    ticks_since_phase_change = (
+        df["ticks_since_bomb_plant"]
+        .fillna(df["ticks_since_freeze_time_end"])
+        .fillna(df["ticks_since_round_start"])
+        .astype("float64")
+    )
+    max_time_ticks = np.select(
+        [
+            df["ticks_since_bomb_plant"].notna().to_numpy(dtype=bool),
+            df["ticks_since_freeze_time_end"].notna().to_numpy(dtype=bool),
+        ],
+        [
+            c4_timer * tick_rate,
+            _get_max_time_ticks("freeze", tick_rate),
+        ],
+        _get_max_time_ticks("start", tick_rate),
+    )
+    return (max_time_ticks - ticks_since_phase_change) / tick_rate
+
+
+def parse_phases(
+    df: pd.DataFrame, tick_col: str = "tick", tick_rate: int = 64
+) -> pd.DataFrame:
+    """Adds the round phase of each row and the seconds since it began.
+
+    The phase is decided per row, so events before a plant are `pre_plant` even
+    in rounds where the bomb is planted later on.
+
+    Args:
+        df (pd.DataFrame): A dataframe with start, freeze_end, end and bomb_plant.
+        tick_col (str, optional): The column name of the tick column.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: The dataframe with `phase` and `phase_seconds` added.
+    """
+    ticks = df[tick_col].astype("float64")
+    phase_starts = {
+        "post_round": df["end"].astype("float64"),
+        "post_plant": df["bomb_plant"].astype("float64"),
+        "pre_plant": df["freeze_end"].astype("float64"),
+    }
+    conditions = [
+        (ticks > phase_starts["post_round"]).to_numpy(dtype=bool),
+        (ticks >= phase_starts["post_plant"]).to_numpy(dtype=bool),
+        (ticks >= phase_starts["pre_plant"]).to_numpy(dtype=bool),
+    ]
+    df["phase"] = np.select(conditions, list(phase_starts), "freeze_time")
+    df["phase"] = df["phase"].where(df["round"] > 0, None)
+
+    phase_start_tick = np.select(
+        conditions,
+        [phase_start.to_numpy() for phase_start in phase_starts.values()],
+        df["start"].astype("float64").to_numpy(),
+    )
+    df["phase_seconds"] = (ticks - phase_start_tick) / tick_rate
+    return df
+
+
+def _find_clock_time(
+    row: pd.Series, tick_rate: int = 64, c4_timer: int = BOMB_DEFAULT_TIME_IN_SECS
+) -> str:
     """Find the clock time for a row.
 
     Args:
         row: A row from a dataframe with ticks_since_* columns.
+        tick_rate: The tick rate of the server. Defaults to 64.
+        c4_timer: Seconds from a bomb plant to its explosion. Defaults to 40.
     """
     times = {
         "start": row["ticks_since_round_start"],
@@ -63,11 +169,221 @@ def _find_clock_time(row: pd.Series) -> str:
     }
     # Filter out NA values and find the key with the minimum value
     min_key = min((k for k in times if pd.notna(times[k])), key=lambda k: times[k])
-    return parse_clock(times[min_key], min_key)
+    max_time_ticks = c4_timer * tick_rate if min_key == "bomb" else min_key
+    return parse_clock(times[min_key], max_time_ticks, tick_rate)
+
+
+def parse_tick_rate(df: pd.DataFrame) -> int:
+    """Estimate the server tick rate from ticks and their game times.
+
+    Args:
+        df (pd.DataFrame): A dataframe with tick and game_time columns, such as
+            any parsed event.
+
+    Returns:
+        int: The server tick rate, which defaults to 64 if it cannot be estimated.
+    """
+    if df is None or not {"tick", "game_time"}.issubset(df.columns):
+        return DEFAULT_TICK_RATE
+
+    timings = df[["tick", "game_time"]].dropna().drop_duplicates("tick")
+    timings = timings.sort_values("tick")
+    ticks_per_second = timings["tick"].diff() / timings["game_time"].diff()
+    ticks_per_second = ticks_per_second[np.isfinite(ticks_per_second)]
+    if len(ticks_per_second) == 0:
+        return DEFAULT_TICK_RATE
+    return int(round(ticks_per_second.median()))
+
+
+def parse_c4_timer(rounds_df: pd.DataFrame, tick_rate: int = DEFAULT_TICK_RATE) -> int:
+    """Estimate `mp_c4timer` from the time between bomb plants and explosions.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe, with `bomb_plant` and
+            `bomb_explode` columns.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        int: The C4 timer in seconds, which defaults to 40 if no bomb exploded.
+    """
+    if not {"bomb_plant", "bomb_explode"}.issubset(rounds_df.columns):
+        return BOMB_DEFAULT_TIME_IN_SECS
+    fuse_ticks = (rounds_df["bomb_explode"] - rounds_df["bomb_plant"]).dropna()
+    if len(fuse_ticks) == 0:
+        return BOMB_DEFAULT_TIME_IN_SECS
+    return int(round(fuse_ticks.astype("float64").median() / tick_rate))
+
+
+def parse_frame_rate(
+    ticks_df: pd.DataFrame, tick_rate: int = DEFAULT_TICK_RATE
+) -> Optional[float]:
+    """Estimate the rate at which the demo recorded frames (the GOTV snapshot rate).
+
+    GOTV can record fewer frames per second than the server's tick rate, in
+    which case only every n-th tick appears in the parsed ticks.
+
+    Args:
+        ticks_df (pd.DataFrame): A dataframe with a tick column.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        Optional[float]: The recorded frames per second, or None if it cannot be
+            estimated.
+    """
+    if ticks_df is None or "tick" not in ticks_df.columns:
+        return None
+
+    # Non-play ticks are removed, so use the typical gap rather than the count
+    tick_gaps = pd.Series(ticks_df["tick"].unique()).sort_values().diff().dropna()
+    if len(tick_gaps) == 0:
+        return None
+    return float(tick_rate / tick_gaps.median())
+
+
+def parse_tick_gaps(
+    ticks_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    min_missing_frames: int = MIN_MISSING_FRAMES,
+) -> pd.DataFrame:
+    """Find where recorded frames are missing during play, e.g., GOTV hiccups.
+
+    Frames are expected every typical gap between ticks, so a jump between two
+    consecutive ticks of the same round means frames were dropped, and the
+    positions in between are unknown. Gaps between rounds are non-play ticks
+    and are not reported.
+
+    Args:
+        ticks_df (pd.DataFrame): A dataframe with a tick column, before any
+            downsampling.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        min_missing_frames (int, optional): Number of consecutive missing
+            frames from which a jump is a gap. Defaults to MIN_MISSING_FRAMES.
+
+    Returns:
+        pd.DataFrame: The gaps, with the last tick before (`start_tick`) and
+            the first tick after (`end_tick`) each gap.
+    """
+    gap_columns = ["round", "start_tick", "end_tick", "missing_frames"]
+    if ticks_df is None or "tick" not in ticks_df.columns:
+        return pd.DataFrame(columns=gap_columns)
+
+    ticks = pd.DataFrame({"tick": np.sort(ticks_df["tick"].unique())})
+    tick_steps = ticks["tick"].diff()
+    if tick_steps.dropna().shape[0] == 0:
+        return pd.DataFrame(columns=gap_columns)
+    frame_ticks = tick_steps.median()
+
+    ticks = apply_round_num(rounds_df, ticks)
+    ticks["start_tick"] = ticks["tick"].shift()
+    ticks["missing_frames"] = (tick_steps / frame_ticks).round() - 1
+    gaps_df = ticks[
+        (ticks["round"] > 0)
+        & (ticks["round"] == ticks["round"].shift())
+        & (ticks["missing_frames"] >= min_missing_frames)
+    ].rename(columns={"tick": "end_tick"})
+    gaps_df["start_tick"] = gaps_df["start_tick"].astype(int)
+    gaps_df["missing_frames"] = gaps_df["missing_frames"].astype(int)
+    return gaps_df[gap_columns].reset_index(drop=True)
+
+
+def parse_timing(
+    ticks_df: pd.DataFrame, tick_rate: int = DEFAULT_TICK_RATE
+) -> dict[str, Optional[float]]:
+    """Measure how far recorded ticks drift from the in-game clock.
+
+    Each recorded frame should be `tick_rate` ticks per second of `game_time`
+    apart. Drift from that, and frames that aren't the usual number of ticks
+    apart, show where GOTV interpolation may have shifted positions.
+
+    Args:
+        ticks_df (pd.DataFrame): A dataframe with `tick` and `game_time`
+            columns, before any downsampling.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        dict[str, Optional[float]]: The number of frames, the median offset
+            between the tick and the clock, the largest and typical drift
+            from that offset, in ticks, and the share of frames that aren't
+            the usual number of ticks after the previous one.
+    """
+    timing = {
+        "n_frames": 0,
+        "tick_offset": None,
+        "max_drift_ticks": None,
+        "drift_std_ticks": None,
+        "irregular_frame_share": None,
+    }
+    if ticks_df is None or not {"tick", "game_time"}.issubset(ticks_df.columns):
+        return timing
+
+    frames = (
+        ticks_df[["tick", "game_time"]]
+        .dropna()
+        .drop_duplicates("tick")
+        .sort_values("tick")
+    )
+    tick_steps = frames["tick"].diff().dropna()
+    if tick_steps.shape[0] == 0:
+        return timing
+
+    offsets = frames["tick"] - frames["game_time"] * tick_rate
+    drifts = offsets - offsets.median()
+    timing.update(
+        {
+            "n_frames": int(frames.shape[0]),
+            "tick_offset": float(offsets.median()),
+            "max_drift_ticks": float(drifts.abs().max()),
+            "drift_std_ticks": float(drifts.std()),
+            "irregular_frame_share": float(
+                (tick_steps != tick_steps.median()).mean()
+            ),
+        }
+    )
+    return timing
+
+
+def parse_wall_times(
+    rounds_df: pd.DataFrame,
+    recording_end: Optional[pd.Timestamp],
+    last_tick: int,
+    tick_rate: int = DEFAULT_TICK_RATE,
+) -> pd.DataFrame:
+    """Add the real-world start and end times of each round.
+
+    Demos don't store when they were recorded, so times are counted back from
+    when the recording ended, e.g., from the match page or the server logs.
+    Pauses still advance ticks, so the times stay aligned with external
+    timelines like VODs.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        recording_end (pd.Timestamp, optional): When the recording ended. If
+            None, the wall times are left empty.
+        last_tick (int): The last tick of the demo.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with `start_wall_time` and `end_wall_time`.
+    """
+    for tick_col in ["start", "end"]:
+        if recording_end is None:
+            rounds_df[f"{tick_col}_wall_time"] = pd.NaT
+            continue
+        seconds_before_end = (last_tick - rounds_df[tick_col]) / tick_rate
+        rounds_df[f"{tick_col}_wall_time"] = recording_end - pd.to_timedelta(
+            seconds_before_end, unit="s"
+        )
+    return rounds_df
 
 
 def parse_times(
-    df: pd.DataFrame, rounds_df: pd.DataFrame, tick_col: str = "tick"
+    df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    tick_col: str = "tick",
+    tick_rate: int = DEFAULT_TICK_RATE,
+    c4_timer: int = BOMB_DEFAULT_TIME_IN_SECS,
+    *,
+    include_clock: bool = True,
 ) -> pd.DataFrame:
     """Adds time_since_* columns to the dataframe.
 
@@ -75,6 +391,11 @@ def parse_times(
         df (pd.DataFrame): The dataframe to add the time columns to.
         rounds_df (pd.DataFrame): The rounds dataframe.
         tick_col (str): The column name of the tick column.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        c4_timer (int, optional): Seconds from a bomb plant to its explosion,
+            i.e., `mp_c4timer`. Defaults to 40.
+        include_clock (bool, optional): Whether to add the clock string, which is
+            slow for large dataframes like ticks. Defaults to True.
 
     Returns:
         pd.DataFrame: The dataframe with the timesince_* columns added.
@@ -83,7 +404,12 @@ def parse_times(
         tick_col_missing_msg = f"{tick_col} not found in dataframe."
         raise ValueError(tick_col_missing_msg)
 
-    df_with_round_info = df.merge(rounds_df, on="round", how="left")
+    df_with_round_info = df.merge(
+        rounds_df[["round", "start", "freeze_end", "end", "bomb_plant"]],
+        on="round",
+        how="left",
+    )
+    df_with_round_info = parse_phases(df_with_round_info, tick_col, tick_rate)
     df_with_round_info["ticks_since_round_start"] = (
         df_with_round_info[tick_col] - df_with_round_info["start"]
     )
@@ -104,17 +430,23 @@ def parse_times(
             )
 
     df_with_round_info = df_with_round_info.drop(
-        columns=[
-            "start",
-            "freeze_end",
-            "end",
-            "official_end",
-            "winner",
-            "reason",
-            "bomb_plant",
-        ]
+        columns=["start", "freeze_end", "end", "bomb_plant"]
     )
 
-    df_with_round_info["clock"] = df_with_round_info.apply(_find_clock_time, axis=1)
+    df_with_round_info["time_remaining"] = parse_time_remaining(
+        df_with_round_info, tick_rate, c4_timer
+    )
+
+    # Seconds until the bomb explodes, if it's planted
+    seconds_since_plant = (
+        df_with_round_info["ticks_since_bomb_plant"].astype("float64") / tick_rate
+    )
+    df_with_round_info["bomb_time_remaining"] = (
+        c4_timer - seconds_since_plant
+    ).clip(lower=0)
+    if include_clock:
+        df_with_round_info["clock"] = df_with_round_info.apply(
+            _find_clock_time, tick_rate=tick_rate, c4_timer=c4_timer, axis=1
+        )
 
     return df_with_round_info
@@ -0,0 +1,36 @@
+"""Module for server convar parsing functions."""
+
+import pandas as pd
+
+CONVAR_CHANGE_COLUMNS = ["tick", "name", "old_value", "new_value"]
+
+
+def parse_convar_changes(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
+    """Parse a timeline of server convar changes, e.g., `mp_maxrounds`.
+
+    Servers announce a convar when it is first set and every time it changes,
+    so mid-match changes, like a longer timeout or new overtime settings, show
+    up as new rows. Announcements that don't change the value are dropped.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+
+    Returns:
+        pd.DataFrame: The convar changes. `old_value` is missing for the first
+            value of each convar.
+    """
+    server_cvars = events.get("server_cvar")
+    if server_cvars is None or not {"cvarname", "cvarvalue"}.issubset(
+        server_cvars.columns
+    ):
+        return pd.DataFrame(columns=CONVAR_CHANGE_COLUMNS)
+
+    changes_df = (
+        server_cvars[["tick", "cvarname", "cvarvalue"]]
+        .rename(columns={"cvarname": "name", "cvarvalue": "new_value"})
+        .sort_values("tick", kind="stable")
+    )
+    changes_df["new_value"] = changes_df["new_value"].astype(str)
+    changes_df["old_value"] = changes_df.groupby("name")["new_value"].shift()
+    is_change = changes_df["new_value"] != changes_df["old_value"]
+    return changes_df[is_change][CONVAR_CHANGE_COLUMNS].reset_index(drop=True)
@@ -0,0 +1,627 @@
+"""Module for equipment value and economy parsing functions."""
+
+from typing import Optional
+
+import numpy as np
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
+
+from awpy.data.equipment_data import (
+    ECONOMY_SETTINGS,
+    EQUIPMENT_DATA,
+    EQUIPMENT_NAMES,
+    GAME_VERSIONS,
+    ITEM_PRICES,
+    PRICE_OVERRIDES,
+)
+from awpy.parsers.utils import parse_col_types
+from awpy.utils import apply_round_num
+
+# Inventory item classes that make up each part of a loadout
+PRIMARY_CLASSES = ("smg", "heavy", "rifle")
+SECONDARY_CLASSES = ("pistol",)
+UTILITY_CLASSES = ("grenade",)
+UTILITY_ITEMS = ("Zeus x27",)
+
+# Round end reasons that pay the objective win bonus rather than the usual one
+OBJECTIVE_WIN_REASONS = ("bomb_exploded", "bomb_defused")
+TEAM_NAMES = {"CT": "CT", "T": "TERRORIST", "TERRORIST": "TERRORIST"}
+
+# Server convars that override the economy settings, and the setting of each
+ECONOMY_CONVARS = {
+    "mp_startmoney": "start_money",
+    "mp_overtime_startmoney": "overtime_start_money",
+    "mp_maxmoney": "max_money",
+    "cash_team_loser_bonus": "loss_bonus",
+    "cash_team_loser_bonus_consecutive_rounds": "loss_bonus_increment",
+    "cash_team_elimination_bomb_map": "win_bonus",
+    "cash_team_win_by_defusing_bomb": "objective_win_bonus",
+    "cash_team_planted_bomb_but_defused": "bomb_plant_bonus",
+}
+
+
+def get_game_version(header: dict) -> str:
+    """Get the game version of a demo from its header.
+
+    Args:
+        header (dict): The parsed demo header.
+
+    Returns:
+        str: `csgo` for Source 1 demos, otherwise `cs2`.
+    """
+    if str(header.get("demo_file_stamp", "")).startswith("HL2DEMO"):
+        return "csgo"
+    return "cs2"
+
+
+def get_prices(game: str = "cs2") -> dict[str, int]:
+    """Get the price of every weapon and item for a game version.
+
+    Args:
+        game (str, optional): The game version, one of `GAME_VERSIONS`.
+            Defaults to "cs2".
+
+    Returns:
+        dict[str, int]: Prices keyed by weapon name (e.g., `ak47`) and item name
+            (e.g., `kevlar`).
+
+    Raises:
+        ValueError: If the game version is unknown.
+    """
+    if game not in GAME_VERSIONS:
+        unknown_game_msg = f"Unknown game version: {game}. Use one of {GAME_VERSIONS}."
+        raise ValueError(unknown_game_msg)
+    prices = {weapon: data["price"] for weapon, data in EQUIPMENT_DATA.items()}
+    prices.update(ITEM_PRICES)
+    prices.update(PRICE_OVERRIDES[game])
+    return prices
+
+
+def _sum_inventory_value(
+    inventory: list, prices: dict[str, int], classes: tuple, items: tuple = ()
+) -> int:
+    """Sum the price of the inventory items in the given classes."""
+    if not isinstance(inventory, (list, tuple, np.ndarray)):
+        return 0
+    value = 0
+    for item in inventory:
+        weapon = EQUIPMENT_NAMES.get(item)
+        if weapon is None:
+            continue
+        if EQUIPMENT_DATA[weapon]["class"] in classes or item in items:
+            value += prices[weapon]
+    return value
+
+
+def parse_equipment_values(
+    df: pd.DataFrame, prefix: str = "", game: str = "cs2"
+) -> pd.DataFrame:
+    """Break down the equipment value of each player by loadout category.
+
+    The categories are the primary weapon, the secondary weapon, armor, utility
+    (grenades and the Zeus) and the defuse kit. Knives and the bomb are free.
+
+    Args:
+        df (pd.DataFrame): Dataframe with a `{prefix}inventory` column and,
+            optionally, `{prefix}armor_value`, `{prefix}has_helmet` and
+            `{prefix}has_defuser` columns.
+        prefix (str, optional): Column prefix, e.g., `victim_`. Defaults to "".
+        game (str, optional): The game version to price items with. Defaults
+            to "cs2".
+
+    Returns:
+        pd.DataFrame: `df` with `{prefix}primary_value`, `{prefix}secondary_value`,
+            `{prefix}armor_equipment_value`, `{prefix}utility_value`,
+            `{prefix}kit_value` and `{prefix}equipment_value` columns.
+
+    Raises:
+        KeyError: If the inventory column is missing.
+        ValueError: If the game version is unknown.
+    """
+    inventory_col = f"{prefix}inventory"
+    if inventory_col not in df.columns:
+        inventory_missing_msg = f"{inventory_col} column not found in dataframe."
+        raise KeyError(inventory_missing_msg)
+    prices = get_prices(game)
+
+    df[f"{prefix}primary_value"] = df[inventory_col].map(
+        lambda inventory: _sum_inventory_value(inventory, prices, PRIMARY_CLASSES)
+    )
+    df[f"{prefix}secondary_value"] = df[inventory_col].map(
+        lambda inventory: _sum_inventory_value(inventory, prices, SECONDARY_CLASSES)
+    )
+    df[f"{prefix}utility_value"] = df[inventory_col].map(
+        lambda inventory: _sum_inventory_value(
+            inventory, prices, UTILITY_CLASSES, UTILITY_ITEMS
+        )
+    )
+
+    # Armor and kits are player props rather than inventory items
+    armor_col, helmet_col = f"{prefix}armor_value", f"{prefix}has_helmet"
+    if armor_col in df.columns:
+        has_armor = df[armor_col].fillna(0) > 0
+        has_helmet = (
+            df[helmet_col].fillna(False).astype(bool)
+            if helmet_col in df.columns
+            else False
+        )
+        df[f"{prefix}armor_equipment_value"] = 0
+        df.loc[has_armor, f"{prefix}armor_equipment_value"] = prices["kevlar"]
+        df.loc[has_armor & has_helmet, f"{prefix}armor_equipment_value"] = prices[
+            "assaultsuit"
+        ]
+    else:
+        df[f"{prefix}armor_equipment_value"] = 0
+
+    defuser_col = f"{prefix}has_defuser"
+    if defuser_col in df.columns:
+        df[f"{prefix}kit_value"] = (
+            df[defuser_col].fillna(False).astype(bool) * prices["defuser"]
+        )
+    else:
+        df[f"{prefix}kit_value"] = 0
+
+    df[f"{prefix}equipment_value"] = (
+        df[f"{prefix}primary_value"]
+        + df[f"{prefix}secondary_value"]
+        + df[f"{prefix}armor_equipment_value"]
+        + df[f"{prefix}utility_value"]
+        + df[f"{prefix}kit_value"]
+    )
+    return df
+
+
+def parse_victim_equipment(
+    kill_ticks_df: pd.DataFrame, kills_df: pd.DataFrame, game: str = "cs2"
+) -> pd.DataFrame:
+    """Add the equipment each victim carried to the kills.
+
+    Victims drop their weapons as they die, so their loadout is read on the tick
+    before the kill, including holstered weapons and utility.
+
+    Args:
+        kill_ticks_df (pd.DataFrame): The players around the kills. See
+            `awpy.parsers.ticks.parse_kill_ticks`.
+        kills_df (pd.DataFrame): The parsed kills.
+        game (str, optional): The game version to price items with. Defaults
+            to "cs2".
+
+    Returns:
+        pd.DataFrame: `kills_df` with the `victim_` equipment values of
+            `parse_equipment_values` and `victim_has_primary`.
+    """
+    value_cols = [
+        "primary_value",
+        "secondary_value",
+        "armor_equipment_value",
+        "utility_value",
+        "kit_value",
+        "equipment_value",
+        "has_primary",
+    ]
+    equipment_props = ["inventory", "armor_value", "has_helmet", "has_defuser"]
+    if kills_df.shape[0] == 0:
+        for col in value_cols:
+            kills_df[f"victim_{col}"] = pd.Series(dtype="float64")
+        return kills_df
+
+    equipment_df = parse_equipment_values(
+        kill_ticks_df[["tick", "steamid", *equipment_props]].copy(), game=game
+    )
+    equipment_df["has_primary"] = equipment_df["primary_value"] > 0
+    equipment_df["tick"] += 1
+    return kills_df.merge(
+        equipment_df[["tick", "steamid", *value_cols]].rename(
+            columns={
+                "steamid": "victim_steamid",
+                **{col: f"victim_{col}" for col in value_cols},
+            }
+        ),
+        on=["tick", "victim_steamid"],
+        how="left",
+    )
+
+
+def get_half_start_money(
+    round_num: int, game: str = "cs2", settings: Optional[dict] = None
+) -> int:
+    """Get the money players reset to if a round starts a half.
+
+    Args:
+        round_num (int): The round number.
+        game (str, optional): The game version. Defaults to "cs2".
+        settings (dict, optional): The economy settings, e.g., from
+            `get_economy_settings`. Defaults to None, which is
+            ECONOMY_SETTINGS for the game.
+
+    Returns:
+        int: The start money, or 0 if the round doesn't start a half.
+    """
+    if settings is None:
+        settings = ECONOMY_SETTINGS[game]
+    regulation_rounds = 2 * settings["half_rounds"]
+    if round_num <= regulation_rounds:
+        is_half_start = (round_num - 1) % settings["half_rounds"] == 0
+        return settings["start_money"] if is_half_start else 0
+    is_half_start = (round_num - regulation_rounds - 1) % settings[
+        "overtime_half_rounds"
+    ] == 0
+    return settings["overtime_start_money"] if is_half_start else 0
+
+
+def forecast_economy(
+    balances_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    convar_changes_df: pd.DataFrame,
+    game: str = "cs2",
+) -> pd.DataFrame:
+    """Predict each team's money for the next round from the round end.
+
+    Winners get the win bonus, which is larger for bomb explosions and
+    defuses. Losers get the loss bonus, which grows with their loss streak,
+    and Terrorists who planted get the plant bonus on top. Money resets at the
+    start of each half. The bonuses are the economy settings in effect at the
+    start of each round, so servers with other `cash_team_*` convars are
+    forecast with their own. Survivors who saved when time ran out are paid as
+    the rest of their team, so their money is overestimated.
+
+    Args:
+        balances_df (pd.DataFrame): The `balance` and `team_name` of every
+            player at the `end` tick of each round.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        convar_changes_df (pd.DataFrame): The convar changes, from
+            `parse_convar_changes`.
+        game (str, optional): The game version. Defaults to "cs2".
+
+    Returns:
+        pd.DataFrame: The `money`, `loss_streak`, `round_bonus` and predicted
+            `next_round_money` of each team at the end of each round.
+    """
+    economy_rows = []
+    starting_losses = dict.fromkeys(
+        ("CT", "TERRORIST"), ECONOMY_SETTINGS[game]["starting_losses"]
+    )
+    loss_streaks = starting_losses.copy()
+    for round_row in rounds_df.sort_values("round").itertuples():
+        settings = get_economy_settings(convar_changes_df, round_row.start, game)
+        if get_half_start_money(round_row.round, game, settings) > 0:
+            loss_streaks = starting_losses.copy()
+        next_start_money = get_half_start_money(round_row.round + 1, game, settings)
+        winner = TEAM_NAMES.get(round_row.winner)
+        round_balances = balances_df[balances_df["tick"] == round_row.end]
+
+        for team_name in ("CT", "TERRORIST"):
+            round_won = team_name == winner
+            if round_won:
+                loss_streaks[team_name] = (
+                    0
+                    if settings["reset_losses_on_win"]
+                    else max(loss_streaks[team_name] - 1, 0)
+                )
+                round_bonus = (
+                    settings["objective_win_bonus"]
+                    if round_row.reason in OBJECTIVE_WIN_REASONS
+                    else settings["win_bonus"]
+                )
+            else:
+                loss_streaks[team_name] += 1
+                round_bonus = min(
+                    settings["loss_bonus"]
+                    + settings["loss_bonus_increment"] * (loss_streaks[team_name] - 1),
+                    settings["max_loss_bonus"],
+                )
+                if team_name == "TERRORIST" and pd.notna(round_row.bomb_plant):
+                    round_bonus += settings["bomb_plant_bonus"]
+
+            team_balances = round_balances.loc[
+                round_balances["team_name"] == team_name, "balance"
+            ]
+            next_round_money = (
+                next_start_money * len(team_balances)
+                if next_start_money > 0
+                else int(
+                    np.minimum(
+                        team_balances + round_bonus, settings["max_money"]
+                    ).sum()
+                )
+            )
+            economy_rows.append(
+                {
+                    "round": round_row.round,
+                    "team_name": team_name,
+                    "n_players": len(team_balances),
+                    "money": int(team_balances.sum()),
+                    "round_won": round_won,
+                    "loss_streak": loss_streaks[team_name],
+                    "round_bonus": round_bonus,
+                    "next_round_money": next_round_money,
+                }
+            )
+
+    return pd.DataFrame(
+        economy_rows,
+        columns=[
+            "round",
+            "team_name",
+            "n_players",
+            "money",
+            "round_won",
+            "loss_streak",
+            "round_bonus",
+            "next_round_money",
+        ],
+    )
+
+
+def parse_round_balances(parser: DemoParser, rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse the money of every player at the start and end of each round.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The `steamid`, `name`, `team_name` and `balance` of every
+            player at the `start` and `end` tick of each round, for
+            `forecast_economy` and `build_economy_seeds`.
+    """
+    ticks = pd.concat([rounds_df["start"], rounds_df["end"]]).dropna().astype(int)
+    if len(ticks) == 0:
+        return pd.DataFrame(columns=["tick", "steamid", "name", "team_name", "balance"])
+    return parse_col_types(
+        parser.parse_ticks(
+            wanted_props=["team_name", "balance"], ticks=ticks.unique().tolist()
+        )
+    )
+
+
+def get_economy_settings(
+    convar_changes_df: pd.DataFrame, tick: int, game: str = "cs2"
+) -> dict:
+    """Get the economy settings in effect at a tick.
+
+    Args:
+        convar_changes_df (pd.DataFrame): The convar changes, from
+            `parse_convar_changes`.
+        tick (int): The tick.
+        game (str, optional): The game version, for the default settings.
+            Defaults to "cs2".
+
+    Returns:
+        dict: ECONOMY_SETTINGS for the game, with the ECONOMY_CONVARS set up
+            to `tick` applied.
+    """
+    settings = ECONOMY_SETTINGS[game].copy()
+    changes = convar_changes_df[
+        (convar_changes_df["tick"] <= tick)
+        & convar_changes_df["name"].isin(ECONOMY_CONVARS)
+    ].sort_values("tick", kind="stable")
+    for change in changes.itertuples():
+        value = pd.to_numeric(change.new_value, errors="coerce")
+        if pd.notna(value):
+            settings[ECONOMY_CONVARS[change.name]] = int(value)
+    return settings
+
+
+def build_economy_seeds(
+    balances_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    economy_df: pd.DataFrame,
+    convar_changes_df: pd.DataFrame,
+    game: str = "cs2",
+) -> pd.DataFrame:
+    """Gather the inputs to re-simulate the economy of each round.
+
+    Each row has a player's money at the start and end of a round, the team's
+    loss streak and round bonus from `forecast_economy`, how the round ended
+    and the economy settings in effect at the start of the round, so a
+    simulator can be checked against the money players actually had.
+
+    Args:
+        balances_df (pd.DataFrame): The `steamid`, `name`, `team_name` and
+            `balance` of every player at the `start` and `end` tick of each
+            round.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        economy_df (pd.DataFrame): The economy, from `forecast_economy`.
+        convar_changes_df (pd.DataFrame): The convar changes, from
+            `parse_convar_changes`.
+        game (str, optional): The game version. Defaults to "cs2".
+
+    Returns:
+        pd.DataFrame: One row per player and round, with `start_money`,
+            `end_money`, `loss_streak`, `round_bonus`, `winner`, `reason`,
+            `bomb_plant` and a column for each economy setting.
+    """
+    seed_columns = [
+        "round",
+        "steamid",
+        "name",
+        "team_name",
+        "start_money",
+        "end_money",
+        "loss_streak",
+        "round_bonus",
+        "winner",
+        "reason",
+        "bomb_plant",
+        *ECONOMY_SETTINGS[game],
+    ]
+    seeds = []
+    for round_row in rounds_df.sort_values("round").itertuples():
+        start_balances = balances_df[balances_df["tick"] == round_row.start]
+        end_balances = balances_df.loc[
+            balances_df["tick"] == round_row.end, ["steamid", "balance"]
+        ].rename(columns={"balance": "end_money"})
+        round_seeds = (
+            start_balances[["steamid", "name", "team_name", "balance"]]
+            .rename(columns={"balance": "start_money"})
+            .merge(end_balances, on="steamid", how="left")
+        )
+        round_seeds = round_seeds[round_seeds["team_name"].isin(["CT", "TERRORIST"])]
+        settings = get_economy_settings(convar_changes_df, round_row.start, game)
+        seeds.append(
+            round_seeds.assign(
+                round=round_row.round,
+                winner=round_row.winner,
+                reason=round_row.reason,
+                bomb_plant=round_row.bomb_plant,
+                **settings,
+            )
+        )
+    if len(seeds) == 0:
+        return pd.DataFrame(columns=seed_columns)
+
+    seeds_df = pd.concat(seeds, ignore_index=True).merge(
+        economy_df[["round", "team_name", "loss_streak", "round_bonus"]],
+        on=["round", "team_name"],
+        how="left",
+    )
+    return seeds_df[seed_columns]
+
+
+def parse_zeus_purchases(
+    economy_seeds_df: pd.DataFrame, buy_log_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Add how many Zeus x27s each player bought in each round, less refunds.
+
+    Args:
+        economy_seeds_df (pd.DataFrame): The economy seeds, from
+            `build_economy_seeds`.
+        buy_log_df (pd.DataFrame): The buy log, from `build_buy_log`.
+
+    Returns:
+        pd.DataFrame: `economy_seeds_df` with `zeus_purchases`.
+    """
+    zeus_log = buy_log_df[buy_log_df["weapon"] == "taser"]
+    zeus_purchases = (
+        zeus_log["action"]
+        .map({"buy": 1, "refund": -1})
+        .groupby([zeus_log["round"], zeus_log["steamid"].astype(str)])
+        .sum()
+        .rename("zeus_purchases")
+        .reset_index()
+    )
+    economy_seeds_df = economy_seeds_df.drop(
+        columns=["zeus_purchases"], errors="ignore"
+    ).merge(zeus_purchases, on=["round", "steamid"], how="left")
+    economy_seeds_df["zeus_purchases"] = (
+        economy_seeds_df["zeus_purchases"].fillna(0).astype(int)
+    )
+    return economy_seeds_df
+
+
+def build_buy_log(
+    purchases_df: pd.DataFrame,
+    spent_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    game: str = "cs2",
+) -> pd.DataFrame:
+    """Log the buys and refunds of each player during freeze time.
+
+    Refunds have no event, so they are found where a player's
+    `cash_spent_this_round` drops, and matched to their latest unrefunded buy
+    of the same price. Without `item_purchase` events, buys are found where the
+    money spent rises, with an unknown weapon.
+
+    Args:
+        purchases_df (pd.DataFrame): The `item_purchase` events.
+        spent_df (pd.DataFrame): The `cash_spent_this_round` of every player at
+            every freeze time tick.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        game (str, optional): The game version. Defaults to "cs2".
+
+    Returns:
+        pd.DataFrame: Every `buy` and `refund` action, with the weapon and price.
+    """
+    buy_columns = ["round", "tick", "name", "steamid", "action", "weapon", "price"]
+    prices = get_prices(game)
+    freeze_times = rounds_df[["round", "start", "freeze_end"]].dropna()
+
+    def _in_freeze_time(df: pd.DataFrame) -> pd.DataFrame:
+        df = apply_round_num(rounds_df, df.copy()).merge(freeze_times, on="round")
+        return df[df["tick"].between(df["start"], df["freeze_end"])]
+
+    # Money spent only moves on buys and refunds, and resets between rounds
+    spent_df = _in_freeze_time(parse_col_types(spent_df.copy())).sort_values(
+        ["steamid", "tick"]
+    )
+    spent_df["spent_change"] = spent_df.groupby(["steamid", "round"])[
+        "cash_spent_this_round"
+    ].diff()
+    spent_changes = spent_df[spent_df["spent_change"].fillna(0) != 0]
+
+    if purchases_df is not None and purchases_df.shape[0] > 0:
+        buys = _in_freeze_time(parse_col_types(purchases_df.copy())).rename(
+            columns={"user_name": "name", "user_steamid": "steamid"}
+        )
+        buys["weapon"] = (
+            buys["weapon"]
+            .astype(str)
+            .str.removeprefix("weapon_")
+            .str.removeprefix("item_")
+        )
+        buys["price"] = buys["weapon"].map(prices)
+    else:
+        buys = spent_changes[spent_changes["spent_change"] > 0].copy()
+        buys["weapon"] = None
+        buys["price"] = buys["spent_change"]
+    buys["action"] = "buy"
+
+    refunds = spent_changes[spent_changes["spent_change"] < 0].copy()
+    refunds["action"] = "refund"
+    refunds["price"] = -refunds["spent_change"]
+    refunds["weapon"] = None
+    refunded = set()
+    for refund_idx, refund in refunds.iterrows():
+        candidates = buys[
+            (buys["round"] == refund["round"])
+            & (buys["steamid"] == refund["steamid"])
+            & (buys["tick"] <= refund["tick"])
+            & (buys["price"] == refund["price"])
+            & ~buys.index.isin(refunded)
+        ]
+        if candidates.shape[0] > 0:
+            buy_idx = candidates["tick"].idxmax()
+            refunded.add(buy_idx)
+            refunds.loc[refund_idx, "weapon"] = buys.loc[buy_idx, "weapon"]
+
+    buy_log = pd.concat([buys[buy_columns], refunds[buy_columns]], ignore_index=True)
+    return buy_log.sort_values(["tick", "steamid"], kind="stable").reset_index(
+        drop=True
+    )
+
+
+def parse_buy_log(
+    parser: DemoParser,
+    events: dict[str, pd.DataFrame],
+    rounds_df: pd.DataFrame,
+    game: str = "cs2",
+) -> pd.DataFrame:
+    """Parse what each player bought and refunded in the buy menu.
+
+    Unlike the loadouts, this includes items that were refunded, dropped for a
+    teammate or bought over a picked-up weapon.
+
+    Args:
+        parser (DemoParser): The parser object.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        game (str, optional): The game version. Defaults to "cs2".
+
+    Returns:
+        pd.DataFrame: The buy log. See `build_buy_log`.
+    """
+    freeze_ticks = [
+        tick
+        for start, freeze_end in rounds_df[["start", "freeze_end"]]
+        .dropna()
+        .astype(int)
+        .itertuples(index=False)
+        for tick in range(start, freeze_end + 1)
+    ]
+    spent_df = (
+        parser.parse_ticks(wanted_props=["cash_spent_this_round"], ticks=freeze_ticks)
+        if len(freeze_ticks) > 0
+        else pd.DataFrame(columns=["tick", "name", "steamid", "cash_spent_this_round"])
+    )
+    return build_buy_log(events.get("item_purchase"), spent_df, rounds_df, game)
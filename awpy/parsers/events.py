@@ -1,5 +1,7 @@
 """Module for event parsing functions."""
 
+from typing import Optional
+
 import numpy as np
 import pandas as pd
 from demoparser2 import DemoParser  # pylint: disable=E0611
@@ -7,9 +9,103 @@ from loguru import logger
 
 from awpy.converters import (
     map_hitgroup,
+    map_weapon_class,
+)
+from awpy.data.equipment_data import (
+    EQUIPMENT_DATA,
+    EQUIPMENT_NAMES,
+    KNIFE_PREFIXES,
+    WEAPON_CYCLE_TIMES,
 )
+from awpy.parsers.clock import BOMB_DEFAULT_TIME_IN_SECS
+from awpy.parsers.economy import get_prices
 from awpy.parsers.ticks import remove_nonplay_ticks
-from awpy.parsers.utils import parse_col_types
+from awpy.parsers.utils import parse_col_types, parse_stance
+from awpy.utils import apply_round_num
+
+# Seconds it takes to defuse the bomb, with and without a kit
+DEFUSE_SECONDS = 10
+DEFUSE_KIT_SECONDS = 5
+
+# Bomb sounds, from the bomb events they are heard on
+BOMB_MARKER_EVENTS = {
+    "bomb_planted": "plant",
+    "bomb_beep": "beep",
+    "bomb_begindefuse": "defuse_start",
+    "bomb_abortdefuse": "defuse_abort",
+    "bomb_defused": "defused",
+    "bomb_exploded": "exploded",
+}
+
+# Knives every player spawns with, rather than a skin's model, e.g., `bayonet`
+DEFAULT_KNIVES = ("knife", "knife_t")
+
+# Knife hits backstab when the victim faces away from the attacker, i.e., the
+# victim's view and the attacker-to-victim direction are within about 60 degrees
+BACKSTAB_MIN_DOT = 0.475
+
+# Utility is early in the first seconds after freeze time and late in the last
+# seconds on the clock
+EARLY_UTILITY_SECONDS = 30
+LATE_UTILITY_SECONDS = 20
+UTILITY_TIMINGS = ("early", "mid", "late")
+
+# Bomb state after each bomb event
+BOMB_STATE_EVENTS = {
+    "bomb_pickup": "carried",
+    "bomb_dropped": "dropped",
+    "bomb_planted": "planted",
+    "bomb_defused": "defused",
+    "bomb_exploded": "exploded",
+}
+
+# A dropped bomb is unattended when nobody picks it up for this long, and left
+# behind when every alive Terrorist is farther than this from it for this long
+BOMB_UNATTENDED_SECONDS = 15
+BOMB_LEFT_BEHIND_DISTANCE = 1500.0
+BOMB_LEFT_BEHIND_SECONDS = 10
+BOMB_WARNING_SAMPLE_SECONDS = 1
+
+BOMB_WARNING_COLUMNS = [
+    "round",
+    "warning",
+    "start_tick",
+    "end_tick",
+    "duration_seconds",
+    "end_reason",
+    "dropper_name",
+    "dropper_steamid",
+    "X",
+    "Y",
+    "Z",
+]
+
+# Columns of smokes and infernos, kept even when a demo has none
+EFFECT_COLUMNS = [
+    "entity_id",
+    "start_tick",
+    "end_tick",
+    "thrower_name",
+    "thrower_team_clan_name",
+    "thrower_team_name",
+    "thrower_steamid",
+    "X",
+    "Y",
+    "Z",
+]
+
+# Grenades that start an inferno
+INFERNO_GRENADES = ["molotov", "incgrenade"]
+
+# Columns that identify a grenade and its thrower in the grenade effects
+GRENADE_EFFECT_KEYS = [
+    "grenade_type",
+    "entity_id",
+    "tick",
+    "thrower_name",
+    "thrower_steamid",
+    "thrower_team_name",
+]
 
 
 def parse_grenades(parser: DemoParser) -> pd.DataFrame:
@@ -58,6 +154,11 @@ def parse_kills(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
     # Filter out nonplay ticks
     kill_df = parse_col_types(remove_nonplay_ticks(kill_df))
 
+    # Death notice info, which not every demo has
+    death_notice_cols = [
+        col for col in ["dominated", "revenge", "wipe"] if col in kill_df.columns
+    ]
+
     # Get only relevant columns
     kill_df = kill_df[
         [
@@ -112,6 +213,8 @@ def parse_kills(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "attacker_team_clan_name",
             "attacker_name",
             "attacker_steamid",
+            "attacker_duck_amount",
+            "attacker_velocity_Z",
             # Victim
             "user_X",
             "user_Y",
@@ -131,6 +234,9 @@ def parse_kills(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "user_team_clan_name",
             "user_name",
             "user_steamid",
+            "user_duck_amount",
+            "user_velocity_Z",
+            *death_notice_cols,
         ]
     ]
 
@@ -142,6 +248,15 @@ def parse_kills(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
     # Convert hitgroup to string
     kill_df["hitgroup"] = map_hitgroup(kill_df["hitgroup"])
 
+    # Stances at the time of the kill
+    kill_df["attacker_stance"] = parse_stance(kill_df, "attacker_")
+    kill_df["victim_stance"] = parse_stance(kill_df, "victim_")
+
+    # Kill feed order, keeping the event order for kills on the same tick
+    kill_df = kill_df.sort_values("tick", kind="stable").reset_index(drop=True)
+    kill_df["kill_feed_index"] = kill_df.index
+    kill_df["tick_kill_index"] = kill_df.groupby("tick").cumcount()
+
     return kill_df
 
 
@@ -211,6 +326,7 @@ def parse_damages(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "user_team_name",
             "user_team_clan_name",
             "user_name",
+            "user_steamid",
         ]
     ]
 
@@ -228,10 +344,252 @@ def parse_damages(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
         damage_df["victim_health"],
         damage_df["dmg_health"],
     )
+    damage_df["dmg_armor_real"] = np.where(
+        damage_df["dmg_armor"] > damage_df["victim_armor_value"],
+        damage_df["victim_armor_value"],
+        damage_df["dmg_armor"],
+    )
+
+    # Victim state before and after the hit
+    damage_df["victim_health_before"] = damage_df["victim_health"]
+    damage_df["victim_health_after"] = (
+        damage_df["victim_health"] - damage_df["dmg_health_real"]
+    )
+    damage_df["victim_armor_before"] = damage_df["victim_armor_value"]
+    damage_df["victim_armor_after"] = (
+        damage_df["victim_armor_value"] - damage_df["dmg_armor_real"]
+    )
 
     return damage_df
 
 
+def parse_attacker_ammo(
+    kill_ticks_df: pd.DataFrame, kills_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Add the ammo left in the attacker's weapon at each kill.
+
+    The ammo is read on the kill tick, so it is what was left after the killing
+    shot, e.g., 0 for a kill with the last bullet of the magazine.
+
+    Args:
+        kill_ticks_df (pd.DataFrame): The players around the kills. See
+            `awpy.parsers.ticks.parse_kill_ticks`.
+        kills_df (pd.DataFrame): The parsed kills.
+
+    Returns:
+        pd.DataFrame: `kills_df` with `attacker_ammo_clip` and
+            `attacker_ammo_reserve`, which are missing for world kills.
+    """
+    ammo_cols = ["attacker_ammo_clip", "attacker_ammo_reserve"]
+    if kills_df.shape[0] == 0:
+        for col in ammo_cols:
+            kills_df[col] = pd.Series(dtype="float64")
+        return kills_df
+
+    ammo_df = kill_ticks_df[
+        ["tick", "steamid", "active_weapon_ammo", "total_ammo_left"]
+    ]
+    return kills_df.merge(
+        ammo_df.rename(
+            columns={
+                "steamid": "attacker_steamid",
+                "active_weapon_ammo": "attacker_ammo_clip",
+                "total_ammo_left": "attacker_ammo_reserve",
+            }
+        ),
+        on=["tick", "attacker_steamid"],
+        how="left",
+    )
+
+
+def parse_melee_kills(kills_df: pd.DataFrame) -> pd.DataFrame:
+    """Add the knife type of knife kills, whether they were backstabs, and Zeus kills.
+
+    Demos don't flag backstabs, so a knife kill is a backstab when the victim
+    was facing away from the attacker, as the game checks it.
+
+    Args:
+        kills_df (pd.DataFrame): The parsed kills, with attacker and victim
+            positions and `victim_yaw`.
+
+    Returns:
+        pd.DataFrame: `kills_df` with `knife_type`, which is "default" for the
+            knives players spawn with, the model (e.g., "karambit") for skins
+            and missing for other weapons, `is_backstab` and `is_zeus_kill`.
+    """
+    weapons = kills_df["weapon"].fillna("").astype(str).str.removeprefix("weapon_")
+    is_knife = weapons.str.startswith(KNIFE_PREFIXES)
+    kills_df["knife_type"] = (
+        weapons.where(~weapons.isin(DEFAULT_KNIVES), "default")
+        .str.removeprefix("knife_")
+        .where(is_knife)
+    )
+
+    to_victim = np.column_stack(
+        [
+            kills_df["victim_X"].to_numpy(dtype=float)
+            - kills_df["attacker_X"].to_numpy(dtype=float),
+            kills_df["victim_Y"].to_numpy(dtype=float)
+            - kills_df["attacker_Y"].to_numpy(dtype=float),
+        ]
+    )
+    victim_yaw = np.radians(kills_df["victim_yaw"].to_numpy(dtype=float))
+    with np.errstate(invalid="ignore", divide="ignore"):
+        facing_dot = (
+            np.cos(victim_yaw) * to_victim[:, 0] + np.sin(victim_yaw) * to_victim[:, 1]
+        ) / np.linalg.norm(to_victim, axis=1)
+    kills_df["is_backstab"] = is_knife.to_numpy() & (facing_dot > BACKSTAB_MIN_DOT)
+    kills_df["is_zeus_kill"] = weapons == "taser"
+    return kills_df
+
+
+def parse_kill_contributions(
+    kills_df: pd.DataFrame, damages_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Parse every player who contributed to each kill.
+
+    Contributors are the attacker, the assister (or flash assister) and anyone
+    else who damaged the victim during the life that ended with the kill.
+
+    Args:
+        kills_df (pd.DataFrame): The parsed kills.
+        damages_df (pd.DataFrame): The parsed damages.
+
+    Returns:
+        pd.DataFrame: One row per kill and contributor, with the contributor's
+            `role` and the health `damage` they dealt to the victim.
+    """
+    contribution_columns = [
+        "kill_feed_index",
+        "tick",
+        "victim_name",
+        "victim_steamid",
+        "contributor_name",
+        "contributor_steamid",
+        "contributor_team_name",
+        "role",
+        "damage",
+    ]
+    if kills_df.shape[0] == 0:
+        return pd.DataFrame(columns=contribution_columns)
+
+    # Each hit counts towards the victim's next death in the same round
+    life_keys = ["victim_steamid"]
+    if "round" in kills_df.columns and "round" in damages_df.columns:
+        life_keys.append("round")
+    hits_df = pd.merge_asof(
+        damages_df.sort_values("tick"),
+        kills_df[["tick", "kill_feed_index", *life_keys]].sort_values("tick"),
+        on="tick",
+        by=life_keys,
+        direction="forward",
+    ).dropna(subset=["kill_feed_index"])
+    hits_df["kill_feed_index"] = hits_df["kill_feed_index"].astype(int)
+    damage_by_player = (
+        hits_df.groupby(["kill_feed_index", "attacker_steamid"])["dmg_health_real"]
+        .sum()
+        .rename("damage")
+        .reset_index()
+        .rename(columns={"attacker_steamid": "contributor_steamid"})
+    )
+
+    kill_info = ["kill_feed_index", "tick", "victim_name", "victim_steamid"]
+    attackers = kills_df[
+        [*kill_info, "attacker_name", "attacker_steamid", "attacker_team_name"]
+    ].rename(columns=lambda col: col.replace("attacker_", "contributor_"))
+    attackers["role"] = "attacker"
+    assisters = kills_df.loc[
+        kills_df["assister_name"].notna(),
+        [*kill_info, "assister_name", "assister_steamid", "assister_team_name"],
+    ].rename(columns=lambda col: col.replace("assister_", "contributor_"))
+    assisters["role"] = np.where(
+        kills_df.loc[assisters.index, "assistedflash"], "flash_assister", "assister"
+    )
+    damagers = hits_df[
+        [
+            "kill_feed_index",
+            "attacker_name",
+            "attacker_steamid",
+            "attacker_team_name",
+        ]
+    ].drop_duplicates(subset=["kill_feed_index", "attacker_steamid"])
+    damagers = damagers.rename(
+        columns=lambda col: col.replace("attacker_", "contributor_")
+    )
+    damagers = damagers.merge(kills_df[kill_info], on="kill_feed_index")
+    damagers["role"] = "damage"
+
+    # Players with several roles keep the most direct one
+    role_order = {"attacker": 0, "assister": 1, "flash_assister": 2, "damage": 3}
+    contributions_df = pd.concat([attackers, assisters, damagers])
+    contributions_df = contributions_df[contributions_df["contributor_name"].notna()]
+    contributions_df["role_order"] = contributions_df["role"].map(role_order)
+    contributions_df = contributions_df.sort_values(
+        ["kill_feed_index", "role_order"]
+    ).drop_duplicates(subset=["kill_feed_index", "contributor_steamid"])
+    contributions_df = contributions_df.merge(
+        damage_by_player, on=["kill_feed_index", "contributor_steamid"], how="left"
+    )
+    contributions_df["damage"] = contributions_df["damage"].fillna(0).astype(int)
+    return contributions_df[contribution_columns].reset_index(drop=True)
+
+
+def parse_death_recaps(
+    kills_df: pd.DataFrame, damages_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Parse every hit each victim took during the life that ended in a death.
+
+    Like the in-game death recap, this lists each hit with who dealt it, with
+    what weapon, where it landed and how much it took.
+
+    Args:
+        kills_df (pd.DataFrame): The parsed kills.
+        damages_df (pd.DataFrame): The parsed damages.
+
+    Returns:
+        pd.DataFrame: One row per kill and hit, in the order the hits landed.
+    """
+    recap_columns = [
+        "kill_feed_index",
+        "death_tick",
+        "victim_name",
+        "victim_steamid",
+        "tick",
+        "attacker_name",
+        "attacker_steamid",
+        "attacker_team_name",
+        "weapon",
+        "hitgroup",
+        "damage",
+        "armor_damage",
+    ]
+    if kills_df.shape[0] == 0 or damages_df.shape[0] == 0:
+        return pd.DataFrame(columns=recap_columns)
+
+    # Each hit counts towards the victim's next death in the same round
+    life_keys = ["victim_steamid"]
+    if "round" in kills_df.columns and "round" in damages_df.columns:
+        life_keys.append("round")
+    recaps_df = pd.merge_asof(
+        damages_df.drop(columns=["victim_name"], errors="ignore").sort_values("tick"),
+        kills_df[["tick", "kill_feed_index", "victim_name", *life_keys]]
+        .assign(death_tick=kills_df["tick"])
+        .sort_values("tick"),
+        on="tick",
+        by=life_keys,
+        direction="forward",
+    ).dropna(subset=["kill_feed_index"])
+    recaps_df["kill_feed_index"] = recaps_df["kill_feed_index"].astype(int)
+    recaps_df = recaps_df.rename(
+        columns={"dmg_health_real": "damage", "dmg_armor_real": "armor_damage"}
+    )
+    return (
+        recaps_df[recap_columns]
+        .sort_values(["kill_feed_index", "tick"])
+        .reset_index(drop=True)
+    )
+
+
 def parse_bomb(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
     """Parse the bomb events of the demofile.
 
@@ -358,6 +716,230 @@ def parse_bomb(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
     return bomb_df
 
 
+def parse_defuses(
+    events: dict[str, pd.DataFrame], tick_rate: int = 64
+) -> pd.DataFrame:
+    """Parse each defuse attempt, from when it started to how it ended.
+
+    Attempts end when the bomb is defused, the defuser lets go (`aborted`), is
+    killed (`killed`) or the bomb explodes (`exploded`). Any outcome other than
+    `defused` is an interrupted defuse.
+
+    Args:
+        events: A dictionary of parsed events.
+        tick_rate: The tick rate of the server. Defaults to 64.
+
+    Returns:
+        The defuse attempts for the demofile.
+    """
+    defuse_columns = [
+        "start_tick",
+        "end_tick",
+        "outcome",
+        "is_interrupted",
+        "player_name",
+        "player_steamid",
+        "has_kit",
+        "required_seconds",
+        "elapsed_seconds",
+        "progress",
+        "n_hits_taken",
+        "damage_taken",
+    ]
+    begin_defuse = events.get("bomb_begindefuse")
+    if begin_defuse is None or begin_defuse.shape[0] == 0:
+        logger.warning("bomb_begindefuse not found in events.")
+        return pd.DataFrame(columns=defuse_columns)
+    begin_defuse = parse_col_types(remove_nonplay_ticks(begin_defuse))
+
+    # Every event that can end a defuse, with the player it applies to
+    defuse_ends = []
+    for event_name, steamid_col, outcome in [
+        ("bomb_defused", "user_steamid", "defused"),
+        ("bomb_abortdefuse", "user_steamid", "aborted"),
+        ("player_death", "user_steamid", "killed"),
+        ("bomb_exploded", None, "exploded"),
+    ]:
+        end_event = events.get(event_name)
+        if end_event is None or end_event.shape[0] == 0:
+            continue
+        end_event = parse_col_types(end_event.copy())
+        defuse_ends.append(
+            pd.DataFrame(
+                {
+                    "tick": end_event["tick"],
+                    "steamid": None if steamid_col is None else end_event[steamid_col],
+                    "outcome": outcome,
+                }
+            )
+        )
+    defuse_ends = (
+        pd.concat(defuse_ends)
+        if len(defuse_ends) > 0
+        else pd.DataFrame(columns=["tick", "steamid", "outcome"])
+    )
+    damages = events.get("player_hurt")
+
+    defuses = []
+    for _, start_row in begin_defuse.iterrows():
+        steamid = start_row["user_steamid"]
+        ends = defuse_ends[
+            (defuse_ends["tick"] >= start_row["tick"])
+            & (defuse_ends["steamid"].isna() | (defuse_ends["steamid"] == steamid))
+        ].sort_values("tick")
+        end_row = None if ends.empty else ends.iloc[0]
+        has_kit = bool(start_row.get("haskit", False))
+        required_seconds = DEFUSE_KIT_SECONDS if has_kit else DEFUSE_SECONDS
+        end_tick = None if end_row is None else end_row["tick"]
+        elapsed_seconds = (
+            None if end_tick is None else (end_tick - start_row["tick"]) / tick_rate
+        )
+
+        # Hits the defuser took while defusing
+        hits = pd.DataFrame()
+        if damages is not None and end_tick is not None:
+            hits = damages[
+                (damages["user_steamid"].astype(str) == steamid)
+                & (damages["tick"] >= start_row["tick"])
+                & (damages["tick"] <= end_tick)
+            ]
+
+        defuses.append(
+            {
+                "start_tick": start_row["tick"],
+                "end_tick": end_tick,
+                "outcome": None if end_row is None else end_row["outcome"],
+                "player_name": start_row["user_name"],
+                "player_steamid": steamid,
+                "has_kit": has_kit,
+                "required_seconds": required_seconds,
+                "elapsed_seconds": elapsed_seconds,
+                "n_hits_taken": len(hits),
+                "damage_taken": 0 if hits.empty else int(hits["dmg_health"].sum()),
+            }
+        )
+
+    defuse_df = pd.DataFrame(defuses, columns=defuse_columns)
+    defuse_df["is_interrupted"] = defuse_df["outcome"].notna() & (
+        defuse_df["outcome"] != "defused"
+    )
+    defuse_df["progress"] = (
+        defuse_df["elapsed_seconds"].astype("float64") / defuse_df["required_seconds"]
+    ).clip(upper=1)
+    return defuse_df[defuse_columns]
+
+
+def parse_bomb_markers(
+    events: dict[str, pd.DataFrame],
+    tick_rate: int = 64,
+    c4_timer: int = BOMB_DEFAULT_TIME_IN_SECS,
+) -> pd.DataFrame:
+    """Parse the ticks of bomb sounds, e.g., to sync a demo to VOD audio.
+
+    Markers are heard on the bomb events of `BOMB_MARKER_EVENTS`, like each
+    `beep` if the demo has `bomb_beep` events. Each plant also gets a
+    `ten_second_warning` and `five_second_warning`, from when a defuse without
+    and with a kit can no longer finish, if the bomb was still ticking then.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        c4_timer (int, optional): Seconds from a bomb plant to its explosion.
+            Defaults to BOMB_DEFAULT_TIME_IN_SECS.
+
+    Returns:
+        pd.DataFrame: The `tick`, `marker`, `player_name` and `player_steamid`
+            of each marker, sorted by tick.
+    """
+    marker_columns = ["tick", "marker", "player_name", "player_steamid"]
+    markers = []
+    for event_name, marker in BOMB_MARKER_EVENTS.items():
+        bomb_event = events.get(event_name)
+        if bomb_event is None or bomb_event.shape[0] == 0:
+            continue
+        bomb_event = parse_col_types(bomb_event.copy())
+        markers.append(
+            pd.DataFrame(
+                {
+                    "tick": bomb_event["tick"],
+                    "marker": marker,
+                    "player_name": bomb_event.get("user_name"),
+                    "player_steamid": bomb_event.get("user_steamid"),
+                }
+            )
+        )
+    if len(markers) == 0:
+        return pd.DataFrame(columns=marker_columns)
+    markers_df = pd.concat(markers, ignore_index=True)
+
+    # Warnings are only heard if the bomb wasn't defused or exploded before
+    plant_ticks = markers_df.loc[markers_df["marker"] == "plant", "tick"]
+    bomb_end_ticks = markers_df.loc[
+        markers_df["marker"].isin(["defused", "exploded"]), "tick"
+    ].sort_values()
+    warnings = []
+    for plant_tick in plant_ticks:
+        ends = bomb_end_ticks[bomb_end_ticks > plant_tick]
+        end_tick = ends.iloc[0] if len(ends) > 0 else np.inf
+        for marker, seconds_left in [
+            ("ten_second_warning", DEFUSE_SECONDS),
+            ("five_second_warning", DEFUSE_KIT_SECONDS),
+        ]:
+            warning_tick = plant_tick + (c4_timer - seconds_left) * tick_rate
+            if warning_tick < end_tick:
+                warnings.append({"tick": int(warning_tick), "marker": marker})
+    if len(warnings) > 0:
+        markers_df = pd.concat(
+            [markers_df, pd.DataFrame(warnings)], ignore_index=True
+        )
+    return (
+        markers_df[marker_columns]
+        .sort_values("tick", kind="stable")
+        .reset_index(drop=True)
+    )
+
+
+def parse_defuse_progress(
+    ticks_df: pd.DataFrame, defuses_df: pd.DataFrame, tick_rate: int = 64
+) -> pd.DataFrame:
+    """Add the defuse progress of players who are defusing to the ticks.
+
+    Args:
+        ticks_df (pd.DataFrame): The parsed ticks.
+        defuses_df (pd.DataFrame): The parsed defuse attempts.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: `ticks_df` with a `defuse_progress` column, the fraction
+            of the defuse done, which is NaN when the player isn't defusing.
+    """
+    defuses = defuses_df.dropna(subset=["end_tick"])[
+        ["player_steamid", "start_tick", "end_tick", "required_seconds"]
+    ].rename(columns={"player_steamid": "steamid"})
+    defuses["steamid"] = defuses["steamid"].astype(str)
+
+    # Pair each defuser's ticks with their defuses, then keep the ones within
+    defusing = pd.DataFrame(
+        {
+            "row": np.arange(ticks_df.shape[0]),
+            "tick": ticks_df["tick"].to_numpy(),
+            "steamid": ticks_df["steamid"].astype(str).to_numpy(),
+        }
+    ).merge(defuses, on="steamid")
+    defusing = defusing[
+        defusing["tick"].between(defusing["start_tick"], defusing["end_tick"])
+    ]
+
+    elapsed_ticks = defusing["tick"] - defusing["start_tick"]
+    required_ticks = defusing["required_seconds"] * tick_rate
+    defuse_progress = np.full(ticks_df.shape[0], np.nan)
+    defuse_progress[defusing["row"].to_numpy()] = (
+        (elapsed_ticks / required_ticks).clip(upper=1).to_numpy(dtype="float64")
+    )
+    ticks_df["defuse_progress"] = defuse_progress
+    return ticks_df
+
+
 def parse_smokes(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
     """Parse the smokes of the demofile.
 
@@ -410,7 +992,7 @@ def parse_smokes(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "Z": start_row["z"],
         }
         matched_rows.append(combined_row)
-    return pd.DataFrame(matched_rows)
+    return pd.DataFrame(matched_rows, columns=EFFECT_COLUMNS)
 
 
 def parse_infernos(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
@@ -464,7 +1046,63 @@ def parse_infernos(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "Z": start_row["z"],
         }
         matched_rows.append(combined_row)
-    return pd.DataFrame(matched_rows)
+    infernos_df = pd.DataFrame(matched_rows, columns=EFFECT_COLUMNS)
+    return label_inferno_grenades(infernos_df, events.get("weapon_fire"))
+
+
+def label_inferno_grenades(
+    infernos_df: pd.DataFrame, weapon_fires_df: Optional[pd.DataFrame]
+) -> pd.DataFrame:
+    """Tell molotovs and incendiary grenades apart.
+
+    In CS2, both grenades are the same projectile and start the same inferno,
+    so each inferno takes the type of the last one its thrower threw.
+
+    Args:
+        infernos_df (pd.DataFrame): The parsed infernos.
+        weapon_fires_df (pd.DataFrame, optional): `weapon_fire` events.
+
+    Returns:
+        pd.DataFrame: `infernos_df` with a `grenade_type` column, either
+            "molotov" or "incgrenade", or missing if the throw wasn't found.
+    """
+    infernos_df = infernos_df.assign(grenade_type=None)
+    if (
+        infernos_df.shape[0] == 0
+        or weapon_fires_df is None
+        or weapon_fires_df.shape[0] == 0
+    ):
+        return infernos_df
+
+    throws_df = weapon_fires_df.assign(
+        grenade_type=weapon_fires_df["weapon"].str.removeprefix("weapon_")
+    )
+    throws_df = throws_df[throws_df["grenade_type"].isin(INFERNO_GRENADES)]
+    throws_df = pd.DataFrame(
+        {
+            "tick": throws_df["tick"].astype(int),
+            "thrower_steamid": throws_df["user_steamid"].astype(str),
+            "grenade_type": throws_df["grenade_type"],
+        }
+    ).sort_values("tick")
+    infernos_df = infernos_df.drop(columns=["grenade_type"]).assign(
+        start_tick=infernos_df["start_tick"].astype(int),
+        thrower_steamid=infernos_df["thrower_steamid"].astype(str),
+    )
+    return (
+        pd.merge_asof(
+            infernos_df.reset_index().sort_values("start_tick"),
+            throws_df,
+            left_on="start_tick",
+            right_on="tick",
+            by="thrower_steamid",
+            direction="backward",
+        )
+        .drop(columns=["tick"])
+        .sort_values("index")
+        .set_index("index")
+        .rename_axis(None)
+    )
 
 
 def parse_weapon_fires(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
@@ -485,6 +1123,13 @@ def parse_weapon_fires(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
         raise KeyError(weapon_fire_missing_msg)
 
     weapon_fires_df = parse_col_types(remove_nonplay_ticks(weapon_fires_df))
+
+    # Inaccuracy props at the time of the shot, which older demos may not have
+    inaccuracy_cols = [
+        col
+        for col in ["user_recoil_index", "user_velo_modifier"]
+        if col in weapon_fires_df.columns
+    ]
     weapon_fires_df = weapon_fires_df[
         [
             "tick",
@@ -504,9 +1149,10 @@ def parse_weapon_fires(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
             "user_armor_value",
             "user_zoom_lvl",
             "user_inventory",
+            *inaccuracy_cols,
             "weapon",
         ]
-    ]
+    ].rename(columns={"user_velo_modifier": "user_velocity_modifier"})
 
     # Rename columns
     for col in weapon_fires_df.columns:
@@ -515,3 +1161,927 @@ def parse_weapon_fires(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
                 columns={col: col.replace("user_", "player_")}
             )
     return weapon_fires_df
+
+
+def parse_utility_timings(
+    weapon_fires_df: pd.DataFrame,
+    tick_rate: int = 64,
+    game: str = "cs2",
+    early_seconds: float = EARLY_UTILITY_SECONDS,
+    late_seconds: float = LATE_UTILITY_SECONDS,
+) -> pd.DataFrame:
+    """Summarize when each side threw its utility in each round.
+
+    Throws are `early` in the first `early_seconds` after freeze time (or
+    during it), `late` when at most `late_seconds` are left on the clock, which
+    is the bomb timer after a plant, and `mid` otherwise.
+
+    Args:
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires, with round
+            and time columns.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        game (str, optional): The game version to price utility with. Defaults
+            to "cs2".
+        early_seconds (float, optional): Seconds after freeze time that are
+            early. Defaults to EARLY_UTILITY_SECONDS.
+        late_seconds (float, optional): Seconds left on the clock that are
+            late. Defaults to LATE_UTILITY_SECONDS.
+
+    Returns:
+        pd.DataFrame: The `n_utility_{timing}` and `utility_value_{timing}` of
+            each side in each round.
+    """
+    timing_columns = [
+        f"{stat}_{timing}"
+        for timing in UTILITY_TIMINGS
+        for stat in ["n_utility", "utility_value"]
+    ]
+    throws_df = weapon_fires_df[
+        (map_weapon_class(weapon_fires_df["weapon"]) == "grenade")
+        & (weapon_fires_df["round"] > 0)
+    ].copy()
+    if throws_df.shape[0] == 0:
+        return pd.DataFrame(columns=["round", "team_name", *timing_columns])
+
+    prices = get_prices(game)
+    throws_df["utility_value"] = (
+        throws_df["weapon"].str.removeprefix("weapon_").map(prices).fillna(0)
+    )
+    seconds_since_freeze_end = (
+        throws_df["ticks_since_freeze_time_end"].astype("float64") / tick_rate
+    )
+    throws_df["timing"] = np.select(
+        [
+            (
+                (throws_df["time_remaining"] <= late_seconds)
+                & seconds_since_freeze_end.notna()
+            ).to_numpy(dtype=bool),
+            (seconds_since_freeze_end >= early_seconds).to_numpy(dtype=bool),
+        ],
+        ["late", "mid"],
+        "early",
+    )
+
+    timings_df = throws_df.pivot_table(
+        index=["round", "player_team_name"],
+        columns="timing",
+        values="utility_value",
+        aggfunc=["count", "sum"],
+        fill_value=0,
+    )
+    timings_df.columns = [
+        f"{'n_utility' if stat == 'count' else 'utility_value'}_{timing}"
+        for stat, timing in timings_df.columns
+    ]
+    timings_df = (
+        timings_df.reindex(columns=timing_columns, fill_value=0)
+        .astype(int)
+        .reset_index()
+        .rename(columns={"player_team_name": "team_name"})
+    )
+    return timings_df[["round", "team_name", *timing_columns]]
+
+
+def parse_bursts(
+    weapon_fires_df: pd.DataFrame, tick_rate: int = 64, max_gap_cycles: float = 1.5
+) -> pd.DataFrame:
+    """Group each player's consecutive shots with a gun into bursts.
+
+    A shot continues a burst if it comes within `max_gap_cycles` of the gun's
+    cycle time after the previous shot with the same gun. The view angle drift
+    is how far the view moved from the first to the last shot, so sprays that
+    are pulled down for recoil have a negative `pitch_drift`.
+
+    Args:
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        max_gap_cycles (float, optional): Longest gap between shots of a
+            burst, in cycle times of the gun. Defaults to 1.5.
+
+    Returns:
+        pd.DataFrame: The bursts, with their first and last tick, number of
+            shots and view angle drift.
+    """
+    burst_columns = [
+        "round",
+        "player_name",
+        "player_steamid",
+        "weapon",
+        "start_tick",
+        "end_tick",
+        "n_shots",
+        "yaw_drift",
+        "pitch_drift",
+    ]
+    shots_df = weapon_fires_df.assign(
+        cycle_time=weapon_fires_df["weapon"]
+        .str.removeprefix("weapon_")
+        .map(WEAPON_CYCLE_TIMES)
+    )
+    shots_df = shots_df[shots_df["cycle_time"].notna()].sort_values(
+        ["player_steamid", "tick"], kind="stable"
+    )
+    if shots_df.shape[0] == 0:
+        return pd.DataFrame(columns=burst_columns)
+
+    # Start a new burst on a new player or gun, or after a long gap
+    previous_shot = shots_df.groupby("player_steamid")[["tick", "weapon"]].shift(1)
+    max_gap_ticks = shots_df["cycle_time"] * tick_rate * max_gap_cycles
+    shots_df["burst_id"] = (
+        previous_shot["tick"].isna()
+        | (previous_shot["weapon"] != shots_df["weapon"])
+        | (shots_df["tick"] - previous_shot["tick"] > max_gap_ticks)
+    ).cumsum()
+
+    bursts_df = (
+        shots_df.groupby("burst_id")
+        .agg(
+            round=("round", "first"),
+            player_name=("player_name", "first"),
+            player_steamid=("player_steamid", "first"),
+            weapon=("weapon", "first"),
+            start_tick=("tick", "min"),
+            end_tick=("tick", "max"),
+            n_shots=("tick", "size"),
+            first_yaw=("player_yaw", "first"),
+            last_yaw=("player_yaw", "last"),
+            first_pitch=("player_pitch", "first"),
+            last_pitch=("player_pitch", "last"),
+        )
+        .reset_index(drop=True)
+    )
+
+    # Yaw wraps around at 180 degrees, and pitch is positive looking down
+    bursts_df["yaw_drift"] = (
+        bursts_df["last_yaw"] - bursts_df["first_yaw"] + 180
+    ) % 360 - 180
+    bursts_df["pitch_drift"] = bursts_df["first_pitch"] - bursts_df["last_pitch"]
+    return bursts_df[burst_columns].sort_values("start_tick").reset_index(drop=True)
+
+
+def parse_blinds(events: dict[str, pd.DataFrame], tick_rate: int = 64) -> pd.DataFrame:
+    """Parse the blinded intervals of the demofile.
+
+    Overlapping flashes on the same player are merged into one interval, so a
+    player who is re-flashed while still blind is not counted twice.
+
+    Args:
+        events: A dictionary of parsed events.
+        tick_rate: The tick rate of the server. Defaults to 64.
+
+    Returns:
+        The blinded intervals for the demofile.
+    """
+    blind_columns = [
+        "start_tick",
+        "end_tick",
+        "duration",
+        "max_blind_duration",
+        "n_flashes",
+        "player_name",
+        "player_steamid",
+        "player_team_name",
+        "flasher_name",
+        "flasher_steamid",
+        "flasher_team_name",
+    ]
+
+    blind_df = events.get("player_blind")
+    if blind_df is None:
+        logger.warning("player_blind not found in events.")
+        return pd.DataFrame(columns=blind_columns)
+
+    blind_df = parse_col_types(remove_nonplay_ticks(blind_df))
+    blind_df["end_tick"] = blind_df["tick"] + (
+        blind_df["blind_duration"] * tick_rate
+    ).round().astype(int)
+    blind_df = blind_df.sort_values(["user_steamid", "tick"])
+
+    # Merge the overlapping flashes of each player
+    blinded_intervals = []
+    for _, player_blinds in blind_df.groupby("user_steamid"):
+        current_interval = None
+        for _, row in player_blinds.iterrows():
+            if current_interval is not None and (
+                row["tick"] <= current_interval["end_tick"]
+            ):
+                current_interval["end_tick"] = max(
+                    current_interval["end_tick"], row["end_tick"]
+                )
+                current_interval["n_flashes"] += 1
+                # The flasher is the player who threw the strongest flash
+                if row["blind_duration"] > current_interval["max_blind_duration"]:
+                    current_interval["max_blind_duration"] = row["blind_duration"]
+                    current_interval["flasher_name"] = row["attacker_name"]
+                    current_interval["flasher_steamid"] = row["attacker_steamid"]
+                    current_interval["flasher_team_name"] = row[
+                        "attacker_team_name"
+                    ]
+                continue
+
+            if current_interval is not None:
+                blinded_intervals.append(current_interval)
+            current_interval = {
+                "start_tick": row["tick"],
+                "end_tick": row["end_tick"],
+                "max_blind_duration": row["blind_duration"],
+                "n_flashes": 1,
+                "player_name": row["user_name"],
+                "player_steamid": row["user_steamid"],
+                "player_team_name": row["user_team_name"],
+                "flasher_name": row["attacker_name"],
+                "flasher_steamid": row["attacker_steamid"],
+                "flasher_team_name": row["attacker_team_name"],
+            }
+        if current_interval is not None:
+            blinded_intervals.append(current_interval)
+
+    blinds_df = pd.DataFrame(blinded_intervals, columns=blind_columns)
+    blinds_df["duration"] = (
+        blinds_df["end_tick"] - blinds_df["start_tick"]
+    ) / tick_rate
+    return blinds_df.sort_values("start_tick").reset_index(drop=True)
+
+
+def _get_detonations(
+    events: dict[str, pd.DataFrame], event_name: str, grenade_type: str
+) -> pd.DataFrame:
+    """Get the grenades that went off from a detonation event.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        event_name (str): The detonation event, e.g., `flashbang_detonate`.
+        grenade_type (str): The type of grenade of the event.
+
+    Returns:
+        pd.DataFrame: The detonations, in the columns of `GRENADE_EFFECT_KEYS`.
+    """
+    detonations = events.get(event_name)
+    if detonations is None or detonations.shape[0] == 0:
+        return pd.DataFrame(columns=GRENADE_EFFECT_KEYS)
+
+    detonations = parse_col_types(detonations.copy())
+    return pd.DataFrame(
+        {
+            "grenade_type": grenade_type,
+            "entity_id": detonations["entityid"],
+            "tick": detonations["tick"],
+            "thrower_name": detonations["user_name"],
+            "thrower_steamid": detonations["user_steamid"],
+            "thrower_team_name": detonations.get("user_team_name"),
+        }
+    )
+
+
+def parse_grenade_effects(
+    events: dict[str, pd.DataFrame],
+    damages_df: pd.DataFrame,
+    infernos_df: pd.DataFrame,
+) -> pd.DataFrame:
+    """Summarize which players each flashbang, HE grenade and inferno affected.
+
+    Flashes blind players on the tick they go off, and HE grenades damage them
+    on that tick, so their effects are matched to the thrower's detonation at
+    that tick. Fire damage goes to the thrower's latest inferno that was still
+    burning. Players who hurt or blind themselves are not counted.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        damages_df (pd.DataFrame): The parsed damages.
+        infernos_df (pd.DataFrame): The parsed infernos.
+
+    Returns:
+        pd.DataFrame: One row per grenade, with the Steam IDs of the enemies
+            and teammates it blinded or damaged, their counts, and the damage
+            it dealt to each side.
+    """
+    effect_columns = [
+        *GRENADE_EFFECT_KEYS,
+        "enemies_blinded",
+        "teammates_blinded",
+        "enemies_damaged",
+        "teammates_damaged",
+        "n_enemies_blinded",
+        "n_teammates_blinded",
+        "n_enemies_damaged",
+        "n_teammates_damaged",
+        "enemy_damage",
+        "team_damage",
+    ]
+    infernos = pd.DataFrame(
+        {
+            "grenade_type": infernos_df.get("grenade_type", "inferno"),
+            "entity_id": infernos_df["entity_id"],
+            "tick": infernos_df["start_tick"],
+            "thrower_name": infernos_df["thrower_name"],
+            "thrower_steamid": infernos_df["thrower_steamid"].astype(str),
+            "thrower_team_name": infernos_df["thrower_team_name"],
+            "end_tick": pd.to_numeric(infernos_df["end_tick"]),
+        }
+    )
+    infernos["grenade_type"] = infernos["grenade_type"].fillna("inferno")
+    flashes = _get_detonations(events, "flashbang_detonate", "flashbang")
+    he_grenades = _get_detonations(events, "hegrenade_detonate", "hegrenade")
+    grenades_df = pd.concat([flashes, he_grenades, infernos], ignore_index=True)
+    if grenades_df.shape[0] == 0:
+        return pd.DataFrame(columns=effect_columns)
+    grenades_df["tick"] = grenades_df["tick"].astype(int)
+    grenades_df["grenade_index"] = grenades_df.index
+    grenade_cols = ["grenade_index", "tick", "thrower_steamid"]
+
+    # Each effect is a player blinded or damaged by a grenade
+    effects = []
+    blinds = events.get("player_blind")
+    if blinds is not None and blinds.shape[0] > 0:
+        effects.append(
+            parse_col_types(blinds.copy())
+            .assign(effect="blinded", damage=0)
+            .merge(
+                grenades_df.loc[
+                    grenades_df["grenade_type"] == "flashbang", grenade_cols
+                ],
+                left_on=["attacker_steamid", "tick"],
+                right_on=["thrower_steamid", "tick"],
+            )
+        )
+    damages = damages_df.rename(
+        columns={
+            "victim_steamid": "user_steamid",
+            "victim_team_name": "user_team_name",
+            "dmg_health_real": "damage",
+        }
+    ).assign(effect="damaged")
+    effects.append(
+        damages[damages["weapon"] == "hegrenade"].merge(
+            grenades_df.loc[grenades_df["grenade_type"] == "hegrenade", grenade_cols],
+            left_on=["attacker_steamid", "tick"],
+            right_on=["thrower_steamid", "tick"],
+        )
+    )
+    fire_damages = damages[damages["weapon"] == "inferno"]
+    is_inferno = ~grenades_df["grenade_type"].isin(["flashbang", "hegrenade"])
+    if is_inferno.any() and fire_damages.shape[0] > 0:
+        fire_damages = pd.merge_asof(
+            fire_damages.assign(
+                tick=fire_damages["tick"].astype(int),
+                thrower_steamid=fire_damages["attacker_steamid"].astype(str),
+            ).sort_values("tick"),
+            grenades_df.loc[is_inferno, [*grenade_cols, "end_tick"]]
+            .rename(columns={"tick": "start_tick"})
+            .sort_values("start_tick"),
+            left_on="tick",
+            right_on="start_tick",
+            by="thrower_steamid",
+            direction="backward",
+        )
+        is_burning = fire_damages["grenade_index"].notna() & ~(
+            fire_damages["tick"] > fire_damages["end_tick"]
+        )
+        effects.append(fire_damages[is_burning].astype({"grenade_index": int}))
+    effects_df = pd.concat(effects, ignore_index=True)
+    is_self = effects_df["user_steamid"] == effects_df["attacker_steamid"]
+    is_teammate = effects_df["user_team_name"] == effects_df["attacker_team_name"]
+    effects_df = effects_df.assign(
+        side=np.where(is_teammate, "teammates", "enemies")
+    )[~is_self]
+
+    # Collect the affected players and the damage of each grenade, by side
+    for side, damage_col in [
+        ("enemies", "enemy_damage"),
+        ("teammates", "team_damage"),
+    ]:
+        side_effects = effects_df[effects_df["side"] == side]
+        for effect in ["blinded", "damaged"]:
+            affected = (
+                side_effects[side_effects["effect"] == effect]
+                .groupby("grenade_index")["user_steamid"]
+                .apply(lambda steamids: sorted(set(steamids)))
+            )
+            grenades_df[f"{side}_{effect}"] = [
+                affected.get(grenade_index, [])
+                for grenade_index in grenades_df["grenade_index"]
+            ]
+            grenades_df[f"n_{side}_{effect}"] = grenades_df[
+                f"{side}_{effect}"
+            ].str.len()
+        grenades_df[damage_col] = (
+            grenades_df["grenade_index"]
+            .map(side_effects.groupby("grenade_index")["damage"].sum())
+            .fillna(0)
+        )
+    return grenades_df[effect_columns].sort_values("tick").reset_index(drop=True)
+
+
+def parse_scopes(parser: DemoParser) -> pd.DataFrame:
+    """Parse the scope in and scope out events of the demofile.
+
+    Args:
+        parser: The parser object.
+
+    Returns:
+        The scope events for the demofile.
+    """
+    scope_df = parser.parse_event(
+        "weapon_zoom",
+        player=["team_name", "zoom_lvl", "active_weapon_name"],
+        other=[
+            "is_freeze_period",
+            "is_warmup_period",
+            "is_terrorist_timeout",
+            "is_ct_timeout",
+            "is_technical_timeout",
+            "is_waiting_for_resume",
+            "is_match_started",
+            "game_phase",
+        ],
+    )
+    if scope_df.shape[0] == 0:
+        return pd.DataFrame(
+            columns=[
+                "tick",
+                "event",
+                "player_name",
+                "player_steamid",
+                "player_team_name",
+                "weapon",
+                "zoom_lvl",
+            ]
+        )
+
+    scope_df = parse_col_types(remove_nonplay_ticks(scope_df))
+    scope_df["event"] = np.where(scope_df["user_zoom_lvl"] > 0, "scope_in", "scope_out")
+    scope_df = scope_df.rename(
+        columns={
+            "user_name": "player_name",
+            "user_steamid": "player_steamid",
+            "user_team_name": "player_team_name",
+            "user_active_weapon_name": "weapon",
+            "user_zoom_lvl": "zoom_lvl",
+        }
+    )
+    return scope_df[
+        [
+            "tick",
+            "event",
+            "player_name",
+            "player_steamid",
+            "player_team_name",
+            "weapon",
+            "zoom_lvl",
+        ]
+    ].reset_index(drop=True)
+
+
+def _find_dropped_gun(inventory: list) -> Optional[str]:
+    """Find the gun a player drops on death, their primary or else their pistol."""
+    if not isinstance(inventory, (list, tuple, np.ndarray)):
+        return None
+    weapons = [EQUIPMENT_NAMES.get(item) for item in inventory]
+    classes = map_weapon_class(pd.Series(weapons, dtype="object")).tolist()
+    for gun_classes in [("smg", "heavy", "rifle"), ("pistol",)]:
+        for weapon, weapon_class in zip(weapons, classes):
+            if weapon_class in gun_classes:
+                return weapon
+    return None
+
+
+def build_dropped_weapons(
+    kills_df: pd.DataFrame,
+    inventories_df: pd.DataFrame,
+    pickups_df: Optional[pd.DataFrame],
+    rounds_df: pd.DataFrame,
+) -> pd.DataFrame:
+    """Track the guns dropped by dead players until someone picks them up.
+
+    Each victim drops their primary, or their pistol if they have none, where
+    they died. The gun stays on the ground until the first pickup of the same
+    weapon in the round, or until the round ends. Guns dropped by hand are not
+    tracked, since demos have no event for them.
+
+    Args:
+        kills_df (pd.DataFrame): The parsed kills, with round numbers.
+        inventories_df (pd.DataFrame): The `inventory` of every player on the
+            tick before each kill.
+        pickups_df (pd.DataFrame, optional): The `item_pickup` events, with
+            round numbers.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The dropped guns, with where and when they were dropped,
+            who dropped them and who picked them up. Guns are on the ground
+            from `drop_tick` until `remove_tick`, the pickup or round end.
+    """
+    drop_columns = [
+        "round",
+        "drop_tick",
+        "weapon",
+        "X",
+        "Y",
+        "Z",
+        "previous_owner_name",
+        "previous_owner_steamid",
+        "pickup_tick",
+        "picked_up_by_name",
+        "picked_up_by_steamid",
+        "remove_tick",
+    ]
+    inventories_df = parse_col_types(inventories_df.copy())
+    inventories_df["tick"] += 1
+    drops_df = kills_df.merge(
+        inventories_df[["tick", "steamid", "inventory"]].rename(
+            columns={"steamid": "victim_steamid"}
+        ),
+        on=["tick", "victim_steamid"],
+    )
+    drops_df["weapon"] = drops_df["inventory"].map(_find_dropped_gun)
+    drops_df = (
+        drops_df[drops_df["weapon"].notna()]
+        .rename(
+            columns={
+                "tick": "drop_tick",
+                "victim_X": "X",
+                "victim_Y": "Y",
+                "victim_Z": "Z",
+                "victim_name": "previous_owner_name",
+                "victim_steamid": "previous_owner_steamid",
+            }
+        )
+        .sort_values("drop_tick")
+        .reset_index(drop=True)
+    )
+    drops_df["pickup_tick"] = pd.Series(pd.NA, index=drops_df.index, dtype="Int64")
+    drops_df["picked_up_by_name"] = None
+    drops_df["picked_up_by_steamid"] = None
+    if drops_df.shape[0] == 0:
+        return pd.DataFrame(columns=drop_columns)
+
+    # Match each pickup to the earliest gun of that kind still on the ground
+    if pickups_df is not None and {"item", "user_steamid"}.issubset(
+        pickups_df.columns
+    ):
+        pickups_df = parse_col_types(pickups_df.copy()).sort_values("tick")
+        for pickup in pickups_df.itertuples():
+            on_ground = drops_df[
+                (drops_df["round"] == pickup.round)
+                & (drops_df["weapon"] == str(pickup.item).removeprefix("weapon_"))
+                & (drops_df["drop_tick"] <= pickup.tick)
+                & drops_df["pickup_tick"].isna()
+            ]
+            if on_ground.shape[0] > 0:
+                drop_idx = on_ground.index[0]
+                drops_df.loc[drop_idx, "pickup_tick"] = pickup.tick
+                drops_df.loc[drop_idx, "picked_up_by_name"] = getattr(
+                    pickup, "user_name", None
+                )
+                drops_df.loc[drop_idx, "picked_up_by_steamid"] = pickup.user_steamid
+
+    round_ends = rounds_df.set_index("round")["official_end"]
+    drops_df["remove_tick"] = (
+        drops_df["pickup_tick"]
+        .fillna(drops_df["round"].map(round_ends).astype("Int64"))
+        .astype("Int64")
+    )
+    return drops_df[drop_columns]
+
+
+def parse_dropped_weapons(
+    kill_ticks_df: pd.DataFrame,
+    kills_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    rounds_df: pd.DataFrame,
+) -> pd.DataFrame:
+    """Parse the guns dropped by dead players. See `build_dropped_weapons`.
+
+    Args:
+        kill_ticks_df (pd.DataFrame): The players around the kills, with their
+            inventories. See `awpy.parsers.ticks.parse_kill_ticks`.
+        kills_df (pd.DataFrame): The parsed kills, with round numbers.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The dropped guns.
+    """
+    pickups_df = events.get("item_pickup")
+    if pickups_df is not None:
+        pickups_df = apply_round_num(rounds_df, pickups_df.copy())
+    return build_dropped_weapons(
+        kills_df, kill_ticks_df[["tick", "steamid", "inventory"]], pickups_df, rounds_df
+    )
+
+
+def build_bomb_states(
+    events: dict[str, pd.DataFrame], rounds_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Build a timeline of the bomb's state from the bomb events.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The `tick`, `round` and `bomb_state` (carried, dropped,
+            planted, defused or exploded) of each bomb event, with the name,
+            Steam ID and position of the player who picked up, dropped or
+            planted the bomb.
+    """
+    state_columns = [
+        "tick",
+        "round",
+        "bomb_state",
+        "player_name",
+        "player_steamid",
+        "X",
+        "Y",
+        "Z",
+    ]
+    state_events = [
+        pd.DataFrame(
+            {
+                "tick": events[event_name]["tick"],
+                "bomb_state": bomb_state,
+                "player_name": events[event_name].get("user_name"),
+                "player_steamid": events[event_name].get("user_steamid"),
+                "X": events[event_name].get("user_X"),
+                "Y": events[event_name].get("user_Y"),
+                "Z": events[event_name].get("user_Z"),
+            }
+        )
+        for event_name, bomb_state in BOMB_STATE_EVENTS.items()
+        if event_name in events and "tick" in events[event_name].columns
+    ]
+    if len(state_events) == 0:
+        return pd.DataFrame(columns=state_columns).astype(
+            {"tick": "int64", "round": "int64"}
+        )
+
+    states_df = parse_col_types(
+        apply_round_num(rounds_df, pd.concat(state_events, ignore_index=True))
+    )
+    return (
+        states_df.astype({"tick": "int64", "round": "int64"})
+        .sort_values("tick", kind="stable")[state_columns]
+        .reset_index(drop=True)
+    )
+
+
+def parse_bomb_state(
+    ticks_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    rounds_df: pd.DataFrame,
+) -> pd.DataFrame:
+    """Add who carries the bomb and what state it is in to every tick.
+
+    The state is from the latest bomb event of the round, e.g., `bomb_dropped`,
+    and the carrier is whoever has the C4 in their inventory, so the bomb is
+    `carried` before anyone drops it.
+
+    Args:
+        ticks_df (pd.DataFrame): The parsed ticks, with round numbers and
+            inventories.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: `ticks_df` with `bomb_carrier_steamid`, `bomb_state`
+            (carried, dropped, planted, defused or exploded) and
+            `is_bomb_ticking`.
+    """
+    states_df = build_bomb_states(events, rounds_df)[["tick", "round", "bomb_state"]]
+
+    bomb_df = pd.DataFrame({"tick": ticks_df["tick"].unique()}).astype("int64")
+    bomb_df = apply_round_num(rounds_df, bomb_df).sort_values("tick")
+    bomb_df = pd.merge_asof(
+        bomb_df,
+        states_df,
+        on="tick",
+        by="round",
+        direction="backward",
+    )
+
+    # The bomb spawns in a Terrorist's inventory, before any bomb event
+    c4_name = EQUIPMENT_DATA["c4"]["name"]
+    inventory_items = ticks_df["inventory"].reset_index(drop=True).explode()
+    has_c4 = np.zeros(ticks_df.shape[0], dtype=bool)
+    has_c4[inventory_items.index[inventory_items == c4_name]] = True
+    carriers = (
+        ticks_df.loc[has_c4, ["tick", "steamid"]]
+        .drop_duplicates("tick")
+        .rename(columns={"steamid": "bomb_carrier_steamid"})
+    )
+    bomb_df = bomb_df.merge(carriers, on="tick", how="left")
+    is_on_player = bomb_df["bomb_state"].isna() | (
+        bomb_df["bomb_state"] == "carried"
+    )
+    bomb_df["bomb_state"] = np.where(
+        bomb_df["bomb_carrier_steamid"].notna() & is_on_player,
+        "carried",
+        bomb_df["bomb_state"],
+    )
+    bomb_df["bomb_carrier_steamid"] = bomb_df["bomb_carrier_steamid"].where(
+        bomb_df["bomb_state"] == "carried", None
+    )
+    bomb_df["is_bomb_ticking"] = bomb_df["bomb_state"] == "planted"
+
+    return ticks_df.merge(
+        bomb_df[["tick", "bomb_carrier_steamid", "bomb_state", "is_bomb_ticking"]],
+        on="tick",
+        how="left",
+    )
+
+
+def build_bomb_drops(
+    bomb_states_df: pd.DataFrame, rounds_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Find how long the bomb lay on the ground after each drop.
+
+    A drop ends when the bomb is picked up or planted, or when the round ends.
+    Drops during freeze time, e.g., to pass the bomb in spawn, count from the
+    end of freeze time.
+
+    Args:
+        bomb_states_df (pd.DataFrame): The bomb's states, from
+            `build_bomb_states`.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The `round`, `start_tick`, `end_tick` and `end_reason`
+            (pickup, plant or round_end) of each drop, with the dropper's
+            name, Steam ID and position, which is about where the bomb lies.
+    """
+    drop_columns = [
+        "round",
+        "start_tick",
+        "end_tick",
+        "end_reason",
+        "dropper_name",
+        "dropper_steamid",
+        "X",
+        "Y",
+        "Z",
+    ]
+    states_df = bomb_states_df[bomb_states_df["round"] > 0]
+    drops_df = states_df[states_df["bomb_state"] == "dropped"].rename(
+        columns={"player_name": "dropper_name", "player_steamid": "dropper_steamid"}
+    )
+    if drops_df.shape[0] == 0 or rounds_df.shape[0] == 0:
+        return pd.DataFrame(columns=drop_columns)
+
+    # A drop ends when the bomb is next picked up or planted
+    end_reasons = {"carried": "pickup", "planted": "plant"}
+    ends_df = states_df[states_df["bomb_state"].isin(end_reasons)]
+    ends_df = pd.DataFrame(
+        {
+            "end_tick": ends_df["tick"],
+            "round": ends_df["round"],
+            "end_reason": ends_df["bomb_state"].map(end_reasons),
+        }
+    )
+    drops_df = pd.merge_asof(
+        drops_df,
+        ends_df,
+        left_on="tick",
+        right_on="end_tick",
+        by="round",
+        direction="forward",
+    )
+
+    rounds = rounds_df.set_index("round")
+    round_ends = drops_df["round"].map(rounds["end"])
+    drops_df["end_reason"] = drops_df["end_reason"].fillna("round_end")
+    drops_df["end_tick"] = drops_df["end_tick"].fillna(round_ends)
+    drops_df["start_tick"] = np.maximum(
+        drops_df["tick"], drops_df["round"].map(rounds["freeze_end"]).fillna(0)
+    )
+    drops_df = drops_df[drops_df["end_tick"] > drops_df["start_tick"]].astype(
+        {"start_tick": "int64", "end_tick": "int64"}
+    )
+    return drops_df[drop_columns].reset_index(drop=True)
+
+
+def find_bomb_warnings(
+    drops_df: pd.DataFrame,
+    players_df: pd.DataFrame,
+    tick_rate: int = 64,
+    unattended_seconds: float = BOMB_UNATTENDED_SECONDS,
+    left_behind_distance: float = BOMB_LEFT_BEHIND_DISTANCE,
+    left_behind_seconds: float = BOMB_LEFT_BEHIND_SECONDS,
+    sample_seconds: float = BOMB_WARNING_SAMPLE_SECONDS,
+) -> pd.DataFrame:
+    """Flag drops where the bomb was neglected, e.g., to find throwaway rounds.
+
+    A drop is `unattended` if the bomb stays on the ground for at least
+    `unattended_seconds`. It is also `left_behind` if, for at least
+    `left_behind_seconds` of samples, every alive Terrorist is farther than
+    `left_behind_distance` from it, e.g., when the team pushes out of spawn
+    without it.
+
+    Args:
+        drops_df (pd.DataFrame): The drops, from `build_bomb_drops`.
+        players_df (pd.DataFrame): The `tick`, `team_name`, `health`, `X`, `Y`
+            and `Z` of players, sampled every `sample_seconds` during drops.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        unattended_seconds (float, optional): Least seconds on the ground to be
+            unattended. Defaults to BOMB_UNATTENDED_SECONDS.
+        left_behind_distance (float, optional): Least distance of every alive
+            Terrorist to be left behind. Defaults to BOMB_LEFT_BEHIND_DISTANCE.
+        left_behind_seconds (float, optional): Least seconds away to be left
+            behind. Defaults to BOMB_LEFT_BEHIND_SECONDS.
+        sample_seconds (float, optional): Seconds between samples in
+            `players_df`. Defaults to BOMB_WARNING_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: One row per warning. `duration_seconds` is how long the
+            bomb was on the ground for `unattended` warnings, and how long every
+            Terrorist was away from it for `left_behind` warnings.
+    """
+    warnings = []
+    terrorists = players_df[
+        (players_df["team_name"] == "TERRORIST") & (players_df["health"] > 0)
+    ]
+    for drop in drops_df.itertuples():
+        on_ground_seconds = (drop.end_tick - drop.start_tick) / tick_rate
+        drop_warning = {
+            "round": drop.round,
+            "start_tick": drop.start_tick,
+            "end_tick": drop.end_tick,
+            "end_reason": drop.end_reason,
+            "dropper_name": drop.dropper_name,
+            "dropper_steamid": drop.dropper_steamid,
+            "X": drop.X,
+            "Y": drop.Y,
+            "Z": drop.Z,
+        }
+        if on_ground_seconds >= unattended_seconds:
+            warnings.append(
+                {
+                    **drop_warning,
+                    "warning": "unattended",
+                    "duration_seconds": on_ground_seconds,
+                }
+            )
+
+        samples = terrorists[terrorists["tick"].between(drop.start_tick, drop.end_tick)]
+        if samples.shape[0] == 0:
+            continue
+        distances = np.linalg.norm(
+            samples[["X", "Y", "Z"]].to_numpy(dtype=float)
+            - np.array([drop.X, drop.Y, drop.Z], dtype=float),
+            axis=1,
+        )
+        nearest = pd.Series(distances, index=samples.index)
+        nearest = nearest.groupby(samples["tick"]).min()
+        away_seconds = (nearest > left_behind_distance).sum() * sample_seconds
+        if away_seconds >= left_behind_seconds:
+            warnings.append(
+                {
+                    **drop_warning,
+                    "warning": "left_behind",
+                    "duration_seconds": float(away_seconds),
+                }
+            )
+    return pd.DataFrame(warnings, columns=BOMB_WARNING_COLUMNS)
+
+
+def parse_bomb_warnings(
+    parser: DemoParser,
+    events: dict[str, pd.DataFrame],
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+    sample_seconds: float = BOMB_WARNING_SAMPLE_SECONDS,
+) -> pd.DataFrame:
+    """Parse warnings for dropped bombs that were neglected.
+
+    Args:
+        parser (DemoParser): The parser object.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        sample_seconds (float, optional): Seconds between position samples.
+            Defaults to BOMB_WARNING_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: The warnings. See `find_bomb_warnings`.
+    """
+    drops_df = build_bomb_drops(build_bomb_states(events, rounds_df), rounds_df)
+    sample_step = max(int(tick_rate * sample_seconds), 1)
+    sample_ticks = sorted(
+        {
+            int(tick)
+            for drop in drops_df.itertuples()
+            for tick in np.arange(drop.start_tick, drop.end_tick + 1, sample_step)
+        }
+    )
+    players_df = (
+        parse_col_types(
+            parser.parse_ticks(
+                wanted_props=["team_name", "health", "X", "Y", "Z"],
+                ticks=sample_ticks,
+            )
+        )
+        if len(sample_ticks) > 0
+        else pd.DataFrame(columns=["tick", "team_name", "health", "X", "Y", "Z"])
+    )
+    return find_bomb_warnings(
+        drops_df, players_df, tick_rate, sample_seconds=sample_seconds
+    )
@@ -0,0 +1,251 @@
+"""Module for T side site executes and the utility thrown in them."""
+
+import numpy as np
+import pandas as pd
+
+from awpy.parsers.utils import parse_col_types
+from awpy.utils import apply_round_num
+
+# Seconds from the first to the last utility of an execute
+EXECUTE_WINDOW_SECONDS = 8
+
+# Least number of utility landing on a site to count as an execute
+EXECUTE_MIN_UTILITY = 3
+
+# Farthest utility can land from a bombsite's zone, in units, to be paired to it
+EXECUTE_SITE_DISTANCE = 1000
+
+# Bombsite zones, from `parse_zones`, and the site they are named by
+EXECUTE_SITES = {"bombsite_a": "A", "bombsite_b": "B"}
+
+EXECUTE_COLUMNS = [
+    "round",
+    "site",
+    "start_tick",
+    "end_tick",
+    "n_smokes",
+    "n_flashes",
+    "n_molotovs",
+    "grenade_types",
+    "thrower_steamids",
+    "planted",
+    "won",
+]
+
+
+def _get_utility(
+    smokes_df: pd.DataFrame,
+    infernos_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    rounds_df: pd.DataFrame,
+) -> pd.DataFrame:
+    """Get the T side smokes, flashes and molotovs, where they went off.
+
+    Args:
+        smokes_df (pd.DataFrame): The parsed smokes.
+        infernos_df (pd.DataFrame): The parsed infernos.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The `round`, `tick`, `grenade_type`, `thrower_steamid`,
+            `X` and `Y` of each grenade.
+    """
+    utility_columns = ["round", "tick", "grenade_type", "thrower_steamid", "X", "Y"]
+    utility = [
+        smokes_df.assign(grenade_type="smoke").rename(columns={"start_tick": "tick"}),
+        infernos_df.assign(grenade_type="molotov").rename(
+            columns={"start_tick": "tick"}
+        ),
+    ]
+    flashes = events.get("flashbang_detonate")
+    if flashes is not None and flashes.shape[0] > 0:
+        flashes = parse_col_types(flashes.copy())
+        utility.append(
+            apply_round_num(
+                rounds_df,
+                pd.DataFrame(
+                    {
+                        "tick": flashes["tick"],
+                        "grenade_type": "flash",
+                        "thrower_team_name": flashes.get("user_team_name"),
+                        "thrower_steamid": flashes["user_steamid"],
+                        "X": flashes["x"],
+                        "Y": flashes["y"],
+                    }
+                ),
+            )
+        )
+    utility = [df for df in utility if df.shape[0] > 0]
+    if len(utility) == 0:
+        return pd.DataFrame(columns=utility_columns)
+
+    utility_df = pd.concat(utility, ignore_index=True)
+    utility_df = utility_df[utility_df["thrower_team_name"] == "TERRORIST"]
+    return utility_df.dropna(subset=["round", "tick", "X", "Y"])[
+        utility_columns
+    ].reset_index(drop=True)
+
+
+def _get_sites(
+    x: np.ndarray,
+    y: np.ndarray,
+    zones_df: pd.DataFrame,
+    max_distance: float = EXECUTE_SITE_DISTANCE,
+) -> np.ndarray:
+    """Get the bombsite closest to each point, within `max_distance` of its zone.
+
+    Args:
+        x (np.ndarray): X coordinates of the points.
+        y (np.ndarray): Y coordinates of the points.
+        zones_df (pd.DataFrame): The zone bounds, from `parse_zones`.
+        max_distance (float, optional): Farthest a point can be from a zone.
+            Defaults to EXECUTE_SITE_DISTANCE.
+
+    Returns:
+        np.ndarray: The site of each point, or None if no site is close enough.
+    """
+    sites = np.full(len(x), None, dtype=object)
+    closest = np.full(len(x), np.inf)
+    for zone in zones_df[zones_df["zone"].isin(EXECUTE_SITES)].itertuples():
+        # Distance to the zone's box, which is 0 inside it
+        dx = np.maximum(np.maximum(zone.min_X - x, x - zone.max_X), 0)
+        dy = np.maximum(np.maximum(zone.min_Y - y, y - zone.max_Y), 0)
+        distance = np.hypot(dx, dy)
+        is_closer = (distance < closest) & (distance <= max_distance)
+        sites[is_closer] = EXECUTE_SITES[zone.zone]
+        closest[is_closer] = distance[is_closer]
+    return sites
+
+
+def find_executes(
+    utility_df: pd.DataFrame,
+    zones_df: pd.DataFrame,
+    bomb_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+    window_seconds: float = EXECUTE_WINDOW_SECONDS,
+    min_utility: int = EXECUTE_MIN_UTILITY,
+    max_distance: float = EXECUTE_SITE_DISTANCE,
+) -> pd.DataFrame:
+    """Find the T side executes onto each bombsite, and how they ended.
+
+    An execute is at least `min_utility` grenades that go off near the same
+    bombsite within `window_seconds` of the first. Each grenade is paired to
+    the closest bombsite zone within `max_distance` units. A round can have
+    several executes, e.g., a fake onto one site and the real one onto the
+    other.
+
+    Args:
+        utility_df (pd.DataFrame): The `round`, `tick`, `grenade_type`,
+            `thrower_steamid`, `X` and `Y` of each T side grenade.
+        zones_df (pd.DataFrame): The zone bounds, from `parse_zones`.
+        bomb_df (pd.DataFrame): The parsed bomb events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        window_seconds (float, optional): Seconds from the first to the last
+            grenade of an execute. Defaults to EXECUTE_WINDOW_SECONDS.
+        min_utility (int, optional): Least number of grenades in an execute.
+            Defaults to EXECUTE_MIN_UTILITY.
+        max_distance (float, optional): Farthest a grenade can go off from a
+            bombsite zone. Defaults to EXECUTE_SITE_DISTANCE.
+
+    Returns:
+        pd.DataFrame: One row per execute, with the utility in it, whether the
+            bomb was then planted on that site and whether the Ts won.
+    """
+    utility_df = utility_df.assign(
+        site=_get_sites(
+            utility_df["X"].to_numpy(dtype=float),
+            utility_df["Y"].to_numpy(dtype=float),
+            zones_df,
+            max_distance=max_distance,
+        )
+    ).dropna(subset=["site"])
+
+    window_ticks = window_seconds * tick_rate
+    executes = []
+    for (round_num, site), site_utility in utility_df.sort_values("tick").groupby(
+        ["round", "site"]
+    ):
+        ticks = site_utility["tick"].to_numpy()
+        start = 0
+        while start < len(ticks):
+            end = np.searchsorted(ticks, ticks[start] + window_ticks, side="right")
+            if end - start < min_utility:
+                start += 1
+                continue
+            execute_utility = site_utility.iloc[start:end]
+            grenade_types = execute_utility["grenade_type"]
+            executes.append(
+                {
+                    "round": round_num,
+                    "site": site,
+                    "start_tick": ticks[start],
+                    "end_tick": ticks[end - 1],
+                    "n_smokes": int((grenade_types == "smoke").sum()),
+                    "n_flashes": int((grenade_types == "flash").sum()),
+                    "n_molotovs": int((grenade_types == "molotov").sum()),
+                    "grenade_types": grenade_types.tolist(),
+                    "thrower_steamids": execute_utility["thrower_steamid"]
+                    .astype(str)
+                    .tolist(),
+                }
+            )
+            start = end
+    executes_df = pd.DataFrame(executes, columns=EXECUTE_COLUMNS)
+    if executes_df.shape[0] == 0:
+        return executes_df
+
+    plants = bomb_df[bomb_df["event"] == "planted"]
+    plants = plants.assign(
+        site=_get_sites(
+            plants["user_X"].to_numpy(dtype=float),
+            plants["user_Y"].to_numpy(dtype=float),
+            zones_df,
+            max_distance=max_distance,
+        )
+    )
+    executes_df["planted"] = [
+        (
+            (plants["round"] == execute.round)
+            & (plants["site"] == execute.site)
+            & (plants["tick"] >= execute.start_tick)
+        ).any()
+        for execute in executes_df.itertuples()
+    ]
+    t_wins = rounds_df.loc[rounds_df["winner"].isin(["T", "TERRORIST"]), "round"]
+    executes_df["won"] = executes_df["round"].isin(t_wins)
+    return executes_df
+
+
+def parse_executes(
+    smokes_df: pd.DataFrame,
+    infernos_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    zones_df: pd.DataFrame,
+    bomb_df: pd.DataFrame,
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+) -> pd.DataFrame:
+    """Parse the T side executes onto each bombsite. See `find_executes`.
+
+    Args:
+        smokes_df (pd.DataFrame): The parsed smokes.
+        infernos_df (pd.DataFrame): The parsed infernos.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        zones_df (pd.DataFrame): The zone bounds, from `parse_zones`.
+        bomb_df (pd.DataFrame): The parsed bomb events.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: One row per execute.
+    """
+    return find_executes(
+        _get_utility(smokes_df, infernos_df, events, rounds_df),
+        zones_df,
+        bomb_df,
+        rounds_df,
+        tick_rate=tick_rate,
+    )
@@ -0,0 +1,114 @@
+"""Module for exporting kill highlights to demo camera scripts."""
+
+import pandas as pd
+
+# Spectator commands take the 32-bit account ID, rather than the Steam ID
+STEAMID64_BASE = 76561197960265728
+PRE_KILL_SECONDS = 5
+POST_KILL_SECONDS = 2
+
+
+def parse_kill_cameras(
+    kills_df: pd.DataFrame,
+    tick_rate: int = 64,
+    pre_seconds: float = PRE_KILL_SECONDS,
+    post_seconds: float = POST_KILL_SECONDS,
+) -> pd.DataFrame:
+    """Suggest a spectator camera clip from the attacker's POV for every kill.
+
+    Each clip starts `pre_seconds` before a kill and ends `post_seconds` after
+    it. Kills by the same attacker in the same round with overlapping clips,
+    e.g., multi-kills, are merged into a single clip. World kills and suicides
+    are skipped.
+
+    Args:
+        kills_df (pd.DataFrame): The kills dataframe, with round numbers.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        pre_seconds (float, optional): Seconds to show before a kill. Defaults
+            to PRE_KILL_SECONDS.
+        post_seconds (float, optional): Seconds to show after a kill. Defaults
+            to POST_KILL_SECONDS.
+
+    Returns:
+        pd.DataFrame: The clips, with the attacker to spectate from
+            `start_tick` to `end_tick`.
+    """
+    camera_columns = [
+        "round",
+        "start_tick",
+        "end_tick",
+        "duration_seconds",
+        "attacker_name",
+        "attacker_steamid",
+        "n_kills",
+    ]
+    attacker_steamids = pd.to_numeric(kills_df["attacker_steamid"], errors="coerce")
+    victim_steamids = pd.to_numeric(kills_df["victim_steamid"], errors="coerce")
+    kills = kills_df[
+        (attacker_steamids > 0)
+        & (attacker_steamids != victim_steamids)
+        & (kills_df["round"] > 0)
+    ].sort_values("tick")
+    if kills.shape[0] == 0:
+        return pd.DataFrame(columns=camera_columns)
+
+    start_ticks = (kills["tick"] - pre_seconds * tick_rate).clip(lower=0)
+    kills = kills.assign(
+        start_tick=start_ticks.astype(int),
+        end_tick=(kills["tick"] + post_seconds * tick_rate).astype(int),
+    )
+
+    # A clip continues while the same attacker keeps killing before it ends
+    new_clip = (
+        (kills["attacker_steamid"] != kills["attacker_steamid"].shift())
+        | (kills["round"] != kills["round"].shift())
+        | (kills["start_tick"] > kills["end_tick"].shift())
+    )
+    cameras_df = (
+        kills.groupby(new_clip.cumsum())
+        .agg(
+            round=("round", "first"),
+            start_tick=("start_tick", "min"),
+            end_tick=("end_tick", "max"),
+            attacker_name=("attacker_name", "first"),
+            attacker_steamid=("attacker_steamid", "first"),
+            n_kills=("tick", "size"),
+        )
+        .reset_index(drop=True)
+    )
+    cameras_df["duration_seconds"] = (
+        cameras_df["end_tick"] - cameras_df["start_tick"]
+    ) / tick_rate
+    return cameras_df[camera_columns]
+
+
+def to_hlae_script(cameras_df: pd.DataFrame) -> str:
+    """Write camera clips as an HLAE config that plays them back to back.
+
+    At the start of each clip, the camera switches to the attacker, and at the
+    end it skips to the next clip, or pauses after the last one. Run it with
+    `exec` in a demo with HLAE attached.
+
+    Args:
+        cameras_df (pd.DataFrame): The clips, from `parse_kill_cameras`.
+
+    Returns:
+        str: The HLAE config.
+    """
+    lines = ["mirv_cmd clear"]
+    clips = cameras_df.sort_values("start_tick").to_dict("records")
+    for i, clip in enumerate(clips):
+        account_id = int(clip["attacker_steamid"]) - STEAMID64_BASE
+        lines.append(
+            f'mirv_cmd addAtTick {clip["start_tick"]} '
+            f'"spec_player_by_accountid {account_id}"'
+        )
+        end_command = (
+            f"demo_gototick {clips[i + 1]['start_tick']}"
+            if i + 1 < len(clips)
+            else "demo_pause"
+        )
+        lines.append(f'mirv_cmd addAtTick {clip["end_tick"]} "{end_command}"')
+    if len(clips) > 0:
+        lines.append(f"demo_gototick {clips[0]['start_tick']}")
+    return "\n".join(lines) + "\n"
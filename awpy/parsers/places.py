@@ -0,0 +1,426 @@
+"""Module for place polygons, to name places on maps without place names."""
+
+import json
+from pathlib import Path
+
+import numpy as np
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
+
+from awpy.parsers.utils import parse_col_types
+
+# A polygon needs 3 points to have an area
+MIN_POLYGON_POINTS = 3
+
+# Place columns, named after the X and Y columns with the same prefix
+PLACE_COLUMN_SUFFIXES = ("last_place_name", "place")
+
+# Grid size, in units, that player positions are deduplicated on
+PLACE_POLYGON_CELL_SIZE = 32
+
+# Seconds between the position samples that outline zones
+ZONE_SAMPLE_SECONDS = 1
+
+# Seconds between the position samples that place times are counted from
+PLACE_TIME_SAMPLE_SECONDS = 0.5
+
+PLACE_TIME_COLUMNS = ["round", "steamid", "name", "team_name", "place", "seconds"]
+
+# Values of `which_bomb_zone` for each bombsite
+BOMBSITE_ZONES = {1: "bombsite_a", 2: "bombsite_b"}
+
+# Buy zone of each side
+BUY_ZONES = {"CT": "ct_buyzone", "TERRORIST": "t_buyzone"}
+
+ZONE_COLUMNS = [
+    "zone",
+    "min_X",
+    "max_X",
+    "min_Y",
+    "max_Y",
+    "min_Z",
+    "max_Z",
+    "n_positions",
+]
+
+
+def load_place_polygons(path: Path) -> list[tuple[str, np.ndarray]]:
+    """Load named place polygons from a JSON file.
+
+    The file holds a list of places, each with a `name` and the `points` of
+    its outline on the map, e.g.,
+    `[{"name": "TopofMid", "points": [[0, 0], [500, 0], [500, 300]]}]`. A place
+    can have several polygons by repeating its name.
+
+    Args:
+        path (Path): Path to the JSON file.
+
+    Returns:
+        list[tuple[str, np.ndarray]]: The name and (n, 2) points of each polygon.
+
+    Raises:
+        ValueError: If a polygon doesn't have at least 3 (x, y) points.
+    """
+    polygons = []
+    for place in json.loads(Path(path).read_text()):
+        points = np.asarray(place["points"], dtype=float)
+        if points.shape[1:] != (2,) or points.shape[0] < MIN_POLYGON_POINTS:
+            bad_polygon_msg = f"Place {place['name']} needs at least 3 (x, y) points."
+            raise ValueError(bad_polygon_msg)
+        polygons.append((place["name"], points))
+    return polygons
+
+
+def _points_in_polygon(x: np.ndarray, y: np.ndarray, polygon: np.ndarray) -> np.ndarray:
+    """Check which points are inside a polygon, by casting a ray from each.
+
+    Args:
+        x (np.ndarray): X coordinates of the points.
+        y (np.ndarray): Y coordinates of the points.
+        polygon (np.ndarray): The (n, 2) points of the polygon's outline.
+
+    Returns:
+        np.ndarray: Whether each point is inside the polygon.
+    """
+    inside = np.zeros(len(x), dtype=bool)
+    for (x1, y1), (x2, y2) in zip(polygon, np.roll(polygon, -1, axis=0)):
+        # Flip for every edge that a ray going right from the point crosses
+        crosses = (y1 > y) != (y2 > y)
+        with np.errstate(divide="ignore", invalid="ignore"):
+            x_cross = x1 + (y - y1) * (x2 - x1) / (y2 - y1)
+        inside ^= crosses & (x < x_cross)
+    return inside
+
+
+def fill_place_names(
+    df: pd.DataFrame, polygons: list[tuple[str, np.ndarray]]
+) -> pd.DataFrame:
+    """Fill in missing place names from place polygons.
+
+    New maps may ship without place names, so the place columns, like
+    `last_place_name`, `attacker_last_place_name` or `death_place`, are empty.
+    Each empty place takes the name of the first polygon that contains the
+    X and Y columns with the same prefix.
+
+    Args:
+        df (pd.DataFrame): A dataframe with place and position columns.
+        polygons (list[tuple[str, np.ndarray]]): The place polygons, from
+            `load_place_polygons`.
+
+    Returns:
+        pd.DataFrame: `df` with its empty place names filled in, where a
+            polygon contains the position.
+    """
+    for place_col in df.columns:
+        if not str(place_col).endswith(PLACE_COLUMN_SUFFIXES):
+            continue
+        prefix = str(place_col).removesuffix("last_place_name").removesuffix("place")
+        x_col, y_col = f"{prefix}X", f"{prefix}Y"
+        if x_col not in df.columns or y_col not in df.columns:
+            continue
+
+        is_empty = df[place_col].isna() | (df[place_col] == "")
+        x = df.loc[is_empty, x_col].to_numpy(dtype=float)
+        y = df.loc[is_empty, y_col].to_numpy(dtype=float)
+        places = df.loc[is_empty, place_col].to_numpy(dtype=object)
+        is_named = np.zeros(len(x), dtype=bool)
+        for name, polygon in polygons:
+            is_new = ~is_named & _points_in_polygon(x, y, polygon)
+            places[is_new] = name
+            is_named |= is_new
+        df.loc[is_empty, place_col] = places
+    return df
+
+
+def _half_hull(sorted_points: np.ndarray) -> list[np.ndarray]:
+    """Get the lower half of a convex hull, or the upper for reversed points.
+
+    Args:
+        sorted_points (np.ndarray): The (n, 2) points, sorted by x then y.
+
+    Returns:
+        list[np.ndarray]: The points of the half hull, in order.
+    """
+    hull = []
+    for point in sorted_points:
+        # Drop points until the hull turns left, i.e., counter-clockwise
+        while len(hull) > 1:
+            (x1, y1), (x2, y2) = hull[-1] - hull[-2], point - hull[-2]
+            if x1 * y2 - y1 * x2 > 0:
+                break
+            hull.pop()
+        hull.append(point)
+    return hull
+
+
+def _convex_hull(points: np.ndarray) -> np.ndarray:
+    """Get the convex hull of 2D points, with Andrew's monotone chain.
+
+    Args:
+        points (np.ndarray): The (n, 2) points.
+
+    Returns:
+        np.ndarray: The (m, 2) points of the hull, counter-clockwise.
+    """
+    points = np.unique(points, axis=0)
+    if len(points) < MIN_POLYGON_POINTS:
+        return points
+
+    lower = _half_hull(points)
+    upper = _half_hull(points[::-1])
+    return np.array(lower[:-1] + upper[:-1])
+
+
+def extract_place_polygons(
+    ticks_df: pd.DataFrame, cell_size: float = PLACE_POLYGON_CELL_SIZE
+) -> list[tuple[str, np.ndarray]]:
+    """Extract a simplified polygon for each place from player positions.
+
+    Demos don't include the map's nav mesh, so each place is outlined by the
+    convex hull of every position a player stood at with that
+    `last_place_name`. Places with fewer than 3 distinct positions are dropped.
+    Parse a few demos on a map with place names to get polygons for
+    `fill_place_names` or for plotting.
+
+    Args:
+        ticks_df (pd.DataFrame): Player ticks with `X`, `Y` and
+            `last_place_name`.
+        cell_size (float, optional): Grid size that positions are snapped to
+            before taking hulls. Defaults to PLACE_POLYGON_CELL_SIZE.
+
+    Returns:
+        list[tuple[str, np.ndarray]]: The name and (n, 2) points of each
+            polygon, sorted by name.
+    """
+    positions = ticks_df[["X", "Y", "last_place_name"]].dropna()
+    positions = positions[positions["last_place_name"] != ""]
+
+    polygons = []
+    for name, place_positions in positions.groupby("last_place_name"):
+        cells = (place_positions[["X", "Y"]].to_numpy(dtype=float) / cell_size).round()
+        hull = _convex_hull(cells) * cell_size
+        if len(hull) >= MIN_POLYGON_POINTS:
+            polygons.append((str(name), hull))
+    return polygons
+
+
+def to_place_json(polygons: list[tuple[str, np.ndarray]]) -> list[dict]:
+    """Convert place polygons to the JSON layout of `load_place_polygons`.
+
+    Args:
+        polygons (list[tuple[str, np.ndarray]]): The place polygons.
+
+    Returns:
+        list[dict]: A `name` and `points` for each polygon.
+    """
+    return [{"name": name, "points": points.tolist()} for name, points in polygons]
+
+
+def to_geojson(polygons: list[tuple[str, np.ndarray]]) -> dict:
+    """Convert place polygons to a GeoJSON feature collection, in game units.
+
+    Args:
+        polygons (list[tuple[str, np.ndarray]]): The place polygons.
+
+    Returns:
+        dict: A `FeatureCollection` with a `Polygon` feature for each polygon,
+            named in its `name` property.
+    """
+    return {
+        "type": "FeatureCollection",
+        "features": [
+            {
+                "type": "Feature",
+                "properties": {"name": name},
+                "geometry": {
+                    "type": "Polygon",
+                    # GeoJSON rings repeat their first point at the end
+                    "coordinates": [[*points.tolist(), points[0].tolist()]],
+                },
+            }
+            for name, points in polygons
+        ],
+    }
+
+
+def summarize_zones(positions_df: pd.DataFrame) -> pd.DataFrame:
+    """Get the bounding box of each bombsite and buy zone from player positions.
+
+    Demos don't include the map's trigger volumes, only whether each player is
+    inside one, so each zone is bounded by the positions players stood at
+    inside it. The boxes are at most as large as the real zones, and grow with
+    the number of demos the positions come from.
+
+    Args:
+        positions_df (pd.DataFrame): Player positions with `X`, `Y`, `Z`,
+            `team_name`, `in_buy_zone` and `which_bomb_zone`.
+
+    Returns:
+        pd.DataFrame: The bounds and number of positions of each zone.
+    """
+    bombsites = positions_df.assign(
+        zone=positions_df["which_bomb_zone"].map(BOMBSITE_ZONES)
+    )
+    buy_zones = positions_df[positions_df["in_buy_zone"].fillna(False).astype(bool)]
+    buy_zones = buy_zones.assign(zone=buy_zones["team_name"].map(BUY_ZONES))
+    zones = pd.concat([bombsites, buy_zones]).dropna(subset=["zone", "X", "Y", "Z"])
+    if zones.shape[0] == 0:
+        return pd.DataFrame(columns=ZONE_COLUMNS)
+
+    zones = zones.groupby("zone").agg(
+        min_X=("X", "min"),
+        max_X=("X", "max"),
+        min_Y=("Y", "min"),
+        max_Y=("Y", "max"),
+        min_Z=("Z", "min"),
+        max_Z=("Z", "max"),
+        n_positions=("X", "size"),
+    )
+    return zones.reset_index()[ZONE_COLUMNS]
+
+
+def parse_zones(
+    parser: DemoParser,
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+    sample_seconds: float = ZONE_SAMPLE_SECONDS,
+) -> pd.DataFrame:
+    """Parse the bounding box of each bombsite and buy zone.
+
+    Positions are sampled every `sample_seconds` during the rounds. See
+    `summarize_zones`.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        sample_seconds (float, optional): Seconds between samples. Defaults to
+            ZONE_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: The bounds and number of positions of each zone.
+    """
+    if rounds_df.shape[0] == 0:
+        return pd.DataFrame(columns=ZONE_COLUMNS)
+
+    sample_ticks = np.arange(
+        int(rounds_df["start"].min()),
+        int(rounds_df["official_end"].max()) + 1,
+        max(int(tick_rate * sample_seconds), 1),
+    )
+    positions_df = parser.parse_ticks(
+        wanted_props=[
+            "X",
+            "Y",
+            "Z",
+            "team_name",
+            "is_alive",
+            "in_buy_zone",
+            "which_bomb_zone",
+        ],
+        ticks=sample_ticks.tolist(),
+    )
+    if positions_df.shape[0] == 0:
+        return pd.DataFrame(columns=ZONE_COLUMNS)
+
+    positions_df = parse_col_types(positions_df)
+    positions_df = positions_df[
+        positions_df["team_name"].isin(["CT", "TERRORIST"]) & positions_df["is_alive"]
+    ]
+    return summarize_zones(positions_df)
+
+
+def summarize_place_times(
+    samples_df: pd.DataFrame, sample_seconds: float = PLACE_TIME_SAMPLE_SECONDS
+) -> pd.DataFrame:
+    """Add up the seconds each player spent alive in each place of each round.
+
+    Every sample counts for `sample_seconds`, so the times are accurate to
+    about one sample per place visit.
+
+    Args:
+        samples_df (pd.DataFrame): The `round`, `steamid`, `name`, `team_name`,
+            `is_alive` and `last_place_name` of every player at every sampled
+            tick.
+        sample_seconds (float, optional): Seconds between samples. Defaults to
+            PLACE_TIME_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: The seconds each player spent in each place of each
+            round, one row per player, round and place they visited.
+    """
+    alive_df = samples_df[
+        samples_df["is_alive"].fillna(False).astype(bool)
+        & samples_df["last_place_name"].notna()
+        & (samples_df["last_place_name"] != "")
+    ]
+    if alive_df.shape[0] == 0:
+        return pd.DataFrame(columns=PLACE_TIME_COLUMNS)
+
+    place_times = (
+        alive_df.assign(
+            steamid=alive_df["steamid"].astype(str), place=alive_df["last_place_name"]
+        )
+        .groupby(["round", "steamid", "place"])
+        .agg(
+            name=("name", "last"),
+            team_name=("team_name", "last"),
+            seconds=("place", "size"),
+        )
+        .reset_index()
+    )
+    place_times["seconds"] = place_times["seconds"] * sample_seconds
+    return (
+        place_times[PLACE_TIME_COLUMNS]
+        .sort_values(["round", "steamid", "seconds"], ascending=[True, True, False])
+        .reset_index(drop=True)
+    )
+
+
+def parse_place_times(
+    parser: DemoParser,
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+    sample_seconds: float = PLACE_TIME_SAMPLE_SECONDS,
+) -> pd.DataFrame:
+    """Parse the seconds each player spent in each place of each round.
+
+    Positions are sampled every `sample_seconds` from the end of freeze time
+    to the end of each round, whether or not ticks are parsed. See
+    `summarize_place_times`.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        sample_seconds (float, optional): Seconds between samples. Defaults to
+            PLACE_TIME_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: The seconds each player spent in each place of each round.
+    """
+    sample_step = max(int(tick_rate * sample_seconds), 1)
+    sample_rounds = rounds_df[["round", "freeze_end", "end"]].dropna().astype(int)
+    sample_ticks = pd.DataFrame(
+        [
+            {"round": round_num, "tick": tick}
+            for round_num, freeze_end, end in sample_rounds.itertuples(index=False)
+            for tick in range(freeze_end, end, sample_step)
+        ],
+        columns=["round", "tick"],
+    )
+    if sample_ticks.shape[0] == 0:
+        return pd.DataFrame(columns=PLACE_TIME_COLUMNS)
+
+    samples_df = parser.parse_ticks(
+        wanted_props=["is_alive", "team_name", "last_place_name"],
+        ticks=sample_ticks["tick"].unique().tolist(),
+    )
+    if samples_df.shape[0] == 0:
+        return pd.DataFrame(columns=PLACE_TIME_COLUMNS)
+
+    samples_df = parse_col_types(samples_df)
+    samples_df = samples_df[samples_df["team_name"].isin(["CT", "TERRORIST"])].merge(
+        sample_ticks, on="tick"
+    )
+    return summarize_place_times(samples_df, sample_seconds=sample_seconds)
@@ -0,0 +1,940 @@
+"""Module for player metadata parsing functions."""
+
+import hashlib
+from typing import Optional
+
+import numpy as np
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
+from loguru import logger
+
+from awpy.converters import (
+    RANK_TYPE_REVERSE_MAPPING,
+    map_premier_tier,
+    map_rank,
+    map_rank_type,
+)
+from awpy.parsers.economy import get_half_start_money, parse_equipment_values
+from awpy.parsers.utils import parse_col_types
+
+TEAM_FLAG_PROP = "CCSTeam.m_szTeamFlagImage"
+TEAM_LOGO_PROP = "CCSTeam.m_szTeamLogoImage"
+SPECTATOR_TEAM_NUM = 1
+NETWORK_SAMPLE_SECONDS = 1
+BOT_CONTROL_PROP = "CCSPlayerController.m_bControllingBot"
+ACTIVITY_SAMPLE_SECONDS = 1
+AFK_MAX_DISTANCE = 50
+BOT_CONTROLLED_SHARE = 0.5
+TEAM_KEY_LENGTH = 12
+AGENT_PROP = "agent_skin"
+
+# Steam IDs of bots, which have none of their own
+BOT_STEAMIDS = ["0", "None", "nan"]
+
+# Scoreboard props, and the columns they are saved as
+SCOREBOARD_PROPS = {
+    "kills_total": "kills",
+    "deaths_total": "deaths",
+    "assists_total": "assists",
+    "headshot_kills_total": "headshot_kills",
+    "mvps": "mvps",
+    "score": "score",
+    "damage_total": "damage",
+    "utility_damage_total": "utility_damage",
+    "enemies_flashed_total": "enemies_flashed",
+}
+
+
+def get_player_keys(steamids: pd.Series, names: pd.Series) -> pd.Series:
+    """Get a key for each player that tells bots apart.
+
+    Bots all share the Steam ID 0, so they are keyed by their name instead.
+
+    Args:
+        steamids (pd.Series): The Steam IDs of the players.
+        names (pd.Series): The names of the players.
+
+    Returns:
+        pd.Series: The Steam ID of each player, or `bot:<name>` for bots.
+    """
+    steamids = steamids.astype(str)
+    return steamids.where(~steamids.isin(BOT_STEAMIDS), "bot:" + names.astype(str))
+
+
+def parse_ranks(parser: DemoParser, tick: int) -> pd.DataFrame:
+    """Parse the matchmaking ranks and Premier CS Ratings of the players.
+
+    For Premier demos, the `rank` prop holds the CS Rating rather than a skill
+    group, so we split it into `premier_rating` and `rank_name` by rank type.
+
+    Args:
+        parser (DemoParser): The parser object.
+        tick (int): The tick to read the ranks at, usually the last round end.
+
+    Returns:
+        pd.DataFrame: The ranks for each player in the demofile.
+    """
+    ranks_df = parser.parse_ticks(
+        wanted_props=[
+            "rank",
+            "rank_if_win",
+            "rank_if_loss",
+            "rank_if_tie",
+            "comp_wins",
+            "comp_rank_type",
+        ],
+        ticks=[tick],
+    )
+    if ranks_df.shape[0] == 0:
+        return pd.DataFrame(
+            columns=[
+                "name",
+                "steamid",
+                "rank_type",
+                "rank",
+                "rank_name",
+                "premier_rating",
+                "premier_tier",
+                "rank_if_win",
+                "rank_if_loss",
+                "rank_if_tie",
+                "wins",
+            ]
+        )
+
+    ranks_df = parse_col_types(ranks_df).rename(columns={"comp_wins": "wins"})
+    ranks_df["rank_type"] = map_rank_type(ranks_df["comp_rank_type"])
+
+    # Premier ranks are CS Ratings, while the other ladders are skill groups
+    is_premier = ranks_df["comp_rank_type"] == RANK_TYPE_REVERSE_MAPPING["premier"]
+    ranks_df["premier_rating"] = np.where(is_premier, ranks_df["rank"], np.nan)
+    ranks_df["premier_tier"] = map_premier_tier(ranks_df["premier_rating"])
+    ranks_df["rank_name"] = map_rank(ranks_df["rank"].where(~is_premier))
+
+    return ranks_df[
+        [
+            "name",
+            "steamid",
+            "rank_type",
+            "rank",
+            "rank_name",
+            "premier_rating",
+            "premier_tier",
+            "rank_if_win",
+            "rank_if_loss",
+            "rank_if_tie",
+            "wins",
+        ]
+    ].reset_index(drop=True)
+
+
+def parse_scoreboard(parser: DemoParser, tick: int) -> pd.DataFrame:
+    """Parse the in-game scoreboard of each player, as counted by the game.
+
+    These are the game's own totals, so they can be used to check the stats
+    that awpy computes from events.
+
+    Args:
+        parser (DemoParser): The parser object.
+        tick (int): The tick to read the scoreboard at, usually the last round
+            end.
+
+    Returns:
+        pd.DataFrame: The scoreboard for each player in the demofile.
+    """
+    scoreboard_columns = ["name", "steamid", *SCOREBOARD_PROPS.values()]
+    scoreboard_df = parser.parse_ticks(
+        wanted_props=list(SCOREBOARD_PROPS), ticks=[tick]
+    )
+    if scoreboard_df.shape[0] == 0:
+        return pd.DataFrame(columns=scoreboard_columns)
+
+    scoreboard_df = parse_col_types(scoreboard_df).rename(columns=SCOREBOARD_PROPS)
+    return (
+        scoreboard_df[scoreboard_columns]
+        .sort_values("score", ascending=False)
+        .reset_index(drop=True)
+    )
+
+
+def parse_teams(parser: DemoParser, rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse the team metadata (clan name, flag and logo) for each round side.
+
+    Flags and logos are set through `mp_teamflag_*` and `mp_teamlogo_*`, so they
+    are usually only present in tournament demos.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The team metadata for each round and side.
+    """
+    team_columns = [
+        "round",
+        "team_name",
+        "team_clan_name",
+        "team_flag",
+        "team_logo",
+        "n_players",
+    ]
+    freeze_end_ticks = rounds_df["freeze_end"].dropna().astype(int).tolist()
+    if len(freeze_end_ticks) == 0:
+        return pd.DataFrame(columns=team_columns)
+
+    team_props = ["team_name", "team_clan_name"]
+    try:
+        teams_df = parser.parse_ticks(
+            wanted_props=[*team_props, TEAM_FLAG_PROP, TEAM_LOGO_PROP],
+            ticks=freeze_end_ticks,
+        ).rename(columns={TEAM_FLAG_PROP: "team_flag", TEAM_LOGO_PROP: "team_logo"})
+    except (KeyError, ValueError):
+        # The parser rejects props that the demo doesn't have
+        logger.debug("Team flags and logos are not available in this demo.")
+        teams_df = parser.parse_ticks(wanted_props=team_props, ticks=freeze_end_ticks)
+        teams_df["team_flag"] = None
+        teams_df["team_logo"] = None
+
+    teams_df = teams_df[teams_df["team_name"].isin(["CT", "TERRORIST"])]
+    teams_df = teams_df.merge(
+        rounds_df[["round", "freeze_end"]], left_on="tick", right_on="freeze_end"
+    )
+    teams_df = (
+        teams_df.groupby(["round", "team_name"])
+        .agg(
+            team_clan_name=("team_clan_name", "first"),
+            team_flag=("team_flag", "first"),
+            team_logo=("team_logo", "first"),
+            n_players=("steamid", "nunique"),
+        )
+        .reset_index()
+    )
+    return teams_df[team_columns]
+
+
+def parse_spectators(events: dict[str, pd.DataFrame]) -> pd.DataFrame:
+    """Parse when spectators, e.g., casters and GOTV relays, were connected.
+
+    Each stint on the spectator team is a row, starting when the account joins
+    the spectator team and ending when it changes team or disconnects. The
+    GOTV relay is a bot on the spectator team, so it is flagged by `is_gotv`.
+
+    Args:
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+
+    Returns:
+        pd.DataFrame: The spectators, with `start_tick` and `end_tick`. The end
+            tick is missing if they were still spectating when the demo ended.
+    """
+    spectator_columns = ["name", "steamid", "is_gotv", "start_tick", "end_tick"]
+    team_changes = events.get("player_team")
+    if team_changes is None or not {"team", "user_name", "user_steamid"}.issubset(
+        team_changes.columns
+    ):
+        return pd.DataFrame(columns=spectator_columns)
+
+    team_changes = parse_col_types(team_changes.copy())
+    is_disconnect = (
+        team_changes["disconnect"].fillna(False).astype(bool)
+        if "disconnect" in team_changes.columns
+        else False
+    )
+    team_changes["is_spectator"] = (
+        team_changes["team"] == SPECTATOR_TEAM_NUM
+    ) & ~is_disconnect
+    team_changes["is_gotv"] = (
+        team_changes["isbot"].fillna(False).astype(bool)
+        if "isbot" in team_changes.columns
+        else False
+    )
+
+    # Leaving the server also ends a stint on the spectator team
+    changes = [team_changes]
+    disconnects = events.get("player_disconnect")
+    if disconnects is not None and "user_steamid" in disconnects.columns:
+        disconnects = parse_col_types(disconnects.copy())
+        disconnects["is_spectator"] = False
+        changes.append(disconnects)
+    changes_df = pd.concat(changes, ignore_index=True).sort_values(
+        ["user_steamid", "tick"], kind="stable"
+    )
+
+    # A stint is a run of consecutive changes with the same spectator status
+    changes_df["stint"] = (
+        changes_df["is_spectator"]
+        != changes_df.groupby("user_steamid")["is_spectator"].shift()
+    ).cumsum()
+    stints_df = (
+        changes_df.groupby("stint")
+        .agg(
+            name=("user_name", "first"),
+            steamid=("user_steamid", "first"),
+            is_spectator=("is_spectator", "first"),
+            is_gotv=("is_gotv", "first"),
+            start_tick=("tick", "min"),
+        )
+        .reset_index(drop=True)
+    )
+    stints_df["end_tick"] = (
+        stints_df.groupby("steamid")["start_tick"].shift(-1).astype("Int64")
+    )
+    stints_df["is_gotv"] = stints_df["is_gotv"].fillna(False).astype(bool)
+    return (
+        stints_df[stints_df["is_spectator"]][spectator_columns]
+        .sort_values("start_tick")
+        .reset_index(drop=True)
+    )
+
+
+def get_spectating_steamids(spectators_df: pd.DataFrame, tick: int) -> set[str]:
+    """Get the accounts that were on the spectator team at a tick.
+
+    Args:
+        spectators_df (pd.DataFrame): The spectators dataframe.
+        tick (int): The tick to check.
+
+    Returns:
+        set[str]: The steamids of the spectators.
+    """
+    is_spectating = (spectators_df["start_tick"] <= tick) & (
+        spectators_df["end_tick"].isna() | (spectators_df["end_tick"] > tick)
+    )
+    return set(spectators_df.loc[is_spectating, "steamid"].astype(str))
+
+
+def parse_team_keys(spawns_df: pd.DataFrame, game: str = "cs2") -> pd.DataFrame:
+    """Key each side of each round by its roster, rather than by clan name.
+
+    Clan names are often empty in matchmaking demos, so each side of each half
+    gets a key hashed from the Steam IDs of everyone who spawned on it. Teams
+    keep their key when they switch sides, as long as their roster is the same.
+
+    Args:
+        spawns_df (pd.DataFrame): The parsed spawns.
+        game (str, optional): The game version. Defaults to "cs2".
+
+    Returns:
+        pd.DataFrame: The `team_key` of each round and `team_name`.
+    """
+    team_key_columns = ["round", "team_name", "team_key"]
+    if spawns_df.shape[0] == 0:
+        return pd.DataFrame(columns=team_key_columns)
+
+    rounds = np.sort(spawns_df["round"].unique())
+    halves = pd.DataFrame(
+        {
+            "round": rounds,
+            "half": np.cumsum(
+                [
+                    i == 0 or get_half_start_money(int(round_num), game) > 0
+                    for i, round_num in enumerate(rounds)
+                ]
+            ),
+        }
+    )
+    spawns_df = spawns_df.merge(halves, on="round")
+    rosters = (
+        spawns_df.groupby(["half", "team_name"])["steamid"]
+        .agg(lambda steamids: ",".join(sorted(set(steamids.astype(str)))))
+        .rename("roster")
+        .reset_index()
+    )
+    rosters["team_key"] = rosters["roster"].map(
+        lambda roster: hashlib.sha256(roster.encode()).hexdigest()[:TEAM_KEY_LENGTH]
+    )
+    team_keys_df = (
+        spawns_df[["round", "half", "team_name"]]
+        .drop_duplicates()
+        .merge(rosters, on=["half", "team_name"])
+    )
+    return (
+        team_keys_df[team_key_columns]
+        .sort_values(["round", "team_name"])
+        .reset_index(drop=True)
+    )
+
+
+def apply_team_keys(df: pd.DataFrame, team_keys_df: pd.DataFrame) -> pd.DataFrame:
+    """Add a team key next to every team name column of a dataframe.
+
+    For example, `attacker_team_name` gets an `attacker_team_key`.
+
+    Args:
+        df (pd.DataFrame): A dataframe with a `round` column.
+        team_keys_df (pd.DataFrame): The team keys, from `parse_team_keys`.
+
+    Returns:
+        pd.DataFrame: `df` with the team key columns.
+    """
+    if "round" not in df.columns:
+        return df
+    team_keys = team_keys_df.set_index(["round", "team_name"])["team_key"]
+    for col in [col for col in df.columns if col.endswith("team_name")]:
+        key_col = col.removesuffix("team_name") + "team_key"
+        df[key_col] = team_keys.reindex(
+            pd.MultiIndex.from_arrays([df["round"], df[col]])
+        ).to_numpy()
+    return df
+
+
+def parse_spawns(parser: DemoParser, rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse where each player spawned at the start of each round.
+
+    Spawn points are fixed for a map, so each distinct spawn position of a side
+    gets a `spawn_index`, ordered by X and then Y.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The spawn of each player in each round.
+    """
+    spawn_columns = [
+        "round",
+        "tick",
+        "name",
+        "steamid",
+        "team_name",
+        "X",
+        "Y",
+        "Z",
+        "yaw",
+        "spawn_index",
+    ]
+    start_ticks = rounds_df["start"].dropna().astype(int).tolist()
+    if len(start_ticks) == 0:
+        return pd.DataFrame(columns=spawn_columns)
+
+    spawns_df = parser.parse_ticks(
+        wanted_props=["team_name", "X", "Y", "Z", "yaw", "is_alive"],
+        ticks=start_ticks,
+    )
+    spawns_df = parse_col_types(spawns_df)
+    spawns_df = spawns_df[
+        spawns_df["team_name"].isin(["CT", "TERRORIST"]) & spawns_df["is_alive"]
+    ]
+    spawns_df = spawns_df.merge(
+        rounds_df[["round", "start"]], left_on="tick", right_on="start"
+    )
+
+    # Number the distinct spawn points of each side
+    spawns_df["spawn_X"] = spawns_df["X"].round()
+    spawns_df["spawn_Y"] = spawns_df["Y"].round()
+    spawn_points = (
+        spawns_df[["team_name", "spawn_X", "spawn_Y"]]
+        .drop_duplicates()
+        .sort_values(["team_name", "spawn_X", "spawn_Y"])
+    )
+    spawn_points["spawn_index"] = spawn_points.groupby("team_name").cumcount()
+    spawns_df = spawns_df.merge(spawn_points, on=["team_name", "spawn_X", "spawn_Y"])
+    return (
+        spawns_df[spawn_columns]
+        .sort_values(["round", "team_name", "spawn_index"])
+        .reset_index(drop=True)
+    )
+
+
+def parse_alive_counts(
+    spawns_df: pd.DataFrame,
+    kills_df: pd.DataFrame,
+    disconnects_df: Optional[pd.DataFrame] = None,
+) -> pd.DataFrame:
+    """Parse how many players are alive on each side whenever it changes.
+
+    Each round starts with the players who spawned, and a player is gone once
+    they die or disconnect, whichever is first.
+
+    Args:
+        spawns_df (pd.DataFrame): The parsed spawns.
+        kills_df (pd.DataFrame): The parsed kills.
+        disconnects_df (pd.DataFrame, optional): `player_disconnect` events
+            with a `round` column. Defaults to None.
+
+    Returns:
+        pd.DataFrame: The `ct_alive` and `t_alive` counts at the start of each
+            round and at every tick where they change.
+    """
+    alive_columns = ["round", "tick", "ct_alive", "t_alive"]
+    if spawns_df.shape[0] == 0:
+        return pd.DataFrame(columns=alive_columns)
+
+    spawned_df = spawns_df[["round", "tick", "team_name"]].copy()
+    spawned_df["player_key"] = get_player_keys(spawns_df["steamid"], spawns_df["name"])
+
+    # Players leave on their first death or disconnect of the round
+    departures = [
+        kills_df[["round", "tick", "victim_steamid", "victim_name"]].rename(
+            columns={"victim_steamid": "steamid", "victim_name": "name"}
+        )
+    ]
+    if disconnects_df is not None and {"user_steamid", "user_name"}.issubset(
+        disconnects_df.columns
+    ):
+        departures.append(
+            disconnects_df[["round", "tick", "user_steamid", "user_name"]].rename(
+                columns={"user_steamid": "steamid", "user_name": "name"}
+            )
+        )
+    departures_df = pd.concat(departures)
+    departures_df["player_key"] = get_player_keys(
+        departures_df["steamid"], departures_df["name"]
+    )
+    departures_df = (
+        departures_df.sort_values("tick")
+        .drop_duplicates(subset=["round", "player_key"])
+        .merge(
+            spawned_df[["round", "player_key", "team_name"]],
+            on=["round", "player_key"],
+        )
+    )
+
+    # Start with everyone alive, then remove players as they leave
+    changes_df = pd.concat(
+        [
+            spawned_df.groupby("round")
+            .agg(
+                tick=("tick", "min"),
+                ct_change=("team_name", lambda teams: (teams == "CT").sum()),
+                t_change=("team_name", lambda teams: (teams == "TERRORIST").sum()),
+            )
+            .reset_index(),
+            departures_df.assign(
+                ct_change=-(departures_df["team_name"] == "CT").astype(int),
+                t_change=-(departures_df["team_name"] == "TERRORIST").astype(int),
+            )[["round", "tick", "ct_change", "t_change"]],
+        ]
+    ).sort_values(["round", "tick"], kind="stable")
+    changes_df["ct_alive"] = changes_df.groupby("round")["ct_change"].cumsum()
+    changes_df["t_alive"] = changes_df.groupby("round")["t_change"].cumsum()
+
+    # Players who leave on the same tick make a single change
+    return (
+        changes_df.drop_duplicates(subset=["round", "tick"], keep="last")[
+            alive_columns
+        ]
+        .astype(int)
+        .reset_index(drop=True)
+    )
+
+
+def parse_kill_advantages(
+    kills_df: pd.DataFrame, alive_counts_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Add how many players were alive on each side right before each kill.
+
+    Kills on the same tick, e.g., a wallbang double kill, all see the counts
+    from before that tick.
+
+    Args:
+        kills_df (pd.DataFrame): The parsed kills.
+        alive_counts_df (pd.DataFrame): The parsed alive counts.
+
+    Returns:
+        pd.DataFrame: `kills_df` with `attacker_side_alive`, `victim_side_alive`,
+            the `man_advantage` from the attacker's side, e.g., "1v3", and
+            `is_clutch` if the attacker was the last one alive on their side.
+    """
+    # Kills are already in tick order, which a stable sort keeps
+    kills_df = kills_df.sort_values("tick", kind="stable")
+    alive_counts_df = alive_counts_df.astype(
+        {"round": kills_df["round"].dtype, "tick": kills_df["tick"].dtype}
+    ).sort_values("tick")
+    kills_df = pd.merge_asof(
+        kills_df,
+        alive_counts_df[["round", "tick", "ct_alive", "t_alive"]],
+        on="tick",
+        by="round",
+        allow_exact_matches=False,
+    )
+
+    kills_df["attacker_side_alive"] = kills_df["ct_alive"].where(
+        kills_df["attacker_team_name"] == "CT", kills_df["t_alive"]
+    )
+    kills_df["victim_side_alive"] = kills_df["ct_alive"].where(
+        kills_df["victim_team_name"] == "CT", kills_df["t_alive"]
+    )
+    has_counts = kills_df["attacker_side_alive"].notna() & kills_df[
+        "attacker_team_name"
+    ].isin(["CT", "TERRORIST"])
+    kills_df["man_advantage"] = None
+    kills_df.loc[has_counts, "man_advantage"] = (
+        kills_df.loc[has_counts, "attacker_side_alive"].astype(int).astype(str)
+        + "v"
+        + kills_df.loc[has_counts, "victim_side_alive"].astype(int).astype(str)
+    )
+    kills_df["is_clutch"] = has_counts & (kills_df["attacker_side_alive"] == 1)
+    return kills_df.drop(columns=["ct_alive", "t_alive"])
+
+
+def parse_survival(
+    parser: DemoParser,
+    rounds_df: pd.DataFrame,
+    spawns_df: pd.DataFrame,
+    kills_df: pd.DataFrame,
+    tick_rate: int = 64,
+) -> pd.DataFrame:
+    """Parse how long each player survived each round and where they died.
+
+    Survival time counts from the end of freeze time, to the player's death or
+    to the round end if they survived. Survivors saved a weapon if they still
+    hold a primary weapon at the round end.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        spawns_df (pd.DataFrame): The parsed spawns.
+        kills_df (pd.DataFrame): The parsed kills.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: The survival of each player in each round, with the
+            death position and place left empty for survivors.
+    """
+    survival_columns = [
+        "round",
+        "name",
+        "steamid",
+        "team_name",
+        "survived",
+        "survival_seconds",
+        "death_tick",
+        "death_X",
+        "death_Y",
+        "death_Z",
+        "death_place",
+        "saved_weapon",
+    ]
+    if spawns_df.shape[0] == 0:
+        return pd.DataFrame(columns=survival_columns)
+
+    survival_df = spawns_df[["round", "name", "steamid", "team_name"]].merge(
+        rounds_df[["round", "start", "freeze_end", "end"]], on="round"
+    )
+    survival_df["steamid"] = survival_df["steamid"].astype(str)
+
+    # The first death of each player in each round
+    deaths_df = (
+        kills_df[
+            [
+                "round",
+                "tick",
+                "victim_steamid",
+                "victim_X",
+                "victim_Y",
+                "victim_Z",
+                "victim_last_place_name",
+            ]
+        ]
+        .sort_values("tick")
+        .drop_duplicates(subset=["round", "victim_steamid"])
+        .rename(
+            columns={
+                "tick": "death_tick",
+                "victim_steamid": "steamid",
+                "victim_X": "death_X",
+                "victim_Y": "death_Y",
+                "victim_Z": "death_Z",
+                "victim_last_place_name": "death_place",
+            }
+        )
+    )
+    deaths_df["steamid"] = deaths_df["steamid"].astype(str)
+    survival_df = survival_df.merge(deaths_df, on=["round", "steamid"], how="left")
+    survival_df["survived"] = survival_df["death_tick"].isna()
+
+    play_start = survival_df["freeze_end"].fillna(survival_df["start"])
+    survival_end = survival_df["death_tick"].fillna(survival_df["end"])
+    survival_df["survival_seconds"] = (
+        (survival_end - play_start).astype("float64").clip(lower=0) / tick_rate
+    )
+
+    # Survivors who still hold a primary at the round end saved it
+    end_ticks = rounds_df["end"].dropna().astype(int).tolist()
+    survival_df["saved_weapon"] = False
+    if len(end_ticks) > 0:
+        inventories_df = parse_equipment_values(
+            parse_col_types(
+                parser.parse_ticks(wanted_props=["inventory"], ticks=end_ticks)
+            )
+        )
+        inventories_df["has_primary"] = inventories_df["primary_value"] > 0
+        survival_df = survival_df.merge(
+            inventories_df[["tick", "steamid", "has_primary"]],
+            left_on=["end", "steamid"],
+            right_on=["tick", "steamid"],
+            how="left",
+        )
+        survival_df["saved_weapon"] = survival_df["survived"] & survival_df[
+            "has_primary"
+        ].fillna(False).astype(bool)
+
+    return (
+        survival_df[survival_columns]
+        .sort_values(["round", "team_name", "steamid"])
+        .reset_index(drop=True)
+    )
+
+
+def parse_network(
+    parser: DemoParser,
+    rounds_df: pd.DataFrame,
+    tick_rate: int = 64,
+    sample_seconds: float = NETWORK_SAMPLE_SECONDS,
+) -> pd.DataFrame:
+    """Parse a timeline of the ping of each player.
+
+    Pings are sampled every `sample_seconds` during the rounds, and only the
+    samples where a player's ping changed are kept, so each row holds until the
+    player's next row. Demos don't record packet loss or choke, only the ping
+    shown on the scoreboard.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        sample_seconds (float, optional): Seconds between samples. Defaults to
+            NETWORK_SAMPLE_SECONDS.
+
+    Returns:
+        pd.DataFrame: The ping of each player from each tick on.
+    """
+    network_columns = ["tick", "name", "steamid", "ping"]
+    if rounds_df.shape[0] == 0:
+        return pd.DataFrame(columns=network_columns)
+
+    sample_ticks = np.arange(
+        int(rounds_df["start"].min()),
+        int(rounds_df["official_end"].max()) + 1,
+        max(int(tick_rate * sample_seconds), 1),
+    )
+    network_df = parser.parse_ticks(
+        wanted_props=["ping", "team_name"], ticks=sample_ticks.tolist()
+    )
+    if network_df.shape[0] == 0:
+        return pd.DataFrame(columns=network_columns)
+
+    network_df = parse_col_types(network_df)
+    network_df = network_df[
+        network_df["team_name"].isin(["CT", "TERRORIST"])
+    ].sort_values(["steamid", "tick"])
+
+    # Keep the first sample of each player, and the samples where the ping moved
+    is_change = network_df["ping"] != network_df.groupby("steamid")["ping"].shift()
+    return (
+        network_df[is_change][network_columns]
+        .sort_values(["tick", "steamid"])
+        .reset_index(drop=True)
+    )
+
+
+def parse_agents(parser: DemoParser, rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse the agent model each player uses on each side.
+
+    Agents are read at the end of each freeze time, and only the rounds where a
+    player's agent or side changed are kept, so each row holds until the
+    player's next row.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: The agent of each player from each round on. Empty if
+            the demo doesn't record agents.
+    """
+    agent_columns = ["round", "tick", "name", "steamid", "team_name", "agent"]
+    freeze_end_ticks = rounds_df["freeze_end"].dropna().astype(int).tolist()
+    if len(freeze_end_ticks) == 0:
+        return pd.DataFrame(columns=agent_columns)
+
+    try:
+        agents_df = parser.parse_ticks(
+            wanted_props=["team_name", AGENT_PROP], ticks=freeze_end_ticks
+        )
+    except Exception:
+        logger.debug("Agents are not available in this demo.")
+        return pd.DataFrame(columns=agent_columns)
+    if agents_df.shape[0] == 0:
+        return pd.DataFrame(columns=agent_columns)
+
+    agents_df = parse_col_types(agents_df).rename(columns={AGENT_PROP: "agent"})
+    agents_df = agents_df[agents_df["team_name"].isin(["CT", "TERRORIST"])]
+    agents_df = agents_df.merge(
+        rounds_df[["round", "freeze_end"]], left_on="tick", right_on="freeze_end"
+    ).sort_values(["steamid", "round"])
+
+    # Keep the first round of each player, and the rounds where the agent moved
+    previous = agents_df.groupby("steamid")[["team_name", "agent"]].shift()
+    is_change = (agents_df["team_name"] != previous["team_name"]) | (
+        agents_df["agent"] != previous["agent"]
+    )
+    return (
+        agents_df[is_change][agent_columns]
+        .sort_values(["round", "team_name", "steamid"])
+        .reset_index(drop=True)
+    )
+
+
+def parse_activity(
+    parser: DemoParser,
+    rounds_df: pd.DataFrame,
+    weapon_fires_df: pd.DataFrame,
+    tick_rate: int = 64,
+    sample_seconds: float = ACTIVITY_SAMPLE_SECONDS,
+    afk_max_distance: float = AFK_MAX_DISTANCE,
+    bot_controlled_share: float = BOT_CONTROLLED_SHARE,
+) -> pd.DataFrame:
+    """Flag bots, AFK players and players who spent a round controlling a bot.
+
+    A player is AFK in a round if, while alive after freeze time, they never
+    moved more than `afk_max_distance` units from where they started and never
+    fired. After dying, players can take control of a teammate bot, whose kills
+    then count for the player, so a round is bot controlled if the player was
+    controlling a bot in at least `bot_controlled_share` of the samples. Bots
+    themselves have no Steam ID and are flagged by `is_bot`.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        sample_seconds (float, optional): Seconds between position samples.
+            Defaults to ACTIVITY_SAMPLE_SECONDS.
+        afk_max_distance (float, optional): Distance in units under which a
+            player didn't move. Defaults to AFK_MAX_DISTANCE.
+        bot_controlled_share (float, optional): Share of the round spent
+            controlling a bot from which the round is bot controlled. Defaults
+            to BOT_CONTROLLED_SHARE.
+
+    Returns:
+        pd.DataFrame: The activity of each player in each round.
+    """
+    activity_columns = [
+        "round",
+        "name",
+        "steamid",
+        "team_name",
+        "max_distance",
+        "n_shots",
+        "bot_control_share",
+        "is_bot",
+        "is_afk",
+        "is_bot_controlled",
+    ]
+    sample_step = max(int(tick_rate * sample_seconds), 1)
+    sample_rounds = rounds_df[["round", "freeze_end", "end"]].dropna().astype(int)
+    sample_ticks = pd.DataFrame(
+        [
+            {"round": round_num, "tick": tick}
+            for round_num, freeze_end, end in sample_rounds.itertuples(index=False)
+            for tick in range(freeze_end, end + 1, sample_step)
+        ],
+        columns=["round", "tick"],
+    )
+    if sample_ticks.shape[0] == 0:
+        return pd.DataFrame(columns=activity_columns)
+
+    samples_df = parser.parse_ticks(
+        wanted_props=["X", "Y", "Z", "is_alive", "team_name", BOT_CONTROL_PROP],
+        ticks=sample_ticks["tick"].unique().tolist(),
+    )
+    samples_df = parse_col_types(samples_df).rename(
+        columns={BOT_CONTROL_PROP: "is_controlling_bot"}
+    )
+    samples_df = samples_df[samples_df["team_name"].isin(["CT", "TERRORIST"])].merge(
+        sample_ticks, on="tick"
+    )
+    return summarize_activity(
+        samples_df,
+        weapon_fires_df,
+        afk_max_distance=afk_max_distance,
+        bot_controlled_share=bot_controlled_share,
+    )[activity_columns]
+
+
+def summarize_activity(
+    samples_df: pd.DataFrame,
+    weapon_fires_df: pd.DataFrame,
+    afk_max_distance: float = AFK_MAX_DISTANCE,
+    bot_controlled_share: float = BOT_CONTROLLED_SHARE,
+) -> pd.DataFrame:
+    """Summarize position samples into the activity of each player and round.
+
+    Args:
+        samples_df (pd.DataFrame): The `round`, `X`, `Y`, `Z`, `is_alive` and
+            `is_controlling_bot` of every player at every sampled tick.
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires.
+        afk_max_distance (float, optional): Distance in units under which a
+            player didn't move. Defaults to AFK_MAX_DISTANCE.
+        bot_controlled_share (float, optional): Share of the round spent
+            controlling a bot from which the round is bot controlled. Defaults
+            to BOT_CONTROLLED_SHARE.
+
+    Returns:
+        pd.DataFrame: The activity of each player in each round. See
+            `parse_activity`.
+    """
+    samples_df = samples_df.sort_values("tick").copy()
+    samples_df["steamid"] = samples_df["steamid"].astype(str)
+    samples_df["player_key"] = get_player_keys(
+        samples_df["steamid"], samples_df["name"]
+    )
+    alive_df = samples_df[samples_df["is_alive"].fillna(False).astype(bool)].copy()
+
+    # Distance from where the player stood when they were first seen alive
+    first_positions = alive_df.groupby(["round", "player_key"])[
+        ["X", "Y", "Z"]
+    ].transform("first")
+    alive_df["distance"] = np.sqrt(
+        ((alive_df[["X", "Y", "Z"]] - first_positions) ** 2).sum(axis=1)
+    )
+
+    activity_df = (
+        samples_df.groupby(["round", "player_key"])
+        .agg(
+            name=("name", "last"),
+            steamid=("steamid", "last"),
+            team_name=("team_name", "last"),
+            bot_control_share=(
+                "is_controlling_bot",
+                lambda x: x.fillna(False).astype(bool).mean(),
+            ),
+        )
+        .reset_index()
+    )
+    max_distances = alive_df.groupby(["round", "player_key"])["distance"].max()
+    activity_df = activity_df.merge(
+        max_distances.rename("max_distance").reset_index(),
+        on=["round", "player_key"],
+        how="left",
+    )
+
+    shots = (
+        weapon_fires_df.assign(
+            player_key=get_player_keys(
+                weapon_fires_df["player_steamid"], weapon_fires_df["player_name"]
+            )
+        )
+        .groupby(["round", "player_key"])
+        .size()
+        .rename("n_shots")
+        .reset_index()
+    )
+    activity_df = activity_df.merge(shots, on=["round", "player_key"], how="left")
+    activity_df["n_shots"] = activity_df["n_shots"].fillna(0).astype(int)
+
+    activity_df["is_bot"] = activity_df["steamid"].isin(BOT_STEAMIDS)
+    activity_df["is_afk"] = (
+        activity_df["max_distance"].notna()
+        & (activity_df["max_distance"] < afk_max_distance)
+        & (activity_df["n_shots"] == 0)
+    )
+    activity_df["is_bot_controlled"] = (
+        activity_df["bot_control_share"] >= bot_controlled_share
+    )
+    return (
+        activity_df.drop(columns="player_key")
+        .sort_values(["round", "team_name", "name"])
+        .reset_index(drop=True)
+    )
@@ -0,0 +1,765 @@
+"""Module for player positioning metrics, like team spread and distances."""
+
+import numpy as np
+import pandas as pd
+from demoparser2 import DemoParser  # pylint: disable=E0611
+
+from awpy.converters import map_weapon_class
+from awpy.parsers.utils import parse_col_types
+
+# Teams have at most 5 players, but allow for more in other game modes
+MAX_TEAM_SIZE = 10
+
+# A hull needs 3 points to have an area
+MIN_HULL_POINTS = 3
+
+# Heights above a player's origin of their eyes and the middle of their body
+PLAYER_EYE_HEIGHT = 64.0
+PLAYER_CROUCH_EYE_HEIGHT = 46.0
+PLAYER_BODY_HEIGHT = 36.0
+
+# Field of view of unscoped players, and of each zoom level of an AWP
+VIEW_FOV = 90.0
+ZOOM_FOVS = {1: 40.0, 2: 10.0}
+
+# Shots within a few degrees of an enemy's body that don't hurt them are misses,
+# and damage can be registered a tick after the shot
+NEAR_MISS_DEGREES = 3.0
+NEAR_MISS_MAX_DISTANCE = 3000.0
+NEAR_MISS_HIT_TICKS = 2
+GUN_CLASSES = ("pistol", "smg", "heavy", "rifle")
+
+# Smokes fill roughly a sphere, centered above where the grenade landed
+SMOKE_RADIUS = 144.0
+SMOKE_CENTER_HEIGHT = 64.0
+
+# Player positions are deduplicated on a grid of this size to name smoke places
+SMOKE_PLACE_CELL_SIZE = 32
+
+
+def _convex_hull_area(points: np.ndarray) -> float:
+    """Get the area of the convex hull of 2D points (Andrew's monotone chain).
+
+    Args:
+        points (np.ndarray): Array of shape (n, 2), which may contain NaN rows.
+
+    Returns:
+        float: The hull area, which is 0 for fewer than 3 points.
+    """
+    points = points[~np.isnan(points).any(axis=1)]
+    if points.shape[0] < MIN_HULL_POINTS:
+        return 0.0
+    points = sorted(map(tuple, points))
+
+    def _cross(o: tuple, a: tuple, b: tuple) -> float:
+        return (a[0] - o[0]) * (b[1] - o[1]) - (a[1] - o[1]) * (b[0] - o[0])
+
+    lower, upper = [], []
+    for point in points:
+        while len(lower) > 1 and _cross(lower[-2], lower[-1], point) <= 0:
+            lower.pop()
+        lower.append(point)
+    for point in reversed(points):
+        while len(upper) > 1 and _cross(upper[-2], upper[-1], point) <= 0:
+            upper.pop()
+        upper.append(point)
+    hull = np.array(lower[:-1] + upper[:-1])
+
+    # Shoelace formula
+    x, y = hull[:, 0], hull[:, 1]
+    return float(0.5 * abs(np.dot(x, np.roll(y, 1)) - np.dot(y, np.roll(x, 1))))
+
+
+def _to_team_slots(
+    alive_df: pd.DataFrame, group_cols: list[str], value_cols: list[str]
+) -> tuple[pd.DataFrame, np.ndarray]:
+    """Pivot players into one row per group, with one slot per player.
+
+    Args:
+        alive_df (pd.DataFrame): The alive players.
+        group_cols (list[str]): Columns that identify a team at a tick.
+        value_cols (list[str]): Columns to pivot, e.g., `X` and `Y`.
+
+    Returns:
+        tuple[pd.DataFrame, np.ndarray]: The groups, and an array of shape
+            (n_groups, n_slots, len(value_cols)) padded with NaN.
+    """
+    alive_df = alive_df.assign(slot=alive_df.groupby(group_cols).cumcount())
+    alive_df = alive_df[alive_df["slot"] < MAX_TEAM_SIZE]
+    slots_df = alive_df.pivot_table(
+        index=group_cols, columns="slot", values=value_cols, aggfunc="first"
+    )
+    n_slots = alive_df["slot"].max() + 1 if alive_df.shape[0] > 0 else 0
+    slots = np.stack(
+        [
+            slots_df[col].reindex(columns=range(n_slots)).to_numpy(dtype=float)
+            for col in value_cols
+        ],
+        axis=-1,
+    )
+    return slots_df.index.to_frame(index=False), slots
+
+
+def parse_team_shapes(ticks_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse the centroid, spread and map control of each team at each tick.
+
+    Only alive players count. Spread is the mean distance between pairs of
+    teammates, and map control is approximated by the area of the convex hull
+    around the team.
+
+    Args:
+        ticks_df (pd.DataFrame): Ticks with `tick`, `team_name`, `health`, `X`
+            and `Y` columns.
+
+    Returns:
+        pd.DataFrame: One row per tick and team, with `n_alive`, `centroid_X`,
+            `centroid_Y`, `spread` and `hull_area`.
+    """
+    group_cols = ["tick", "round", "team_name"]
+    group_cols = [col for col in group_cols if col in ticks_df.columns]
+    shape_columns = [
+        *group_cols,
+        "n_alive",
+        "centroid_X",
+        "centroid_Y",
+        "spread",
+        "hull_area",
+    ]
+    alive_df = ticks_df[
+        ticks_df["team_name"].isin(["CT", "TERRORIST"]) & (ticks_df["health"] > 0)
+    ]
+    if alive_df.shape[0] == 0:
+        return pd.DataFrame(columns=shape_columns)
+
+    shapes_df, slots = _to_team_slots(alive_df, group_cols, ["X", "Y"])
+    shapes_df["n_alive"] = (~np.isnan(slots[:, :, 0])).sum(axis=1)
+    shapes_df["centroid_X"] = np.nanmean(slots[:, :, 0], axis=1)
+    shapes_df["centroid_Y"] = np.nanmean(slots[:, :, 1], axis=1)
+
+    # Mean of the distances between each pair of teammates
+    deltas = slots[:, :, np.newaxis, :] - slots[:, np.newaxis, :, :]
+    distances = np.sqrt((deltas**2).sum(axis=-1))
+    pairs = np.triu(np.ones(distances.shape[1:], dtype=bool), k=1)
+    pair_distances = np.where(pairs, distances, np.nan)
+    with np.errstate(invalid="ignore"):
+        n_pairs = (~np.isnan(pair_distances)).sum(axis=(1, 2))
+        shapes_df["spread"] = np.where(
+            n_pairs > 0, np.nansum(pair_distances, axis=(1, 2)) / n_pairs, 0.0
+        )
+
+    shapes_df["hull_area"] = [_convex_hull_area(points) for points in slots]
+    return shapes_df[shape_columns]
+
+
+def _nearest_in_chunk(
+    coords: np.ndarray, teams: np.ndarray
+) -> tuple[np.ndarray, np.ndarray, np.ndarray, np.ndarray]:
+    """Find each player's nearest teammate and enemy for a chunk of ticks.
+
+    Args:
+        coords (np.ndarray): Positions of shape (n_ticks, n_slots, 3).
+        teams (np.ndarray): Team of each slot, -1 for empty slots.
+
+    Returns:
+        tuple[np.ndarray, np.ndarray, np.ndarray, np.ndarray]: The distance to and
+            slot of the nearest teammate, then of the nearest enemy.
+    """
+    squared_distances = np.zeros(coords.shape[:2] + coords.shape[1:2])
+    for axis in range(coords.shape[-1]):
+        deltas = coords[:, :, np.newaxis, axis] - coords[:, np.newaxis, :, axis]
+        squared_distances += deltas**2
+    distances = np.sqrt(squared_distances)
+
+    # A player is not their own teammate, and empty slots are nobody
+    is_other = ~np.eye(coords.shape[1], dtype=bool)[np.newaxis, :, :]
+    is_occupied = (teams >= 0)[:, np.newaxis, :]
+    is_same_team = teams[:, :, np.newaxis] == teams[:, np.newaxis, :]
+
+    nearest = []
+    for is_candidate in [
+        is_other & is_occupied & is_same_team,
+        is_occupied & ~is_same_team,
+    ]:
+        candidate_distances = np.where(is_candidate, distances, np.inf)
+        nearest_slot = candidate_distances.argmin(axis=2)
+        nearest_distance = np.take_along_axis(
+            candidate_distances, nearest_slot[:, :, np.newaxis], axis=2
+        )[:, :, 0]
+        nearest.extend([nearest_distance, nearest_slot])
+    return tuple(nearest)
+
+
+def parse_nearest_players(
+    ticks_df: pd.DataFrame, chunk_size: int = 50000
+) -> pd.DataFrame:
+    """Add the distance to, and Steam ID of, each player's nearest teammate and enemy.
+
+    Only alive players count, and players without an alive teammate or enemy
+    get NaN distances.
+
+    Args:
+        ticks_df (pd.DataFrame): Ticks with `tick`, `steamid`, `team_name`,
+            `health`, `X`, `Y` and `Z` columns.
+        chunk_size (int, optional): Number of ticks to compare at once, which
+            bounds memory use. Defaults to 50000.
+
+    Returns:
+        pd.DataFrame: `ticks_df` with `nearest_teammate_distance`,
+            `nearest_teammate_steamid`, `nearest_enemy_distance` and
+            `nearest_enemy_steamid` columns.
+    """
+    for side in ["teammate", "enemy"]:
+        ticks_df[f"nearest_{side}_distance"] = np.nan
+        ticks_df[f"nearest_{side}_steamid"] = None
+
+    is_alive = ticks_df["team_name"].isin(["CT", "TERRORIST"]) & (
+        ticks_df["health"] > 0
+    )
+    alive_df = ticks_df[is_alive]
+    if alive_df.shape[0] == 0:
+        return ticks_df
+
+    # Lay out the alive players of each tick in slots
+    tick_codes, _ = pd.factorize(alive_df["tick"])
+    slot_codes = alive_df.groupby("tick").cumcount().to_numpy()
+    n_ticks, n_slots = tick_codes.max() + 1, slot_codes.max() + 1
+    coords = np.full((n_ticks, n_slots, 3), np.nan)
+    coords[tick_codes, slot_codes] = alive_df[["X", "Y", "Z"]].to_numpy(dtype=float)
+    teams = np.full((n_ticks, n_slots), -1)
+    teams[tick_codes, slot_codes] = (alive_df["team_name"] == "CT").to_numpy()
+    steamids = np.full((n_ticks, n_slots), None, dtype=object)
+    steamids[tick_codes, slot_codes] = alive_df["steamid"].to_numpy()
+
+    result_names = [
+        "teammate_distance",
+        "teammate_slot",
+        "enemy_distance",
+        "enemy_slot",
+    ]
+    results = {name: np.empty(len(alive_df)) for name in result_names}
+    for start in range(0, n_ticks, chunk_size):
+        end = min(start + chunk_size, n_ticks)
+        in_chunk = (tick_codes >= start) & (tick_codes < end)
+        chunk_results = _nearest_in_chunk(coords[start:end], teams[start:end])
+        for name, values in zip(results, chunk_results):
+            results[name][in_chunk] = values[
+                tick_codes[in_chunk] - start, slot_codes[in_chunk]
+            ]
+
+    for side in ["teammate", "enemy"]:
+        distances = results[f"{side}_distance"]
+        has_nearest = np.isfinite(distances)
+        nearest_slots = results[f"{side}_slot"].astype(int)
+        nearest_steamids = np.where(
+            has_nearest, steamids[tick_codes, nearest_slots], None
+        )
+        ticks_df.loc[is_alive, f"nearest_{side}_distance"] = np.where(
+            has_nearest, distances, np.nan
+        )
+        ticks_df.loc[is_alive, f"nearest_{side}_steamid"] = nearest_steamids
+    return ticks_df
+
+
+def view_vectors(pitch: pd.Series, yaw: pd.Series) -> np.ndarray:
+    """Get the unit vectors players look along from their view angles.
+
+    Args:
+        pitch (pd.Series): View pitch in degrees, positive when looking down.
+        yaw (pd.Series): View yaw in degrees.
+
+    Returns:
+        np.ndarray: Array of shape (n, 3) of unit view vectors.
+    """
+    pitch_rad = np.radians(pitch.to_numpy(dtype=float))
+    yaw_rad = np.radians(yaw.to_numpy(dtype=float))
+    return np.stack(
+        [
+            np.cos(pitch_rad) * np.cos(yaw_rad),
+            np.cos(pitch_rad) * np.sin(yaw_rad),
+            -np.sin(pitch_rad),
+        ],
+        axis=-1,
+    )
+
+
+def _eye_heights(df: pd.DataFrame, prefix: str) -> np.ndarray:
+    """Get the eye heights of players, which are lower as they crouch."""
+    duck_col = f"{prefix}duck_amount"
+    duck_amount = (
+        df[duck_col].fillna(0).to_numpy(dtype=float)
+        if duck_col in df.columns
+        else np.zeros(len(df))
+    )
+    return PLAYER_EYE_HEIGHT - duck_amount * (
+        PLAYER_EYE_HEIGHT - PLAYER_CROUCH_EYE_HEIGHT
+    )
+
+
+def parse_view_rays(ticks_df: pd.DataFrame) -> pd.DataFrame:
+    """Add the ray each alive player looks along, e.g., to draw vision cones.
+
+    The ray starts at the player's eyes, which are lower as they crouch. The
+    field of view narrows when scoped, by `zoom_lvl` if it was parsed.
+
+    Args:
+        ticks_df (pd.DataFrame): Ticks with `team_name`, `health`, `X`, `Y`,
+            `Z`, `pitch` and `yaw` columns.
+
+    Returns:
+        pd.DataFrame: `ticks_df` with `view_origin_X`, `view_origin_Y`,
+            `view_origin_Z`, the unit `view_dir_X`, `view_dir_Y` and
+            `view_dir_Z`, and `view_fov` in degrees. They are NaN for dead
+            players and spectators.
+    """
+    ray_cols = [
+        "view_origin_X",
+        "view_origin_Y",
+        "view_origin_Z",
+        "view_dir_X",
+        "view_dir_Y",
+        "view_dir_Z",
+        "view_fov",
+    ]
+    for col in ray_cols:
+        ticks_df[col] = np.nan
+
+    is_alive = ticks_df["team_name"].isin(["CT", "TERRORIST"]) & (
+        ticks_df["health"] > 0
+    )
+    alive_df = ticks_df[is_alive]
+    if alive_df.shape[0] == 0:
+        return ticks_df
+
+    directions = view_vectors(alive_df["pitch"], alive_df["yaw"])
+    fov = (
+        alive_df["zoom_lvl"].map(ZOOM_FOVS).fillna(VIEW_FOV).to_numpy(dtype=float)
+        if "zoom_lvl" in alive_df.columns
+        else np.full(len(alive_df), VIEW_FOV)
+    )
+    ticks_df.loc[is_alive, ray_cols] = np.column_stack(
+        [
+            alive_df["X"].to_numpy(dtype=float),
+            alive_df["Y"].to_numpy(dtype=float),
+            alive_df["Z"].to_numpy(dtype=float) + _eye_heights(alive_df, ""),
+            directions,
+            fov,
+        ]
+    )
+    return ticks_df
+
+
+def parse_crosshair_offsets(df: pd.DataFrame) -> pd.DataFrame:
+    """Add how far the attacker's crosshair was from the victim's head.
+
+    The offsets are the angles the attacker would have had to turn to aim at
+    the victim's head, e.g., to measure crosshair placement. Positive
+    `crosshair_yaw_offset` is to the left and positive `crosshair_pitch_offset`
+    is down, as with the view angles.
+
+    Args:
+        df (pd.DataFrame): Kills or damages, with the attacker's position and
+            view angles and the victim's position.
+
+    Returns:
+        pd.DataFrame: `df` with `crosshair_yaw_offset`, `crosshair_pitch_offset`
+            and the total `crosshair_angle`, in degrees.
+    """
+    eyes = df[["attacker_X", "attacker_Y", "attacker_Z"]].to_numpy(dtype=float)
+    eyes[:, 2] += _eye_heights(df, "attacker_")
+    heads = df[["victim_X", "victim_Y", "victim_Z"]].to_numpy(dtype=float)
+    heads[:, 2] += _eye_heights(df, "victim_")
+    to_head = heads - eyes
+
+    head_yaw = np.degrees(np.arctan2(to_head[:, 1], to_head[:, 0]))
+    head_pitch = -np.degrees(
+        np.arctan2(to_head[:, 2], np.linalg.norm(to_head[:, :2], axis=1))
+    )
+    yaw_offset = head_yaw - df["attacker_yaw"].to_numpy(dtype=float)
+    df["crosshair_yaw_offset"] = (yaw_offset + 180) % 360 - 180
+    df["crosshair_pitch_offset"] = head_pitch - df["attacker_pitch"].to_numpy(
+        dtype=float
+    )
+
+    with np.errstate(invalid="ignore", divide="ignore"):
+        cos_angles = (
+            view_vectors(df["attacker_pitch"], df["attacker_yaw"]) * to_head
+        ).sum(axis=1) / np.linalg.norm(to_head, axis=1)
+    df["crosshair_angle"] = np.degrees(np.arccos(np.clip(cos_angles, -1, 1)))
+    return df
+
+
+def parse_supporting_teammates(
+    kill_ticks_df: pd.DataFrame,
+    kills_df: pd.DataFrame,
+    max_distance: float = 1500.0,
+    fov: float = 90.0,
+) -> pd.DataFrame:
+    """Count the alive teammates of the attacker who were covering the victim.
+
+    Without line of sight checks, a teammate supports a kill when the victim is
+    within `max_distance` and inside the teammate's field of view.
+
+    Args:
+        kill_ticks_df (pd.DataFrame): The players around the kills. See
+            `awpy.parsers.ticks.parse_kill_ticks`.
+        kills_df (pd.DataFrame): The parsed kills.
+        max_distance (float, optional): Furthest a supporting teammate can be
+            from the victim. Defaults to 1500.0.
+        fov (float, optional): Field of view of teammates, in degrees. Defaults
+            to 90.0.
+
+    Returns:
+        pd.DataFrame: `kills_df` with `supporting_teammates` and
+            `supporting_teammate_steamids` columns.
+    """
+    kills_df["supporting_teammates"] = 0
+    kills_df["supporting_teammate_steamids"] = [[] for _ in range(len(kills_df))]
+    if kills_df.shape[0] == 0:
+        return kills_df
+
+    teammates_df = kills_df[
+        [
+            "kill_feed_index",
+            "tick",
+            "attacker_steamid",
+            "attacker_team_name",
+            "victim_X",
+            "victim_Y",
+            "victim_Z",
+        ]
+    ].merge(
+        kill_ticks_df[
+            ["tick", "steamid", "X", "Y", "Z", "pitch", "yaw", "team_name", "health"]
+        ],
+        left_on=["tick", "attacker_team_name"],
+        right_on=["tick", "team_name"],
+    )
+    teammates_df = teammates_df[
+        (teammates_df["steamid"] != teammates_df["attacker_steamid"])
+        & (teammates_df["health"] > 0)
+    ]
+
+    # Victim relative to each teammate
+    to_victim = (
+        teammates_df[["victim_X", "victim_Y", "victim_Z"]].to_numpy(dtype=float)
+        - teammates_df[["X", "Y", "Z"]].to_numpy(dtype=float)
+    )
+    distances = np.linalg.norm(to_victim, axis=1)
+    with np.errstate(invalid="ignore", divide="ignore"):
+        cos_angles = (
+            view_vectors(teammates_df["pitch"], teammates_df["yaw"]) * to_victim
+        ).sum(axis=1) / distances
+    is_supporting = (distances <= max_distance) & (
+        cos_angles >= np.cos(np.radians(fov / 2))
+    )
+
+    supporters = teammates_df[is_supporting].groupby("kill_feed_index")["steamid"]
+    supporters = supporters.agg(list)
+    supporter_lists = kills_df["kill_feed_index"].map(supporters)
+    kills_df["supporting_teammate_steamids"] = [
+        steamids if isinstance(steamids, list) else []
+        for steamids in supporter_lists
+    ]
+    kills_df["supporting_teammates"] = supporter_lists.str.len().fillna(0).astype(int)
+    return kills_df
+
+
+def parse_line_through_smoke(
+    df: pd.DataFrame,
+    smokes_df: pd.DataFrame,
+    source_prefix: str = "attacker_",
+    target_prefix: str = "victim_",
+    smoke_radius: float = SMOKE_RADIUS,
+) -> pd.DataFrame:
+    """Check if the line between two players passes through an active smoke.
+
+    Unlike the `thrusmoke` flag of kills, this works for any event with two
+    players, e.g., damages. Smokes are approximated by spheres around where
+    they bloomed, and players by their eye and body height.
+
+    Args:
+        df (pd.DataFrame): Dataframe with a `tick` and the X, Y and Z of the
+            source and target players.
+        smokes_df (pd.DataFrame): The parsed smokes.
+        source_prefix (str, optional): Prefix of the player looking. Defaults to
+            "attacker_".
+        target_prefix (str, optional): Prefix of the player looked at. Defaults
+            to "victim_".
+        smoke_radius (float, optional): Radius of a smoke. Defaults to
+            SMOKE_RADIUS.
+
+    Returns:
+        pd.DataFrame: `df` with a `line_through_smoke` column.
+    """
+    df["line_through_smoke"] = False
+    if df.shape[0] == 0 or smokes_df.shape[0] == 0:
+        return df
+
+    source = df[[f"{source_prefix}X", f"{source_prefix}Y", f"{source_prefix}Z"]]
+    source = source.to_numpy(dtype=float) + [0, 0, PLAYER_EYE_HEIGHT]
+    target = df[[f"{target_prefix}X", f"{target_prefix}Y", f"{target_prefix}Z"]]
+    target = target.to_numpy(dtype=float) + [0, 0, PLAYER_BODY_HEIGHT]
+    line = target - source
+    line_length_sq = (line**2).sum(axis=1)
+
+    ticks = df["tick"].to_numpy()
+    through_smoke = np.zeros(len(df), dtype=bool)
+    for smoke in smokes_df.itertuples():
+        end_tick = np.inf if pd.isna(smoke.end_tick) else smoke.end_tick
+        is_active = (ticks >= smoke.start_tick) & (ticks <= end_tick)
+        if not is_active.any():
+            continue
+
+        # Closest point on each line to the smoke's center
+        center = np.array([smoke.X, smoke.Y, smoke.Z + SMOKE_CENTER_HEIGHT])
+        active_source, active_line = source[is_active], line[is_active]
+        with np.errstate(invalid="ignore", divide="ignore"):
+            projection = ((center - active_source) * active_line).sum(axis=1)
+            t = projection / line_length_sq[is_active]
+        t = np.clip(np.nan_to_num(t), 0, 1)
+        closest = active_source + t[:, np.newaxis] * active_line
+        distances = np.linalg.norm(closest - center, axis=1)
+        through_smoke[is_active] |= distances <= smoke_radius
+
+    df["line_through_smoke"] = through_smoke
+    return df
+
+
+def find_near_misses(
+    weapon_fires_df: pd.DataFrame,
+    players_df: pd.DataFrame,
+    damages_df: pd.DataFrame,
+    max_angle: float = NEAR_MISS_DEGREES,
+    max_distance: float = NEAR_MISS_MAX_DISTANCE,
+) -> pd.DataFrame:
+    """Find the gun shots aimed close to an enemy that didn't hurt them.
+
+    Args:
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires.
+        players_df (pd.DataFrame): The `X`, `Y`, `Z`, `team_name` and `health`
+            of every player at the weapon fire ticks.
+        damages_df (pd.DataFrame): The parsed damages.
+        max_angle (float, optional): Degrees between the shooter's view and the
+            enemy's body under which a shot is near. Defaults to
+            NEAR_MISS_DEGREES.
+        max_distance (float, optional): Furthest an enemy can be from the
+            shooter. Defaults to NEAR_MISS_MAX_DISTANCE.
+
+    Returns:
+        pd.DataFrame: One row for each shot and enemy it narrowly missed, with
+            the `distance` and `angle` between the view and the enemy.
+    """
+    near_miss_columns = [
+        "round",
+        "tick",
+        "player_name",
+        "player_steamid",
+        "player_team_name",
+        "weapon",
+        "target_name",
+        "target_steamid",
+        "distance",
+        "angle",
+    ]
+    fires_df = weapon_fires_df[
+        map_weapon_class(weapon_fires_df["weapon"]).isin(GUN_CLASSES)
+    ].reset_index(drop=True)
+    fires_df["fire_id"] = fires_df.index
+    targets_df = fires_df.merge(
+        players_df[["tick", "name", "steamid", "team_name", "X", "Y", "Z", "health"]]
+        .astype({"steamid": str})
+        .rename(
+            columns={
+                "name": "target_name",
+                "steamid": "target_steamid",
+                "team_name": "target_team_name",
+            }
+        ),
+        on="tick",
+    )
+    targets_df = targets_df[
+        (targets_df["target_team_name"] != targets_df["player_team_name"])
+        & targets_df["target_team_name"].isin(["CT", "TERRORIST"])
+        & (targets_df["health"] > 0)
+    ]
+    if targets_df.shape[0] == 0:
+        return pd.DataFrame(columns=near_miss_columns)
+
+    eyes = targets_df[["player_X", "player_Y", "player_Z"]].to_numpy(dtype=float)
+    eyes[:, 2] += PLAYER_EYE_HEIGHT
+    bodies = targets_df[["X", "Y", "Z"]].to_numpy(dtype=float)
+    bodies[:, 2] += PLAYER_BODY_HEIGHT
+    to_target = bodies - eyes
+    distances = np.linalg.norm(to_target, axis=1)
+    with np.errstate(invalid="ignore", divide="ignore"):
+        cos_angles = (
+            view_vectors(targets_df["player_pitch"], targets_df["player_yaw"])
+            * to_target
+        ).sum(axis=1) / distances
+    targets_df = targets_df.assign(
+        distance=distances,
+        angle=np.degrees(np.arccos(np.clip(cos_angles, -1, 1))),
+    )
+    targets_df = targets_df[
+        (targets_df["angle"] <= max_angle) & (targets_df["distance"] <= max_distance)
+    ]
+
+    # Drop the shots that hurt the enemy they were aimed at
+    hits_df = targets_df.merge(
+        damages_df[["tick", "attacker_steamid", "victim_steamid"]].astype(
+            {"attacker_steamid": str, "victim_steamid": str}
+        ),
+        left_on=["player_steamid", "target_steamid"],
+        right_on=["attacker_steamid", "victim_steamid"],
+        suffixes=("", "_damage"),
+    )
+    hits_df = hits_df[
+        hits_df["tick_damage"].between(
+            hits_df["tick"], hits_df["tick"] + NEAR_MISS_HIT_TICKS
+        )
+    ]
+    is_hit = pd.MultiIndex.from_frame(
+        targets_df[["fire_id", "target_steamid"]]
+    ).isin(pd.MultiIndex.from_frame(hits_df[["fire_id", "target_steamid"]]))
+    return (
+        targets_df[~is_hit][near_miss_columns]
+        .sort_values(["tick", "player_steamid"])
+        .reset_index(drop=True)
+    )
+
+
+def parse_near_misses(
+    parser: DemoParser,
+    weapon_fires_df: pd.DataFrame,
+    damages_df: pd.DataFrame,
+    max_angle: float = NEAR_MISS_DEGREES,
+) -> pd.DataFrame:
+    """Parse the gun shots that narrowly missed an enemy. See `find_near_misses`.
+
+    Args:
+        parser (DemoParser): The parser object.
+        weapon_fires_df (pd.DataFrame): The parsed weapon fires, with rounds.
+        damages_df (pd.DataFrame): The parsed damages.
+        max_angle (float, optional): Degrees between the shooter's view and the
+            enemy's body under which a shot is near. Defaults to
+            NEAR_MISS_DEGREES.
+
+    Returns:
+        pd.DataFrame: The near misses.
+    """
+    fire_ticks = weapon_fires_df["tick"].unique().tolist()
+    players_df = (
+        parse_col_types(
+            parser.parse_ticks(
+                wanted_props=["X", "Y", "Z", "team_name", "health"], ticks=fire_ticks
+            )
+        )
+        if len(fire_ticks) > 0
+        else pd.DataFrame(
+            columns=["tick", "name", "steamid", "team_name", "X", "Y", "Z", "health"]
+        )
+    )
+    return find_near_misses(
+        weapon_fires_df, players_df, damages_df, max_angle=max_angle
+    )
+
+
+def parse_smoke_places(
+    smokes_df: pd.DataFrame,
+    ticks_df: pd.DataFrame,
+    map_name: str,
+    max_distance: float = SMOKE_RADIUS,
+    cell_size: float = SMOKE_PLACE_CELL_SIZE,
+) -> pd.DataFrame:
+    """Name the place each smoke landed in, e.g., to tell which choke it blocks.
+
+    Demos don't include the map's nav mesh, so a smoke takes the
+    `last_place_name` of the closest position any player stood at in the
+    ticks. Smokes with no player position within `max_distance` units, e.g.,
+    on a roof, have no place.
+
+    Args:
+        smokes_df (pd.DataFrame): The parsed smokes.
+        ticks_df (pd.DataFrame): The parsed ticks, with `last_place_name`.
+        map_name (str): The map of the demo, used in the label.
+        max_distance (float, optional): Farthest a player position can be
+            from a smoke to name it. Defaults to SMOKE_RADIUS.
+        cell_size (float, optional): Grid size that player positions are
+            deduplicated on. Defaults to SMOKE_PLACE_CELL_SIZE.
+
+    Returns:
+        pd.DataFrame: `smokes_df` with `place` and `smoke_label` columns, e.g.,
+            "de_mirage TopofMid smoke".
+    """
+    smokes_df["place"] = None
+    smokes_df["smoke_label"] = None
+    positions = ticks_df[["X", "Y", "Z", "last_place_name"]].dropna()
+    positions = positions[positions["last_place_name"] != ""]
+    if smokes_df.shape[0] == 0 or positions.shape[0] == 0:
+        return smokes_df
+
+    cells = (positions[["X", "Y", "Z"]] / cell_size).round()
+    positions = positions[~cells.duplicated()]
+    position_coords = positions[["X", "Y", "Z"]].to_numpy(dtype=float)
+    place_names = positions["last_place_name"].to_numpy()
+
+    places = []
+    for smoke in smokes_df[["X", "Y", "Z"]].to_numpy(dtype=float):
+        distances = np.linalg.norm(position_coords - smoke, axis=1)
+        closest = np.argmin(distances)
+        places.append(
+            place_names[closest] if distances[closest] <= max_distance else None
+        )
+    smokes_df["place"] = places
+    smokes_df["smoke_label"] = [
+        None if place is None else f"{map_name} {place} smoke" for place in places
+    ]
+    return smokes_df
+
+
+def parse_victim_positions(
+    kill_ticks_df: pd.DataFrame, kills_df: pd.DataFrame
+) -> pd.DataFrame:
+    """Add where each victim stood on the tick before the kill.
+
+    GOTV interpolates player positions, so the `victim_X`, `victim_Y` and
+    `victim_Z` of the kill event can lag where the victim really died. The
+    positions on the tick before the kill are the last entity update of the
+    victim while alive. Both are kept so users can choose.
+
+    Args:
+        kill_ticks_df (pd.DataFrame): The players around the kills. See
+            `awpy.parsers.ticks.parse_kill_ticks`.
+        kills_df (pd.DataFrame): The parsed kills.
+
+    Returns:
+        pd.DataFrame: `kills_df` with `victim_alive_X`, `victim_alive_Y`,
+            `victim_alive_Z` and `victim_position_discrepancy`, the distance
+            in units between the two positions.
+    """
+    alive_cols = ["victim_alive_X", "victim_alive_Y", "victim_alive_Z"]
+    if kills_df.shape[0] == 0:
+        for col in [*alive_cols, "victim_position_discrepancy"]:
+            kills_df[col] = pd.Series(dtype="float64")
+        return kills_df
+
+    positions_df = kill_ticks_df[["tick", "steamid", "X", "Y", "Z"]].copy()
+    positions_df["tick"] += 1
+    kills_df = kills_df.merge(
+        positions_df.rename(
+            columns={
+                "steamid": "victim_steamid",
+                "X": "victim_alive_X",
+                "Y": "victim_alive_Y",
+                "Z": "victim_alive_Z",
+            }
+        ),
+        on=["tick", "victim_steamid"],
+        how="left",
+    )
+    kills_df["victim_position_discrepancy"] = np.linalg.norm(
+        kills_df[alive_cols].to_numpy(dtype=float)
+        - kills_df[["victim_X", "victim_Y", "victim_Z"]].to_numpy(dtype=float),
+        axis=1,
+    )
+    return kills_df
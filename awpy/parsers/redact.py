@@ -0,0 +1,93 @@
+"""Module for redacting parsed demos to share under privacy constraints."""
+
+import json
+from pathlib import Path
+from typing import Optional
+
+import pandas as pd
+
+# How chat is redacted: kept as is, with the text replaced, or dropped
+REDACT_CHAT_MODES = ("keep", "redact", "drop")
+REDACTED_TEXT = "[redacted]"
+
+# Header keys that name the server or the recording client
+REDACT_HEADER_KEYS = ["server_name", "client_name"]
+
+DEFAULT_REDACTION_POLICY = {
+    "anonymize": True,
+    "salt": None,
+    "chat": "drop",
+    "header": REDACT_HEADER_KEYS,
+}
+
+
+def load_redaction_policy(path: Optional[Path] = None) -> dict:
+    """Load a redaction policy, filling in the defaults for missing keys.
+
+    A policy is a JSON object with `anonymize` (whether to replace names and
+    Steam IDs), `salt` (to keep aliases stable across demos), `chat` (one of
+    REDACT_CHAT_MODES) and `header` (header keys to clear), e.g.,
+    `{"chat": "redact", "salt": "my-org"}`.
+
+    Args:
+        path (Path, optional): Path to the JSON policy. Defaults to None, which
+            is DEFAULT_REDACTION_POLICY.
+
+    Raises:
+        ValueError: Raises a ValueError if the policy has unknown keys or an
+            unknown chat mode.
+
+    Returns:
+        dict: The redaction policy.
+    """
+    policy = json.loads(Path(path).read_text()) if path is not None else {}
+    unknown_keys = set(policy) - set(DEFAULT_REDACTION_POLICY)
+    if len(unknown_keys) > 0:
+        unknown_keys_msg = f"Unknown redaction policy keys: {sorted(unknown_keys)}"
+        raise ValueError(unknown_keys_msg)
+
+    policy = {**DEFAULT_REDACTION_POLICY, **policy}
+    if policy["chat"] not in REDACT_CHAT_MODES:
+        chat_mode_msg = (
+            f"Redaction chat mode must be one of {REDACT_CHAT_MODES}, "
+            f"not {policy['chat']}"
+        )
+        raise ValueError(chat_mode_msg)
+    return policy
+
+
+def redact_chat(chat_df: pd.DataFrame, mode: str) -> pd.DataFrame:
+    """Redact chat messages, or any other dataframe of chat text.
+
+    Args:
+        chat_df (pd.DataFrame): Dataframe with chat text in `message`, `text`
+            or `args` columns, e.g., the parsed chat or admin events.
+        mode (str): One of REDACT_CHAT_MODES.
+
+    Returns:
+        pd.DataFrame: `chat_df` as is, with its text replaced by REDACTED_TEXT,
+            or without any rows.
+    """
+    if mode == "drop":
+        return chat_df.iloc[0:0]
+    if mode == "redact":
+        chat_df = chat_df.copy()
+        for text_col in ["message", "text", "args"]:
+            if text_col in chat_df.columns:
+                chat_df[text_col] = chat_df[text_col].where(
+                    chat_df[text_col].isna(), REDACTED_TEXT
+                )
+    return chat_df
+
+
+def redact_header(header: dict, keys: list[str]) -> dict:
+    """Clear header keys, e.g., the server name.
+
+    Args:
+        header (dict): The parsed header.
+        keys (list[str]): Header keys to clear.
+
+    Returns:
+        dict: `header` with the keys set to None.
+    """
+    return {key: None if key in keys else value for key, value in header.items()}
@@ -1,11 +1,26 @@
 """Module for round parsing functions."""
 
-from typing import Union
+from typing import Optional, Union
 
 import numpy as np
 import pandas as pd
 from demoparser2 import DemoParser  # pylint: disable=E0611
 
+# Seconds between the end of a round and the next one, i.e., the default
+# `mp_round_restart_delay` of competitive servers
+DEFAULT_ROUND_RESTART_DELAY = 7
+
+# How each round end reason reads in round summaries
+ROUND_SUMMARY_REASONS = {
+    "target_bombed": "bomb",
+    "bomb_defused": "defuse",
+    "ct_win": "elimination",
+    "t_win": "elimination",
+    "target_saved": "time",
+    "t_surrender": "surrender",
+    "ct_surrender": "surrender",
+}
+
 
 def _find_bomb_plant_tick(row: pd.Series, bomb_ticks: pd.Series) -> Union[int, float]:
     """Find the bomb plant tick for a round.
@@ -141,6 +156,9 @@ def parse_rounds(parser: DemoParser, events: dict[str, pd.DataFrame]) -> pd.Data
     rounds_df["bomb_plant"] = pd.NA
     rounds_df["bomb_plant"] = rounds_df["bomb_plant"].astype(pd.Int64Dtype())
 
+    rounds_df["bomb_explode"] = pd.NA
+    rounds_df["bomb_explode"] = rounds_df["bomb_explode"].astype(pd.Int64Dtype())
+
     # Find the bomb plant ticks
     bomb_planted = events.get("bomb_planted")
     if bomb_planted.shape[0] == 0:
@@ -150,4 +168,331 @@ def parse_rounds(parser: DemoParser, events: dict[str, pd.DataFrame]) -> pd.Data
         _find_bomb_plant_tick, bomb_ticks=bomb_planted["tick"], axis=1
     ).astype(pd.Int64Dtype())
 
+    # Find the bomb explosion ticks
+    bomb_exploded = events.get("bomb_exploded")
+    if bomb_exploded is None or bomb_exploded.shape[0] == 0:
+        return rounds_df
+
+    rounds_df["bomb_explode"] = rounds_df.apply(
+        _find_bomb_plant_tick, bomb_ticks=bomb_exploded["tick"], axis=1
+    ).astype(pd.Int64Dtype())
+
+    return rounds_df
+
+
+def parse_restart_delays(
+    rounds_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    tick_rate: int = 64,
+    default_delay: float = DEFAULT_ROUND_RESTART_DELAY,
+) -> pd.DataFrame:
+    """Get the restart delay of each round and fill in missing official ends.
+
+    The delay is the value of `mp_round_restart_delay` when the round ended,
+    so changes mid-match are picked up. Rounds without a
+    `round_officially_ended` event end `restart_delay` seconds after the round
+    ended, and no round officially ends after the next one starts.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        default_delay (float, optional): Seconds to use before the convar is
+            set. Defaults to DEFAULT_ROUND_RESTART_DELAY.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with a `restart_delay` column, in seconds,
+            and fixed `official_end` ticks.
+    """
+    rounds_df["restart_delay"] = float(default_delay)
+    if rounds_df.shape[0] == 0:
+        return rounds_df
+
+    server_cvars = events.get("server_cvar")
+    if server_cvars is not None and {"cvarname", "cvarvalue"}.issubset(
+        server_cvars.columns
+    ):
+        delays = server_cvars[
+            server_cvars["cvarname"] == "mp_round_restart_delay"
+        ].sort_values("tick")
+        if delays.shape[0] > 0:
+            # The latest change before the round ended is the delay of the round
+            change_idx = (
+                np.searchsorted(
+                    delays["tick"].to_numpy(),
+                    rounds_df["end"].to_numpy(dtype="int64"),
+                    side="right",
+                )
+                - 1
+            )
+            values = pd.to_numeric(delays["cvarvalue"], errors="coerce").to_numpy()
+            round_delays = values[np.maximum(change_idx, 0)]
+            rounds_df["restart_delay"] = np.where(
+                (change_idx >= 0) & ~np.isnan(round_delays),
+                round_delays,
+                rounds_df["restart_delay"],
+            )
+
+    # A missing official end was filled with the end of the round
+    next_start = rounds_df["start"].shift(-1).astype("Float64")
+    is_missing = (rounds_df["official_end"] <= rounds_df["end"]) & next_start.notna()
+    delayed_ends = rounds_df["end"] + (rounds_df["restart_delay"] * tick_rate).round()
+    rounds_df["official_end"] = (
+        rounds_df["official_end"]
+        .astype("Float64")
+        .where(~is_missing, delayed_ends)
+        .clip(upper=next_start)
+        .astype("Int32")
+    )
+    return rounds_df
+
+
+def parse_map_segments(
+    rounds_df: pd.DataFrame, events: dict[str, pd.DataFrame]
+) -> pd.DataFrame:
+    """Number the matches in a demo, for demos recorded over several matches.
+
+    Servers that don't stop recording between maps put every match in one
+    demo. A new match starts when the game is restarted (`begin_new_match`)
+    and the score goes back to 0-0.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with a `map_segment` column, starting at 1.
+    """
+    rounds_df["map_segment"] = 1
+    new_match = events.get("begin_new_match")
+    if new_match is None or new_match.shape[0] == 0 or rounds_df.shape[0] == 0:
+        return rounds_df
+
+    # A restart between two rounds starts a new segment from the later round
+    previous_end = rounds_df["official_end"].shift(1)
+    new_match_ticks = new_match["tick"].to_numpy()
+    starts_segment = [
+        bool(((new_match_ticks > prev_end) & (new_match_ticks <= start)).any())
+        for prev_end, start in zip(previous_end.iloc[1:], rounds_df["start"].iloc[1:])
+    ]
+    rounds_df["map_segment"] = np.cumsum([False, *starts_segment]) + 1
+    return rounds_df
+
+
+def parse_win_streaks(rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Count how many rounds in a row the winner of each round has won.
+
+    Teams are followed by their `ct_team_key` and `t_team_key` when the rounds
+    have them, so streaks carry over halftime, and by side otherwise. Streaks
+    restart in each map segment. A round breaks a streak if the other team had
+    won at least the two rounds before it.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with `win_streak` and `broke_streak` columns.
+    """
+    winner_side = rounds_df["winner"].map(
+        {"CT": "CT", "T": "TERRORIST", "TERRORIST": "TERRORIST"}
+    )
+    if {"ct_team_key", "t_team_key"}.issubset(rounds_df.columns):
+        winner_team = rounds_df["ct_team_key"].where(
+            winner_side == "CT", rounds_df["t_team_key"]
+        )
+    else:
+        winner_team = winner_side
+    segments = (
+        rounds_df["map_segment"]
+        if "map_segment" in rounds_df.columns
+        else pd.Series(1, index=rounds_df.index)
+    )
+
+    new_streak = (winner_team != winner_team.shift()) | (
+        segments != segments.shift()
+    )
+    rounds_df["win_streak"] = (
+        rounds_df.groupby(new_streak.cumsum()).cumcount() + 1
+    )
+    rounds_df["broke_streak"] = (
+        new_streak
+        & (segments == segments.shift())
+        & (rounds_df["win_streak"].shift() >= 2)
+    )
     return rounds_df
+
+
+def _with_place(text: str, place: Optional[str]) -> str:
+    """Add a place to a summary part, if there is one.
+
+    Args:
+        text (str): The summary part.
+        place (Optional[str]): The place, e.g., `TopofMid`.
+
+    Returns:
+        str: The summary part, followed by the place.
+    """
+    return f"{text} {place}" if isinstance(place, str) and place != "" else text
+
+
+def parse_round_summaries(
+    rounds_df: pd.DataFrame,
+    kills_df: pd.DataFrame,
+    bomb_df: pd.DataFrame,
+    smokes_df: pd.DataFrame,
+    executes_df: Optional[pd.DataFrame] = None,
+) -> pd.DataFrame:
+    """Write a short human-readable summary of each round.
+
+    Summaries are built from the parsed events, e.g., "CT win by defuse;
+    opening kill s1mple vs ZywOo TopofMid; 3 smokes; A execute; planted
+    BombsiteA", and are meant for reading or for language model pipelines.
+    Smokes are counted by place when smokes have a `place`, i.e., when ticks
+    are parsed.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        kills_df (pd.DataFrame): The parsed kills.
+        bomb_df (pd.DataFrame): The parsed bomb events.
+        smokes_df (pd.DataFrame): The parsed smokes.
+        executes_df (pd.DataFrame, optional): The parsed executes. Defaults to
+            None.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with a `summary_text` column.
+    """
+    summaries = []
+    for round_row in rounds_df.itertuples():
+        winner = "T" if round_row.winner == "TERRORIST" else round_row.winner
+        reason = ROUND_SUMMARY_REASONS.get(round_row.reason, round_row.reason)
+        parts = [f"{winner} win by {reason}"]
+
+        round_kills = kills_df[kills_df["round"] == round_row.round]
+        if round_kills.shape[0] > 0:
+            opening_kill = round_kills.sort_values("tick").iloc[0]
+            attacker = opening_kill["attacker_name"]
+            parts.append(
+                _with_place(
+                    f"opening kill {attacker if pd.notna(attacker) else 'world'} "
+                    f"vs {opening_kill['victim_name']}",
+                    opening_kill["victim_last_place_name"],
+                )
+            )
+
+        round_smokes = smokes_df[smokes_df["round"] == round_row.round]
+        if "place" in round_smokes.columns and round_smokes["place"].notna().any():
+            parts.extend(
+                f"{count} smokes {place}" if count > 1 else f"1 smoke {place}"
+                for place, count in round_smokes["place"].value_counts().items()
+            )
+        elif round_smokes.shape[0] > 0:
+            count = round_smokes.shape[0]
+            parts.append(f"{count} smokes" if count > 1 else "1 smoke")
+
+        if executes_df is not None:
+            parts.extend(
+                f"{site} execute"
+                for site in executes_df.loc[
+                    executes_df["round"] == round_row.round, "site"
+                ]
+            )
+
+        round_plants = bomb_df[
+            (bomb_df["round"] == round_row.round) & (bomb_df["event"] == "planted")
+        ]
+        if round_plants.shape[0] > 0:
+            parts.append(
+                _with_place("planted", round_plants.iloc[0]["user_last_place_name"])
+            )
+        summaries.append("; ".join(parts))
+
+    rounds_df["summary_text"] = summaries
+    return rounds_df
+
+
+def parse_round_range(round_range: Union[str, int, tuple[int, int]]) -> tuple[int, int]:
+    """Parse a round range, e.g., `"5-12"`, to its first and last round.
+
+    Args:
+        round_range (Union[str, int, tuple[int, int]]): A round (e.g., `5`), a
+            string range (e.g., `"5-12"`) or a tuple of the first and last round.
+
+    Returns:
+        tuple[int, int]: The first and last round, inclusive.
+
+    Raises:
+        ValueError: If the range is not valid.
+    """
+    bad_round_range_msg = f"Invalid round range: {round_range}"
+    range_parts = (
+        list(round_range)
+        if isinstance(round_range, tuple)
+        else str(round_range).strip().split("-")
+    )
+    if len(range_parts) not in (1, 2):
+        raise ValueError(bad_round_range_msg)
+    try:
+        first_round, last_round = int(range_parts[0]), int(range_parts[-1])
+    except ValueError as err:
+        raise ValueError(bad_round_range_msg) from err
+    if first_round < 1 or last_round < first_round:
+        raise ValueError(bad_round_range_msg)
+    return first_round, last_round
+
+
+def parse_tick_range(tick_range: str) -> tuple[int, int]:
+    """Parse a tick range, e.g., `"1000:2000"`, to its first and last tick.
+
+    Args:
+        tick_range (str): The first and last tick, separated by a colon.
+
+    Returns:
+        tuple[int, int]: The first and last tick, inclusive.
+
+    Raises:
+        ValueError: If the range is not valid.
+    """
+    bad_tick_range_msg = f"Invalid tick range: {tick_range}"
+    range_parts = str(tick_range).strip().split(":")
+    if len(range_parts) != 2:
+        raise ValueError(bad_tick_range_msg)
+    try:
+        start_tick, end_tick = int(range_parts[0]), int(range_parts[1])
+    except ValueError as err:
+        raise ValueError(bad_tick_range_msg) from err
+    if start_tick < 0 or end_tick < start_tick:
+        raise ValueError(bad_tick_range_msg)
+    return start_tick, end_tick
+
+
+def get_tick_window(
+    rounds_df: pd.DataFrame,
+    round_range: Optional[tuple[int, int]] = None,
+    from_tick: Optional[int] = None,
+    to_tick: Optional[int] = None,
+) -> tuple[int, int]:
+    """Get the ticks to keep for a round range and tick bounds.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        round_range (tuple[int, int], optional): The first and last round to
+            keep. Defaults to None, which keeps every round.
+        from_tick (int, optional): First tick to keep. Defaults to None.
+        to_tick (int, optional): Last tick to keep. Defaults to None.
+
+    Returns:
+        tuple[int, int]: The first and last tick to keep, inclusive.
+    """
+    start_tick, end_tick = 0, np.iinfo(np.int64).max
+    if round_range is not None:
+        first_round, last_round = round_range
+        kept_rounds = rounds_df[rounds_df["round"].between(first_round, last_round)]
+        if kept_rounds.shape[0] == 0:
+            return start_tick, -1
+        start_tick = int(kept_rounds["start"].min())
+        end_tick = int(kept_rounds["official_end"].max())
+    if from_tick is not None:
+        start_tick = max(start_tick, from_tick)
+    if to_tick is not None:
+        end_tick = min(end_tick, to_tick)
+    return start_tick, end_tick
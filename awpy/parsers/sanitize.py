@@ -0,0 +1,111 @@
+"""Module for detecting and cleaning invalid player positions."""
+
+from typing import Optional
+
+import numpy as np
+import pandas as pd
+
+from awpy.data.map_data import MAP_DATA
+
+# Radar images are 1024x1024 pixels, scaled to world units by the map scale
+RADAR_SIZE_IN_PIXELS = 1024
+
+# Players move ~4 units per tick when running, so 20+ is a teleport
+MAX_UNITS_PER_TICK = 20.0
+
+
+def get_map_bounds(map_name: str) -> Optional[tuple[float, float, float, float]]:
+    """Get the X/Y world coordinate bounds of a map's radar.
+
+    Args:
+        map_name (str): Name of the map, e.g., `de_dust2`.
+
+    Returns:
+        Optional[tuple[float, float, float, float]]: The (min_x, max_x, min_y, max_y)
+            bounds, or None if the map is unknown.
+    """
+    if map_name not in MAP_DATA:
+        return None
+    metadata = MAP_DATA[map_name]
+    size = RADAR_SIZE_IN_PIXELS * metadata["scale"]
+    return (
+        metadata["pos_x"],
+        metadata["pos_x"] + size,
+        metadata["pos_y"] - size,
+        metadata["pos_y"],
+    )
+
+
+def sanitize_positions(
+    df: pd.DataFrame,
+    map_name: Optional[str] = None,
+    prefix: str = "",
+    max_units_per_tick: float = MAX_UNITS_PER_TICK,
+    *,
+    check_teleports: bool = True,
+    fix: bool = False,
+) -> pd.DataFrame:
+    """Flag, and optionally remove, invalid positions in a dataframe.
+
+    Positions are invalid if they are exactly (0, 0, 0), fall outside of the map's
+    radar bounds or, when the dataframe has a tick and steamid per row, jump
+    further than `max_units_per_tick` from the player's previous position.
+
+    Args:
+        df (pd.DataFrame): Dataframe with `{prefix}X`, `{prefix}Y`, `{prefix}Z`.
+        map_name (str, optional): Map name to check bounds. Defaults to None.
+        prefix (str, optional): Column prefix, e.g., `attacker_`. Defaults to "".
+        max_units_per_tick (float, optional): Largest valid distance moved per
+            tick. Defaults to MAX_UNITS_PER_TICK.
+        check_teleports (bool, optional): Whether to check for teleports, which
+            only makes sense for dense data like ticks. Defaults to True.
+        fix (bool, optional): Whether to set invalid positions to NaN. Defaults
+            to False.
+
+    Returns:
+        pd.DataFrame: `df` with a `{prefix}position_flag` column, which is the
+            reason a position is invalid or None.
+    """
+    x_col, y_col, z_col = f"{prefix}X", f"{prefix}Y", f"{prefix}Z"
+    flag_col = f"{prefix}position_flag"
+    df[flag_col] = None
+
+    # (0, 0, 0) is what we get for players without a pawn
+    is_zero = (df[x_col] == 0) & (df[y_col] == 0) & (df[z_col] == 0)
+
+    # Outside of the radar bounds
+    is_out_of_bounds = pd.Series(data=False, index=df.index)
+    bounds = get_map_bounds(map_name) if map_name is not None else None
+    if bounds is not None:
+        min_x, max_x, min_y, max_y = bounds
+        is_out_of_bounds = (
+            (df[x_col] < min_x)
+            | (df[x_col] > max_x)
+            | (df[y_col] < min_y)
+            | (df[y_col] > max_y)
+        ) & ~is_zero
+
+    # Teleports between consecutive positions of a player
+    is_teleport = pd.Series(data=False, index=df.index)
+    steamid_col = f"{prefix}steamid"
+    if check_teleports and "tick" in df.columns and steamid_col in df.columns:
+        valid_df = df.loc[~is_zero & ~is_out_of_bounds].sort_values("tick")
+        player_groups = valid_df.groupby(steamid_col)
+        distance = np.sqrt(
+            player_groups[x_col].diff() ** 2
+            + player_groups[y_col].diff() ** 2
+            + player_groups[z_col].diff() ** 2
+        )
+        ticks_elapsed = player_groups["tick"].diff()
+        units_per_tick = distance / ticks_elapsed
+        teleport_index = units_per_tick[units_per_tick > max_units_per_tick].index
+        is_teleport.loc[teleport_index] = True
+
+    df.loc[is_teleport, flag_col] = "teleport"
+    df.loc[is_out_of_bounds, flag_col] = "out_of_bounds"
+    df.loc[is_zero, flag_col] = "zero"
+
+    if fix:
+        df.loc[df[flag_col].notna(), [x_col, y_col, z_col]] = np.nan
+
+    return df
@@ -0,0 +1,114 @@
+"""Module for flagging practice plugin noise in scrim demos."""
+
+import numpy as np
+import pandas as pd
+
+# Competitive servers wait 7 seconds between rounds, while `mp_restartgame`
+# spam from practice plugins restarts rounds about a second after they end
+RAPID_RESTART_SECONDS = 3
+MIN_ROUND_SECONDS = 1
+
+
+def parse_cheats_enabled(
+    rounds_df: pd.DataFrame, events: dict[str, pd.DataFrame]
+) -> pd.Series:
+    """Get whether `sv_cheats` was on at the start of each round.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+
+    Returns:
+        pd.Series: Whether cheats were enabled, for each round.
+    """
+    cheats_enabled = pd.Series(False, index=rounds_df.index)
+    server_cvars = events.get("server_cvar")
+    if server_cvars is None or not {"cvarname", "cvarvalue"}.issubset(
+        server_cvars.columns
+    ):
+        return cheats_enabled
+
+    sv_cheats = server_cvars[server_cvars["cvarname"] == "sv_cheats"].sort_values(
+        "tick"
+    )
+    if sv_cheats.shape[0] == 0:
+        return cheats_enabled
+
+    # The latest change before the round started is the value during the round
+    change_idx = (
+        np.searchsorted(
+            sv_cheats["tick"].to_numpy(),
+            rounds_df["start"].to_numpy(dtype="int64"),
+            side="right",
+        )
+        - 1
+    )
+    values = sv_cheats["cvarvalue"].astype(str).to_numpy()
+    return pd.Series(
+        (change_idx >= 0) & (values[np.maximum(change_idx, 0)] != "0"),
+        index=rounds_df.index,
+    )
+
+
+def flag_junk_rounds(
+    rounds_df: pd.DataFrame,
+    events: dict[str, pd.DataFrame],
+    tick_rate: int = 64,
+    rapid_restart_seconds: float = RAPID_RESTART_SECONDS,
+    min_round_seconds: float = MIN_ROUND_SECONDS,
+) -> pd.DataFrame:
+    """Flag rounds that are practice plugin noise rather than real rounds.
+
+    Scrim servers with practice plugins restart the game over and over, which
+    creates rounds that are never played. A round is junk if the next round
+    starts right after it ends (`is_rapid_restart`), if it ends as soon as
+    freeze time is over (`is_instant_round`) or if `sv_cheats` is on
+    (`is_cheats_enabled`).
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        events (dict[str, pd.DataFrame]): A dictionary of parsed events.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+        rapid_restart_seconds (float, optional): Seconds between the end of a
+            round and the start of the next one under which the round was
+            restarted. Defaults to RAPID_RESTART_SECONDS.
+        min_round_seconds (float, optional): Seconds of play under which a
+            round is instant. Defaults to MIN_ROUND_SECONDS.
+
+    Returns:
+        pd.DataFrame: `rounds_df` with `is_rapid_restart`, `is_instant_round`,
+            `is_cheats_enabled` and `is_junk` columns.
+    """
+    seconds_to_next_start = (
+        rounds_df["start"].shift(-1) - rounds_df["end"]
+    ).astype("float64") / tick_rate
+    rounds_df["is_rapid_restart"] = (
+        seconds_to_next_start < rapid_restart_seconds
+    ).fillna(False)
+
+    play_start = rounds_df["freeze_end"].fillna(rounds_df["start"])
+    seconds_played = (rounds_df["end"] - play_start).astype("float64") / tick_rate
+    rounds_df["is_instant_round"] = (seconds_played < min_round_seconds).fillna(False)
+
+    rounds_df["is_cheats_enabled"] = parse_cheats_enabled(rounds_df, events)
+    rounds_df["is_junk"] = (
+        rounds_df["is_rapid_restart"]
+        | rounds_df["is_instant_round"]
+        | rounds_df["is_cheats_enabled"]
+    )
+    return rounds_df
+
+
+def drop_junk_rounds(rounds_df: pd.DataFrame) -> pd.DataFrame:
+    """Drop the junk rounds and number the remaining rounds from 1.
+
+    Args:
+        rounds_df (pd.DataFrame): The rounds dataframe, flagged with
+            `flag_junk_rounds`.
+
+    Returns:
+        pd.DataFrame: The real rounds.
+    """
+    rounds_df = rounds_df[~rounds_df["is_junk"]].reset_index(drop=True)
+    rounds_df["round"] = rounds_df.index + 1
+    return rounds_df
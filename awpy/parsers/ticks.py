@@ -1,10 +1,34 @@
 """Module for tick parsing functions."""
 
+from typing import Optional, Union
+
 import pandas as pd
 from demoparser2 import DemoParser  # pylint: disable=E0611
 
 from awpy.parsers.utils import parse_col_types
 
+# Delta-encoded ticks keep every player's full state once per interval
+DELTA_KEYFRAME_SECONDS = 10
+DELTA_KEY_COLUMNS = ["tick", "steamid"]
+
+# Player props read around kills, e.g., for supporting teammates, victim
+# positions and equipment and attacker ammo
+KILL_TICK_PROPS = [
+    "X",
+    "Y",
+    "Z",
+    "pitch",
+    "yaw",
+    "team_name",
+    "health",
+    "inventory",
+    "armor_value",
+    "has_helmet",
+    "has_defuser",
+    "active_weapon_ammo",
+    "total_ammo_left",
+]
+
 
 def remove_nonplay_ticks(parsed_df: pd.DataFrame) -> pd.DataFrame:
     """Filter out non-play records from a dataframe.
@@ -68,6 +92,7 @@ def parse_ticks(
     parser: DemoParser,
     player_props: list[str],
     other_props: list[str],
+    ticks: Optional[list[int]] = None,
 ) -> pd.DataFrame:
     """Parse the ticks of the demofile.
 
@@ -75,9 +100,215 @@ def parse_ticks(
         parser (DemoParser): The parser object.
         player_props (list[str]): Player properties to parse.
         other_props (list[str]): World properties to parse.
+        ticks (list[int], optional): Ticks to parse. Defaults to None, which
+            parses every tick.
 
     Returns:
         pd.DataFrame: The ticks for the demofile.
     """
-    ticks_df = parser.parse_ticks(wanted_props=player_props + other_props)
+    if ticks is None:
+        ticks_df = parser.parse_ticks(wanted_props=player_props + other_props)
+    else:
+        ticks_df = parser.parse_ticks(
+            wanted_props=player_props + other_props, ticks=ticks
+        )
     return parse_col_types(remove_nonplay_ticks(ticks_df))
+
+
+def parse_frame_interval(frame_interval: Union[int, str], tick_rate: int = 64) -> int:
+    """Parse a frame interval, given in ticks or in Hz, to ticks.
+
+    Args:
+        frame_interval (Union[int, str]): The interval in ticks (e.g., `16`) or as
+            a frequency (e.g., `"4hz"`).
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        int: The interval in ticks.
+
+    Raises:
+        ValueError: If the frame interval is not a positive number of ticks or Hz.
+    """
+    frame_interval_str = str(frame_interval).strip().lower()
+    try:
+        if frame_interval_str.endswith("hz"):
+            interval_ticks = round(tick_rate / float(frame_interval_str[:-2]))
+        else:
+            interval_ticks = int(frame_interval_str)
+    except (ValueError, ZeroDivisionError) as err:
+        bad_frame_interval_msg = f"Invalid frame interval: {frame_interval}"
+        raise ValueError(bad_frame_interval_msg) from err
+
+    if interval_ticks < 1:
+        bad_frame_interval_msg = f"Frame interval must be positive: {frame_interval}"
+        raise ValueError(bad_frame_interval_msg)
+    return interval_ticks
+
+
+def parse_view_velocity(ticks_df: pd.DataFrame, tick_rate: int = 64) -> pd.DataFrame:
+    """Add each player's continuous yaw and how fast their view turns.
+
+    Yaw wraps around at 180 degrees, so turning past it jumps by 360 degrees.
+    The unwrapped yaw keeps counting instead. Compute this before downsampling,
+    since a player can turn more than 180 degrees between sampled frames.
+
+    Args:
+        ticks_df (pd.DataFrame): Ticks with `tick`, `steamid`, `yaw` and
+            `pitch` columns.
+        tick_rate (int, optional): The tick rate of the server. Defaults to 64.
+
+    Returns:
+        pd.DataFrame: `ticks_df` with `yaw_unwrapped`, and `yaw_velocity` and
+            `pitch_velocity` in degrees per second, which are NaN for each
+            player's first tick.
+    """
+    sorted_df = ticks_df.sort_values(["steamid", "tick"], kind="stable")
+    is_first = sorted_df["steamid"] != sorted_df["steamid"].shift()
+    player_runs = is_first.cumsum()
+
+    # Take the shorter way around between consecutive ticks
+    yaw_step = (sorted_df["yaw"].diff() + 180) % 360 - 180
+    yaw_step = yaw_step.mask(is_first, 0).fillna(0)
+    seconds = (sorted_df["tick"].diff() / tick_rate).mask(is_first)
+
+    ticks_df["yaw_unwrapped"] = (
+        sorted_df["yaw"].groupby(player_runs).transform("first")
+        + yaw_step.groupby(player_runs).cumsum()
+    )
+    ticks_df["yaw_velocity"] = yaw_step.mask(is_first) / seconds
+    ticks_df["pitch_velocity"] = sorted_df["pitch"].diff().mask(is_first) / seconds
+    return ticks_df
+
+
+def downsample_ticks(ticks_df: pd.DataFrame, interval_ticks: int) -> pd.DataFrame:
+    """Keep one recorded tick per interval.
+
+    Rather than keeping every n-th recorded tick, which depends on how often the
+    demo recorded frames, we keep the first recorded tick in each interval so the
+    spacing is the same across demos.
+
+    Args:
+        ticks_df (pd.DataFrame): The parsed ticks.
+        interval_ticks (int): The interval in ticks between kept ticks.
+
+    Returns:
+        pd.DataFrame: The downsampled ticks.
+    """
+    if interval_ticks <= 1:
+        return ticks_df
+
+    unique_ticks = pd.Series(ticks_df["tick"].unique()).sort_values()
+    kept_ticks = unique_ticks.groupby(unique_ticks // interval_ticks).first()
+    return ticks_df[ticks_df["tick"].isin(kept_ticks)]
+
+
+def parse_keyframes(
+    parser: DemoParser, rounds_df: pd.DataFrame, player_props: list[str]
+) -> pd.DataFrame:
+    """Parse player snapshots at the freeze time end and end of every round.
+
+    These are cheap to parse compared to all ticks, and are enough for analyses
+    that only need loadouts and positions at the start and end of rounds.
+
+    Args:
+        parser (DemoParser): The parser object.
+        rounds_df (pd.DataFrame): The rounds dataframe.
+        player_props (list[str]): Player properties to parse.
+
+    Returns:
+        pd.DataFrame: The snapshots, with a `keyframe` column that is either
+            `freeze_end` or `end`.
+    """
+    keyframe_ticks = rounds_df.melt(
+        id_vars="round",
+        value_vars=["freeze_end", "end"],
+        var_name="keyframe",
+        value_name="tick",
+    ).dropna(subset=["tick"])
+    keyframe_ticks["tick"] = keyframe_ticks["tick"].astype(int)
+    if keyframe_ticks.shape[0] == 0:
+        return pd.DataFrame(columns=["round", "keyframe", "tick"])
+
+    keyframes_df = parser.parse_ticks(
+        wanted_props=player_props, ticks=keyframe_ticks["tick"].unique().tolist()
+    )
+    keyframes_df = parse_col_types(keyframes_df).merge(keyframe_ticks, on="tick")
+    return keyframes_df.sort_values(["round", "tick"]).reset_index(drop=True)
+
+
+def parse_kill_ticks(parser: DemoParser, kills_df: pd.DataFrame) -> pd.DataFrame:
+    """Parse the players on every kill tick and on the tick before it.
+
+    Kill details, like supporting teammates and victim equipment, read players
+    around kills, so they share this one pass over the demo.
+
+    Args:
+        parser (DemoParser): The parser object.
+        kills_df (pd.DataFrame): The parsed kills.
+
+    Returns:
+        pd.DataFrame: The KILL_TICK_PROPS of every player, by tick.
+    """
+    kill_ticks = pd.concat([kills_df["tick"], (kills_df["tick"] - 1).clip(lower=0)])
+    if kill_ticks.shape[0] == 0:
+        return pd.DataFrame(columns=["tick", "steamid", *KILL_TICK_PROPS])
+
+    return parse_col_types(
+        parser.parse_ticks(
+            wanted_props=KILL_TICK_PROPS, ticks=kill_ticks.unique().tolist()
+        )
+    )
+
+
+def delta_encode_ticks(ticks_df: pd.DataFrame, keyframe_ticks: int) -> pd.DataFrame:
+    """Blank out player fields that didn't change since the player's last frame.
+
+    Names, teams and most flags rarely change, so the blanks compress to almost
+    nothing. The first frame of each player in every `keyframe_ticks` interval
+    is a keyframe, flagged by `is_keyframe`, which keeps every field so that
+    any interval can be decoded on its own.
+
+    Args:
+        ticks_df (pd.DataFrame): The parsed ticks.
+        keyframe_ticks (int): The interval in ticks between keyframes.
+
+    Returns:
+        pd.DataFrame: The delta-encoded ticks, ordered by player and tick.
+    """
+    ticks_df = ticks_df.sort_values(["steamid", "tick"], kind="stable").reset_index(
+        drop=True
+    )
+    by_player = ticks_df.groupby("steamid", sort=False)
+    interval = ticks_df["tick"] // keyframe_ticks
+    delta_df = ticks_df[DELTA_KEY_COLUMNS].assign(
+        is_keyframe=interval != by_player["tick"].shift() // keyframe_ticks
+    )
+    for col in ticks_df.columns.drop(DELTA_KEY_COLUMNS):
+        values = ticks_df[col].astype(object)
+        is_same = values == by_player[col].shift().astype(object)
+        delta_df[col] = values.where(delta_df["is_keyframe"] | ~is_same, None)
+    return delta_df
+
+
+def delta_decode_ticks(delta_df: pd.DataFrame) -> pd.DataFrame:
+    """Fill in the blanks of delta-encoded ticks from each player's last frame.
+
+    Fields that became missing after a keyframe can't be told apart from
+    unchanged ones, so they take the previous value.
+
+    Args:
+        delta_df (pd.DataFrame): Ticks from `delta_encode_ticks`.
+
+    Returns:
+        pd.DataFrame: The ticks with every field filled in.
+    """
+    delta_df = delta_df.sort_values(["steamid", "tick"], kind="stable")
+    value_cols = delta_df.columns.drop([*DELTA_KEY_COLUMNS, "is_keyframe"])
+    ticks_df = delta_df[DELTA_KEY_COLUMNS].join(
+        delta_df.groupby("steamid", sort=False)[list(value_cols)].ffill()
+    )
+    return (
+        ticks_df.infer_objects()
+        .sort_values(["tick", "steamid"])
+        .reset_index(drop=True)
+    )
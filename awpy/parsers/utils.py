@@ -1,7 +1,10 @@
 """Module for parsing utils."""
 
+import numpy as np
 import pandas as pd
 
+from awpy.data.equipment_data import EQUIPMENT_DATA, KNIFE_PREFIXES
+
 
 def parse_col_types(df: pd.DataFrame) -> pd.DataFrame:
     """Parse the column types of a dataframe.
@@ -17,3 +20,108 @@ def parse_col_types(df: pd.DataFrame) -> pd.DataFrame:
         if "steamid" in col:
             df[col] = df[col].astype(str)
     return df
+
+
+def parse_stance(
+    df: pd.DataFrame,
+    prefix: str = "",
+    crouch_threshold: float = 0.5,
+    airborne_velocity: float = 100.0,
+) -> pd.Series:
+    """Get the stance (standing, crouching or airborne) of a player.
+
+    Players are airborne if their vertical speed is large, since walking up
+    stairs or slopes only moves them up slowly.
+
+    Args:
+        df: A pandas DataFrame with `{prefix}duck_amount` and `{prefix}velocity_Z`.
+        prefix: The column prefix, e.g., `attacker_`. Defaults to "".
+        crouch_threshold: Duck amount from which a player is crouching.
+            Defaults to 0.5.
+        airborne_velocity: Vertical speed from which a player is airborne.
+            Defaults to 100.0.
+
+    Returns:
+        A series of stances.
+    """
+    is_airborne = df[f"{prefix}velocity_Z"].abs() > airborne_velocity
+    is_crouching = df[f"{prefix}duck_amount"] >= crouch_threshold
+    stance = np.select(
+        [is_airborne.to_numpy(dtype=bool), is_crouching.to_numpy(dtype=bool)],
+        ["airborne", "crouching"],
+        "standing",
+    )
+    return pd.Series(stance, index=df.index).where(
+        df[f"{prefix}duck_amount"].notna(), None
+    )
+
+
+def is_known_weapon(weapon: str) -> bool:
+    """Check if a weapon name is in the equipment mapping.
+
+    Args:
+        weapon: A weapon name as reported in game events, e.g., `ak47`
+            or `weapon_ak47`.
+
+    Returns:
+        True if the weapon is known, False otherwise.
+    """
+    weapon = weapon.removeprefix("weapon_")
+    return weapon in EQUIPMENT_DATA or weapon.startswith(KNIFE_PREFIXES)
+
+
+def find_unknown_weapons(df: pd.DataFrame, weapon_col: str = "weapon") -> dict:
+    """Count the weapon names that are not in the equipment mapping.
+
+    Args:
+        df: A pandas DataFrame with a weapon column.
+        weapon_col: The name of the weapon column. Defaults to "weapon".
+
+    Returns:
+        A dictionary of unknown weapon names and their counts.
+    """
+    if df is None or weapon_col not in df.columns:
+        return {}
+
+    weapons = df[weapon_col].dropna().astype(str)
+    weapons = weapons[(weapons != "") & ~weapons.map(is_known_weapon)]
+    return {weapon: int(count) for weapon, count in weapons.value_counts().items()}
+
+
+def get_events_in_range(
+    events: dict[str, pd.DataFrame], start_tick: int, end_tick: int
+) -> pd.DataFrame:
+    """Gather the raw events between two ticks, to debug odd parses.
+
+    Args:
+        events: A dictionary of parsed events.
+        start_tick: The first tick, inclusive.
+        end_tick: The last tick, inclusive.
+
+    Returns:
+        A DataFrame with the `tick`, `event` name and the non-missing `fields`
+        of every event in the range, sorted by tick.
+    """
+    rows = []
+    for event_name, event in events.items():
+        if "tick" not in event.columns:
+            continue
+        in_range = event[event["tick"].between(start_tick, end_tick)]
+        for record in in_range.to_dict("records"):
+            rows.append(
+                {
+                    "tick": int(record["tick"]),
+                    "event": event_name,
+                    "fields": {
+                        key: value
+                        for key, value in record.items()
+                        if key != "tick"
+                        and not (pd.api.types.is_scalar(value) and pd.isna(value))
+                    },
+                }
+            )
+    return (
+        pd.DataFrame(rows, columns=["tick", "event", "fields"])
+        .sort_values(["tick", "event"], kind="stable")
+        .reset_index(drop=True)
+    )
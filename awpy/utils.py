@@ -2,8 +2,13 @@
 
 from typing import Literal
 
+import numpy as np
 import pandas as pd
 
+# 32-bit types that 64-bit columns can be saved as
+FLOAT32_DTYPES = {"float64": "float32", "Float64": "Float32"}
+INT32_DTYPES = {"int64": "int32", "Int64": "Int32"}
+
 
 def apply_round_num(
     rounds_df: pd.DataFrame, df: pd.DataFrame, tick_col: str = "tick"
@@ -37,6 +42,69 @@ def apply_round_num(
     return df
 
 
+def to_camel_case(name: str) -> str:
+    """Convert a snake_case name to camelCase, e.g., `victim_steamid`.
+
+    Args:
+        name (str): The snake_case name.
+
+    Returns:
+        str: The camelCase name, e.g., `victimSteamid`.
+    """
+    first_word, *other_words = name.split("_")
+    return first_word + "".join(word[:1].upper() + word[1:] for word in other_words)
+
+
+def convert_field_case(
+    df: pd.DataFrame, field_case: Literal["snake", "camel"] = "snake"
+) -> pd.DataFrame:
+    """Convert the column names of a dataframe to a naming convention.
+
+    Args:
+        df (pd.DataFrame): Dataframe with snake_case columns, as parsed.
+        field_case (Literal["snake", "camel"], optional): The naming
+            convention. Defaults to "snake", which keeps the columns as is.
+
+    Returns:
+        pd.DataFrame: `df` with renamed columns.
+
+    Raises:
+        ValueError: If the naming convention is unknown.
+    """
+    if field_case == "snake":
+        return df
+    if field_case == "camel":
+        return df.rename(columns=lambda col: to_camel_case(str(col)))
+    unknown_field_case_msg = f"Unknown field case: {field_case}."
+    raise ValueError(unknown_field_case_msg)
+
+
+def downcast_numbers(df: pd.DataFrame) -> pd.DataFrame:
+    """Store 64-bit floats as 32-bit floats, and 64-bit ints as 32-bit ints.
+
+    Demos store positions and angles with less precision than a 32-bit float,
+    so this halves the size of numeric columns without losing any. Int
+    columns are only downcast when every value fits in 32 bits.
+
+    Args:
+        df (pd.DataFrame): The dataframe to downcast.
+
+    Returns:
+        pd.DataFrame: `df` with 32-bit numeric columns.
+    """
+    int32_info = np.iinfo(np.int32)
+    new_dtypes = {}
+    for col, dtype in df.dtypes.items():
+        if str(dtype) in FLOAT32_DTYPES:
+            new_dtypes[col] = FLOAT32_DTYPES[str(dtype)]
+        elif (
+            str(dtype) in INT32_DTYPES
+            and df[col].dropna().between(int32_info.min, int32_info.max).all()
+        ):
+            new_dtypes[col] = INT32_DTYPES[str(dtype)]
+    return df.astype(new_dtypes)
+
+
 def rename_columns_with_affix(
     df: pd.DataFrame,
     old_affix: str,
@@ -2,13 +2,28 @@
 
 import json
 import os
+import threading
 import zipfile
+from http.server import BaseHTTPRequestHandler, HTTPServer
 from pathlib import Path
 
+import pandas as pd
 import pytest
 from click.testing import CliRunner
 
-from awpy.cli import parse
+from awpy.cli import (
+    compare,
+    compare_tables,
+    dump_prices,
+    format_parse_metrics,
+    highlights,
+    info,
+    map_info,
+    parse,
+    places,
+    schema,
+)
+from awpy.parsers.places import load_place_polygons
 
 
 class TestCommandLine:
@@ -50,6 +65,10 @@ class TestCommandLine:
             zipped_files = [Path(file).name for file in zipf.namelist()]
             assert all(Path(file).name in zipped_files for file in expected_files)
 
+            # Parquet is already compressed, so it is stored as is
+            assert zipf.getinfo("kills.data").compress_type == zipfile.ZIP_STORED
+            assert zipf.getinfo("header.json").compress_type == zipfile.ZIP_DEFLATED
+
             # Check if there is an events/ folder and it contains files
             events_files = [
                 file
@@ -62,3 +81,287 @@ class TestCommandLine:
             with zipf.open("header.json") as f:
                 header = json.load(f)
                 assert header["map_name"] == "de_vertigo"
+
+    def test_parse_camel_case(self):
+        """Test that the parse command can write camelCase columns."""
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--field-case",
+                "camel",
+            ],
+        )
+        assert result.exit_code == 0
+
+        with zipfile.ZipFile("spirit-vs-mouz-m1-vertigo.zip", "r") as zipf:
+            with zipf.open("kills.data") as f:
+                kills = pd.read_parquet(f)
+            with zipf.open("header.json") as f:
+                header = json.load(f)
+        assert "victimSteamid" in kills.columns
+        assert "victim_steamid" not in kills.columns
+        assert header["mapName"] == "de_vertigo"
+
+    def test_parse_split_events(self, tmp_path: Path):
+        """Test that the parse command can write one parquet file per table."""
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--split-events",
+                "--outpath",
+                str(tmp_path),
+            ],
+        )
+        assert result.exit_code == 0
+
+        out_dir = tmp_path / "spirit-vs-mouz-m1-vertigo"
+        assert not (tmp_path / "spirit-vs-mouz-m1-vertigo.zip").exists()
+        assert (out_dir / "damages.parquet").exists()
+        assert (out_dir / "events" / "player_death.parquet").exists()
+        assert not (out_dir / "ticks.parquet").exists()
+
+        kills = pd.read_parquet(out_dir / "kills.parquet")
+        assert kills.shape[0] > 0
+        header = json.loads((out_dir / "header.json").read_text())
+        assert header["map_name"] == "de_vertigo"
+
+    def test_parse_partition_by(self, tmp_path: Path):
+        """Test that the parse command can write a Hive-style layout."""
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--partition-by",
+                "map,match",
+                "--outpath",
+                str(tmp_path),
+            ],
+        )
+        assert result.exit_code == 0
+        out_dir = tmp_path / "map=de_vertigo" / "match=spirit-vs-mouz-m1-vertigo"
+        assert (out_dir / "kills.parquet").exists()
+        assert (out_dir / "header.json").exists()
+
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--partition-by",
+                "map,team",
+                "--outpath",
+                str(tmp_path),
+            ],
+        )
+        assert result.exit_code != 0
+        assert isinstance(result.exception, ValueError)
+
+    def test_parse_debug_ticks(self, tmp_path: Path):
+        """Test that the parse command prints the raw events in a tick range."""
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--debug-ticks",
+                "0:100000",
+                "--outpath",
+                str(tmp_path),
+            ],
+        )
+        assert result.exit_code == 0
+        assert "player_death" in result.output
+
+        result = self.runner.invoke(
+            parse, ["tests/spirit-vs-mouz-m1-vertigo.dem", "--debug-ticks", "100"]
+        )
+        assert result.exit_code != 0
+
+    def test_parse_notify_url(self, tmp_path: Path):
+        """Test that a parse summary is POSTed to the notification URL."""
+        received = []
+
+        class NotifyHandler(BaseHTTPRequestHandler):
+            def do_POST(self) -> None:  # noqa: N802
+                content_length = int(self.headers["Content-Length"])
+                received.append(json.loads(self.rfile.read(content_length)))
+                self.send_response(204)
+                self.end_headers()
+
+            def log_message(self, *args: object) -> None:
+                pass
+
+        server = HTTPServer(("127.0.0.1", 0), NotifyHandler)
+        thread = threading.Thread(target=server.handle_request)
+        thread.start()
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--outpath",
+                str(tmp_path),
+                "--notify-url",
+                f"http://127.0.0.1:{server.server_port}",
+            ],
+        )
+        thread.join()
+        server.server_close()
+        assert result.exit_code == 0
+        assert received[0]["status"] == "succeeded"
+        assert received[0]["header"]["map_name"] == "de_vertigo"
+
+        result = self.runner.invoke(
+            parse,
+            ["tests/spirit-vs-mouz-m1-vertigo.dem", "--notify-url", "ftp://host"],
+        )
+        assert result.exit_code != 0
+
+    def test_parse_metrics_file(self, tmp_path: Path):
+        """Test that the parse writes Prometheus metrics."""
+        metrics_path = tmp_path / "awpy.prom"
+        result = self.runner.invoke(
+            parse,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "--noticks",
+                "--outpath",
+                str(tmp_path),
+                "--metrics-file",
+                str(metrics_path),
+            ],
+        )
+        assert result.exit_code == 0
+        metrics = metrics_path.read_text().splitlines()
+        assert "awpy_parse_success 1" in metrics
+        assert "# TYPE awpy_parse_duration_seconds gauge" in metrics
+        assert any(
+            line.startswith('awpy_parse_events{event="player_death"}')
+            for line in metrics
+        )
+
+    def test_format_parse_metrics(self):
+        """Test that failed parses only report failure and the demo size."""
+        metrics = format_parse_metrics(
+            Path("tests/spirit-vs-mouz-m1-vertigo.dem"), {}, succeeded=False
+        ).splitlines()
+        assert "awpy_parse_success 0" in metrics
+        assert [line.split()[0] for line in metrics if not line.startswith("#")] == [
+            "awpy_parse_success",
+            "awpy_demo_size_bytes",
+        ]
+
+    def test_highlights(self, tmp_path: Path):
+        """Test that the highlights command writes an HLAE config."""
+        outpath = tmp_path / "highlights.cfg"
+        result = self.runner.invoke(
+            highlights,
+            ["tests/spirit-vs-mouz-m1-vertigo.dem", "--outpath", str(outpath)],
+        )
+        assert result.exit_code == 0
+        lines = outpath.read_text().splitlines()
+        assert lines[0] == "mirv_cmd clear"
+        assert "spec_player_by_accountid" in lines[1]
+        assert lines[-1].startswith("demo_gototick")
+
+    def test_places(self, tmp_path: Path):
+        """Test that the places command writes polygons that can be loaded."""
+        outpath = tmp_path / "places.json"
+        result = self.runner.invoke(
+            places, ["tests/spirit-vs-mouz-m1-vertigo.dem", "--outpath", str(outpath)]
+        )
+        assert result.exit_code == 0
+        polygons = load_place_polygons(outpath)
+        assert len(polygons) > 0
+
+        result = self.runner.invoke(
+            places, ["tests/spirit-vs-mouz-m1-vertigo.dem", "--geojson"]
+        )
+        assert result.exit_code == 0
+        feature = json.loads(result.output)["features"][0]
+        assert feature["geometry"]["type"] == "Polygon"
+        ring = feature["geometry"]["coordinates"][0]
+        assert ring[0] == ring[-1]
+
+    def test_map_info(self):
+        """Test that the map-info command prints the zones of the map."""
+        result = self.runner.invoke(map_info, ["tests/spirit-vs-mouz-m1-vertigo.dem"])
+        assert result.exit_code == 0
+        map_info_json = json.loads(result.output)
+        assert map_info_json["map_name"] == "de_vertigo"
+        zones = {zone["zone"] for zone in map_info_json["zones"]}
+        assert {"bombsite_a", "bombsite_b"} <= zones
+
+    def test_dump_prices(self):
+        """Test that the dump-prices command prints the price table."""
+        result = self.runner.invoke(dump_prices, ["--game", "csgo"])
+        assert result.exit_code == 0
+        prices = json.loads(result.output)
+        assert prices["ak47"] == 2700
+        assert prices["incgrenade"] == 600
+        assert prices["defuser"] == 400
+
+    def test_info(self):
+        """Test that the info command prints the demo header."""
+        result = self.runner.invoke(info, ["tests/spirit-vs-mouz-m1-vertigo.dem"])
+        assert result.exit_code == 0
+        demo_info = json.loads(result.output)
+        assert demo_info["map_name"] == "de_vertigo"
+        assert "player_death" in demo_info["game_events"]
+
+    def test_schema(self):
+        """Test that the schema command prints the columns of parsed tables."""
+        self.runner.invoke(parse, ["tests/spirit-vs-mouz-m1-vertigo.dem", "--noticks"])
+        result = self.runner.invoke(schema, ["spirit-vs-mouz-m1-vertigo.zip"])
+        assert result.exit_code == 0
+        tables = json.loads(result.output)
+        assert "tick" in tables["kills"]
+        assert "events/player_death" in tables
+
+    def test_compare(self):
+        """Test that comparing a demo to its own parse finds no differences."""
+        self.runner.invoke(
+            parse, ["tests/spirit-vs-mouz-m1-vertigo.dem", "--noticks", "--spawns"]
+        )
+        result = self.runner.invoke(
+            compare,
+            [
+                "tests/spirit-vs-mouz-m1-vertigo.dem",
+                "spirit-vs-mouz-m1-vertigo.zip",
+                "--noticks",
+                "--spawns",
+            ],
+        )
+        assert result.exit_code == 0
+        diff = json.loads(result.output)
+        assert diff["added_tables"] == []
+        assert diff["removed_tables"] == []
+        assert diff["changed_tables"] == {}
+        assert diff["changed_header"] == {}
+
+    def test_compare_tables(self):
+        """Test that table, column, dtype and value changes are reported."""
+        previous_tables = {
+            "kills": pd.DataFrame({"tick": [1, 2], "weapon": ["ak47", "m4a1"]}),
+            "old": pd.DataFrame({"tick": [1]}),
+        }
+        current_tables = {
+            "kills": pd.DataFrame(
+                {"tick": [1.0, 2.0], "weapon": ["ak47", "awp"], "headshot": [1, 0]}
+            ),
+            "new": pd.DataFrame({"tick": [1]}),
+        }
+        diff = compare_tables(previous_tables, current_tables)
+        assert diff["added_tables"] == ["new"]
+        assert diff["removed_tables"] == ["old"]
+        kills_diff = diff["changed_tables"]["kills"]
+        assert kills_diff["rows"] == [2, 2]
+        assert kills_diff["added_columns"] == ["headshot"]
+        assert kills_diff["removed_columns"] == []
+        assert kills_diff["changed_dtypes"] == {"tick": ["int64", "float64"]}
+        assert kills_diff["changed_values"] == {"tick": 2, "weapon": 1}
@@ -1,11 +1,20 @@
 """Test the conversion methods."""
 
 import pandas as pd
+import pytest
 
 from awpy.converters import (
+    HITGROUP_MAPPING,
+    HITGROUP_REVERSE_MAPPING,
+    RANK_REVERSE_MAPPING,
+    invert_mapping,
     map_game_phase,
     map_hitgroup,
+    map_premier_tier,
+    map_rank,
+    map_rank_type,
     map_round_end_reasons,
+    map_weapon_class,
 )
 
 
@@ -80,3 +89,39 @@ class TestConverters:
         )
         result = map_game_phase(series)
         pd.testing.assert_series_equal(result, expected)
+
+    def test_map_rank(self):
+        """Test the map_rank method."""
+        series = pd.Series([0, 1, 18, -1])
+        expected = pd.Series(["Unranked", "Silver I", "The Global Elite", None])
+        result = map_rank(series)
+        pd.testing.assert_series_equal(result, expected)
+
+    def test_map_rank_type(self):
+        """Test the map_rank_type method."""
+        series = pd.Series([7, 11, 12, -1])
+        expected = pd.Series(["wingman", "premier", "competitive", None])
+        result = map_rank_type(series)
+        pd.testing.assert_series_equal(result, expected)
+
+    def test_map_premier_tier(self):
+        """Test the map_premier_tier method."""
+        series = pd.Series([0, 4999, 5000, 17250, 31000])
+        expected = pd.Series([None, "gray", "light_blue", "purple", "gold"])
+        result = map_premier_tier(series)
+        pd.testing.assert_series_equal(result, expected)
+
+    def test_map_weapon_class(self):
+        """Test the map_weapon_class method."""
+        series = pd.Series(["ak47", "weapon_deagle", "knife_karambit", "new_gun"])
+        expected = pd.Series(["rifle", "pistol", "equipment", None])
+        result = map_weapon_class(series)
+        pd.testing.assert_series_equal(result, expected)
+
+    def test_reverse_mappings(self):
+        """Test that reverse mappings round trip."""
+        for index, name in HITGROUP_MAPPING.items():
+            assert HITGROUP_REVERSE_MAPPING[name] == index
+        assert RANK_REVERSE_MAPPING["The Global Elite"] == 18
+        with pytest.raises(ValueError, match="Mapping has duplicate values."):
+            invert_mapping({0: "a", 1: "a"})
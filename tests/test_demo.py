@@ -3,20 +3,62 @@
 import json
 import os
 import zipfile
+from dataclasses import replace
 from pathlib import Path
 
+import pandas as pd
 import pytest
 
-from awpy.demo import Demo
+from awpy.checkpoint import Checkpoint
+from awpy.demo import Demo, DemoOptions, is_remote_path, parse_header
 
+# Parsers of the opt-in tables, by table name
+OPT_IN_PARSERS = {
+    "near_misses": "parse_near_misses",
+    "spawns": "parse_spawns",
+    "survival": "parse_survival",
+    "network": "parse_network",
+    "activity": "parse_activity",
+    "dropped_weapons": "parse_dropped_weapons",
+    "agents": "parse_agents",
+    "zones": "parse_zones",
+    "place_times": "parse_place_times",
+    "bomb_warnings": "parse_bomb_warnings",
+    "buy_log": "parse_buy_log",
+}
 
-@pytest.fixture()
+
+@pytest.fixture(scope="session")
 def parsed_hltv_demo():
-    """Fixture that returns a parsed Demo object."""
-    return Demo(path="tests/spirit-vs-mouz-m1-vertigo.dem")
+    """Fixture that returns a parsed Demo object with every opt-in table."""
+    return Demo(
+        path="tests/spirit-vs-mouz-m1-vertigo.dem",
+        options=DemoOptions(
+            near_misses=True,
+            spawns=True,
+            survival=True,
+            network=True,
+            activity=True,
+            dropped_weapons=True,
+            agents=True,
+            zones=True,
+            place_times=True,
+            bomb_warnings=True,
+            buy_log=True,
+            recording_end="2024-03-01T12:00:00Z",
+        ),
+    )
 
 
 @pytest.fixture()
+def cut_off_checkpoint(tmp_path: Path):
+    """Fixture that returns the path of a checkpoint cut off mid-write."""
+    checkpoint_path = tmp_path / "checkpoint.zip"
+    checkpoint_path.write_bytes(b"PK\x03\x04")
+    return checkpoint_path
+
+
+@pytest.fixture(scope="session")
 def parsed_hltv_demo_no_rounds():
     """Fixture that returns a parsed Demo object with rounds disabled."""
     return Demo(path="tests/spirit-vs-mouz-m1-vertigo.dem", rounds=False)
@@ -40,11 +82,421 @@ class TestDemo:
         assert parsed_hltv_demo_no_rounds.kills is None
         assert parsed_hltv_demo_no_rounds.damages is None
         assert parsed_hltv_demo_no_rounds.bomb is None
+        assert parsed_hltv_demo_no_rounds.defuses is None
+        assert parsed_hltv_demo_no_rounds.economy is None
+        assert parsed_hltv_demo_no_rounds.economy_seeds is None
+        assert parsed_hltv_demo_no_rounds.alive_counts is None
+        assert parsed_hltv_demo_no_rounds.survival is None
+        assert parsed_hltv_demo_no_rounds.grenade_effects is None
+        assert parsed_hltv_demo_no_rounds.agents is None
+        assert parsed_hltv_demo_no_rounds.death_recaps is None
+        assert parsed_hltv_demo_no_rounds.dropped_weapons is None
+        assert parsed_hltv_demo_no_rounds.activity is None
+        assert parsed_hltv_demo_no_rounds.place_times is None
+        assert parsed_hltv_demo_no_rounds.buy_log is None
+        assert parsed_hltv_demo_no_rounds.tick_gaps is None
+        assert parsed_hltv_demo_no_rounds.network is None
         assert parsed_hltv_demo_no_rounds.smokes is None
         assert parsed_hltv_demo_no_rounds.infernos is None
         assert parsed_hltv_demo_no_rounds.weapon_fires is None
+        assert parsed_hltv_demo_no_rounds.utility_timings is None
+        assert parsed_hltv_demo_no_rounds.bursts is None
         assert parsed_hltv_demo_no_rounds.rounds is None
         assert parsed_hltv_demo_no_rounds.grenades is None
+        assert parsed_hltv_demo_no_rounds.blinds is None
+        assert parsed_hltv_demo_no_rounds.scopes is None
+        assert parsed_hltv_demo_no_rounds.kill_contributions is None
+        assert parsed_hltv_demo_no_rounds.chat is None
+        assert parsed_hltv_demo_no_rounds.teams is None
+        assert parsed_hltv_demo_no_rounds.spawns is None
+        assert parsed_hltv_demo_no_rounds.zones is None
+        assert parsed_hltv_demo_no_rounds.executes is None
+        assert parsed_hltv_demo_no_rounds.bomb_warnings is None
+        assert parsed_hltv_demo_no_rounds.keyframes is None
+
+    def test_opt_in_tables(
+        self, parsed_hltv_demo: Demo, monkeypatch: pytest.MonkeyPatch
+    ):
+        """Test that the opt-in tables and wall times are only parsed when asked for."""
+
+        def fail(*_args: object) -> None:
+            opt_in_msg = "Parsed an opt-in table"
+            raise AssertionError(opt_in_msg)
+
+        for parser_name in OPT_IN_PARSERS.values():
+            monkeypatch.setattr(f"awpy.demo.{parser_name}", fail)
+        demo = Demo(path="tests/spirit-vs-mouz-m1-vertigo.dem", ticks=False)
+        for table_name in OPT_IN_PARSERS:
+            assert getattr(demo, table_name) is None
+            assert getattr(parsed_hltv_demo, table_name) is not None
+        assert demo.header["recording_end"] is None
+        assert demo.rounds["start_wall_time"].isna().all()
+        assert "zeus_purchases" not in demo.economy_seeds.columns
+
+    def test_warnings(self, parsed_hltv_demo: Demo):
+        """Test that warnings are counted by type."""
+        assert set(parsed_hltv_demo.warnings) <= {"tick_gaps", "unknown_weapons"}
+        assert parsed_hltv_demo.warnings.get("tick_gaps", 0) == (
+            parsed_hltv_demo.tick_gaps.shape[0]
+        )
+
+    def test_ranks(self, parsed_hltv_demo: Demo):
+        """Test that ranks are parsed with Premier ratings split out."""
+        assert parsed_hltv_demo.ranks is not None
+        assert "premier_rating" in parsed_hltv_demo.ranks.columns
+        assert "rank_name" in parsed_hltv_demo.ranks.columns
+
+    def test_scoreboard(self, parsed_hltv_demo: Demo):
+        """Test that the game's scoreboard has every player's totals."""
+        scoreboard = parsed_hltv_demo.scoreboard
+        assert scoreboard is not None
+        assert scoreboard.shape[0] == 10
+        assert scoreboard["kills"].sum() <= parsed_hltv_demo.kills.shape[0]
+        assert (scoreboard["headshot_kills"] <= scoreboard["kills"]).all()
+
+    def test_convar_changes(self, parsed_hltv_demo: Demo):
+        """Test that each convar row changes the convar's value."""
+        changes = parsed_hltv_demo.convar_changes
+        assert changes is not None
+        assert (changes["old_value"] != changes["new_value"]).all()
+        assert changes["tick"].is_monotonic_increasing
+
+    def test_spectators(self, parsed_hltv_demo: Demo):
+        """Test that spectators are listed apart from the players."""
+        spectators = parsed_hltv_demo.spectators
+        assert spectators is not None
+        assert isinstance(parsed_hltv_demo.header["n_spectators"], int)
+        players = set(parsed_hltv_demo.spawns["steamid"].astype(str))
+        casters = spectators[~spectators["is_gotv"]]
+        assert not set(casters["steamid"]).intersection(
+            parsed_hltv_demo.ranks["steamid"]
+        ) - players
+
+    def test_pre_match(self, parsed_hltv_demo: Demo):
+        """Test that rows before the first round are kept out of the rounds."""
+        first_start = parsed_hltv_demo.rounds["start"].min()
+        assert (parsed_hltv_demo.kills["tick"] >= first_start).all()
+        assert (parsed_hltv_demo.weapon_fires["tick"] >= first_start).all()
+        for df in parsed_hltv_demo.pre_match.values():
+            assert (df["round"] == 0).all()
+
+    def test_time_remaining(self, parsed_hltv_demo: Demo):
+        """Test that events and ticks have a numeric time remaining."""
+        assert "time_remaining" in parsed_hltv_demo.kills.columns
+        assert "time_remaining" in parsed_hltv_demo.ticks.columns
+        assert parsed_hltv_demo.kills["time_remaining"].max() <= 115
+
+    def test_kill_stances(self, parsed_hltv_demo: Demo):
+        """Test that kills have stances without widening the ticks."""
+        assert parsed_hltv_demo.kills["attacker_stance"].notna().any()
+        assert "duck_amount" not in parsed_hltv_demo.ticks.columns
+
+    def test_weapon_fire_inaccuracy(self, parsed_hltv_demo: Demo):
+        """Test that shots have the shooter's inaccuracy props."""
+        weapon_fires = parsed_hltv_demo.weapon_fires
+        assert "player_accuracy_penalty" in weapon_fires.columns
+        assert "player_recoil_index" in weapon_fires.columns
+        assert "player_velocity_modifier" in weapon_fires.columns
+        assert weapon_fires["player_velocity_modifier"].between(0, 1).all()
+        assert "recoil_index" not in parsed_hltv_demo.ticks.columns
+
+    def test_on_round_complete(self):
+        """Test that the round callback gets every round's data."""
+        completed_rounds = {}
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            ticks=False,
+            options=DemoOptions(
+                on_round_complete=lambda round_num, round_data: (
+                    completed_rounds.update({round_num: round_data})
+                )
+            ),
+        )
+        assert list(completed_rounds) == demo.rounds["round"].tolist()
+        first_round = completed_rounds[1]
+        assert first_round["rounds"].shape[0] == 1
+        assert (first_round["kills"]["round"] == 1).all()
+        assert sum(data["kills"].shape[0] for data in completed_rounds.values()) == (
+            demo.kills["round"].isin(demo.rounds["round"]).sum()
+        )
+
+    def test_iter_segments(self, parsed_hltv_demo: Demo):
+        """Test that every round belongs to one map segment."""
+        segments = dict(parsed_hltv_demo.iter_segments())
+        assert len(segments) == parsed_hltv_demo.header["n_map_segments"]
+        assert sum(data["rounds"].shape[0] for data in segments.values()) == (
+            parsed_hltv_demo.rounds.shape[0]
+        )
+
+    def test_checkpoint(self, tmp_path: Path):
+        """Test that a checkpoint is reused by a parse with the same options."""
+        checkpoint_path = tmp_path / "checkpoint.zip"
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            options=DemoOptions(checkpoint=checkpoint_path),
+        )
+        with zipfile.ZipFile(checkpoint_path, "r") as zipf:
+            names = zipf.namelist()
+        assert "events/complete" in names
+        assert "ticks.data" in names
+
+        resumed_demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            options=DemoOptions(checkpoint=checkpoint_path),
+        )
+        assert resumed_demo.kills.shape == demo.kills.shape
+        assert resumed_demo.ticks.shape == demo.ticks.shape
+
+    def test_checkpoint_fingerprint(self, tmp_path: Path):
+        """Test that a checkpoint for other options is started over."""
+        checkpoint_path = tmp_path / "checkpoint.zip"
+        checkpoint = Checkpoint(checkpoint_path, {"round_range": (1, 2)})
+        checkpoint.save("ticks", pd.DataFrame({"tick": [1, 2]}))
+        assert Checkpoint(checkpoint_path, {"round_range": (1, 2)}).has("ticks")
+        assert not Checkpoint(checkpoint_path, {"round_range": (1, 3)}).has("ticks")
+
+    def test_crash_dump(self, tmp_path: Path):
+        """Test that a failed parse writes the parser's state to the crash dump."""
+        crash_dump_path = tmp_path / "crash.json"
+
+        def fail(round_num: int, _round_data: dict) -> None:
+            failed_msg = f"Failed on round {round_num}"
+            raise ValueError(failed_msg)
+
+        with pytest.raises(ValueError, match="Failed on round 1"):
+            Demo(
+                path="tests/spirit-vs-mouz-m1-vertigo.dem",
+                options=DemoOptions(
+                    on_round_complete=fail, crash_dump=crash_dump_path
+                ),
+            )
+        crash_dump = json.loads(crash_dump_path.read_text())
+        assert "Failed on round 1" in crash_dump["error"]
+        assert crash_dump["header"]["map_name"] == "de_vertigo"
+        assert crash_dump["last_round"] > 1
+        assert len(crash_dump["last_events"]) == 100
+        assert crash_dump["last_events"][-1]["tick"] == crash_dump["last_tick"]
+
+    def test_bomb_time_remaining(self, parsed_hltv_demo: Demo):
+        """Test that post-plant events have the time until the bomb explodes."""
+        assert parsed_hltv_demo.header["c4_timer"] == 40
+        kills = parsed_hltv_demo.kills
+        post_plant_kills = kills[kills["ticks_since_bomb_plant"].notna()]
+        assert post_plant_kills["bomb_time_remaining"].between(0, 40).all()
+        assert kills.loc[
+            kills["ticks_since_bomb_plant"].isna(), "bomb_time_remaining"
+        ].isna().all()
+
+    def test_economy_seeds(self, parsed_hltv_demo: Demo):
+        """Test that every round has the money of both teams' players."""
+        seeds = parsed_hltv_demo.economy_seeds
+        assert seeds["round"].nunique() == parsed_hltv_demo.rounds.shape[0]
+        assert set(seeds["team_name"]) == {"CT", "TERRORIST"}
+        assert (seeds.loc[seeds["round"] == 1, "start_money"] == 800).all()
+        assert seeds["loss_streak"].notna().all()
+        assert seeds["zeus_purchases"].notna().all()
+
+    def test_bomb_warnings(self, parsed_hltv_demo: Demo):
+        """Test that bomb warnings are within rounds and have positive durations."""
+        warnings = parsed_hltv_demo.bomb_warnings
+        assert set(warnings["warning"]) <= {"unattended", "left_behind"}
+        assert warnings["round"].isin(parsed_hltv_demo.rounds["round"]).all()
+        assert (warnings["duration_seconds"] > 0).all()
+        assert (warnings["end_tick"] > warnings["start_tick"]).all()
+
+    def test_teams(self, parsed_hltv_demo: Demo):
+        """Test that team metadata is parsed for both sides of every round."""
+        assert parsed_hltv_demo.teams.shape[0] == 2 * parsed_hltv_demo.rounds.shape[0]
+        assert parsed_hltv_demo.teams["team_clan_name"].nunique() == 2
+
+    def test_spawns(self, parsed_hltv_demo: Demo):
+        """Test that every player's spawn is parsed for every round."""
+        spawns = parsed_hltv_demo.spawns
+        assert spawns["round"].nunique() == parsed_hltv_demo.rounds.shape[0]
+        assert (spawns.groupby(["round", "team_name"]).size() == 5).all()
+        assert not spawns.duplicated(["round", "team_name", "spawn_index"]).any()
+
+    def test_place_times(self, parsed_hltv_demo: Demo):
+        """Test that no player spends longer in places than the round lasts."""
+        place_times = parsed_hltv_demo.place_times
+        assert (place_times["seconds"] > 0).all()
+        player_seconds = (
+            place_times.groupby(["round", "steamid"])["seconds"].sum().reset_index()
+        )
+        player_seconds = player_seconds.merge(parsed_hltv_demo.rounds, on="round")
+        round_seconds = (player_seconds["end"] - player_seconds["freeze_end"]) / 64
+        assert (player_seconds["seconds"] <= round_seconds + 1).all()
+
+    def test_round_summaries(self, parsed_hltv_demo: Demo):
+        """Test that every round has a summary that starts with the winner."""
+        rounds = parsed_hltv_demo.rounds
+        assert rounds["summary_text"].str.match(r"^(CT|T) win by ").all()
+        assert rounds["summary_text"].str.contains("opening kill").all()
+
+    def test_executes(self, parsed_hltv_demo: Demo):
+        """Test that executes are onto a site and have enough utility."""
+        executes = parsed_hltv_demo.executes
+        assert executes["site"].isin(["A", "B"]).all()
+        assert (
+            executes[["n_smokes", "n_flashes", "n_molotovs"]].sum(axis=1) >= 3
+        ).all()
+        assert (executes["start_tick"] <= executes["end_tick"]).all()
+
+    def test_zones(self, parsed_hltv_demo: Demo):
+        """Test that both bombsites and buy zones are bounded."""
+        zones = parsed_hltv_demo.zones.set_index("zone")
+        assert set(zones.index) == {
+            "bombsite_a",
+            "bombsite_b",
+            "ct_buyzone",
+            "t_buyzone",
+        }
+        assert (zones["min_X"] <= zones["max_X"]).all()
+        assert (zones["n_positions"] > 0).all()
+
+    def test_team_keys(self, parsed_hltv_demo: Demo):
+        """Test that the two teams are keyed by roster across both halves."""
+        rounds = parsed_hltv_demo.rounds
+        assert rounds["ct_team_key"].notna().all()
+        assert (rounds["ct_team_key"] != rounds["t_team_key"]).all()
+        assert parsed_hltv_demo.team_keys["team_key"].nunique() == 2
+        assert "attacker_team_key" in parsed_hltv_demo.kills.columns
+        assert "team_key" in parsed_hltv_demo.ticks.columns
+
+    def test_bomb_state(self, parsed_hltv_demo: Demo):
+        """Test that the ticks know who carries the bomb and if it is planted."""
+        ticks = parsed_hltv_demo.ticks
+        assert {"carried", "planted"}.issubset(set(ticks["bomb_state"].dropna()))
+        carried = ticks[ticks["bomb_state"] == "carried"]
+        assert carried["bomb_carrier_steamid"].notna().all()
+        assert (ticks["is_bomb_ticking"] == (ticks["bomb_state"] == "planted")).all()
+
+    def test_tick_rates(self, parsed_hltv_demo: Demo):
+        """Test that the server tick rate and demo frame rate are in the header."""
+        assert parsed_hltv_demo.header["tick_rate"] == 64
+        assert parsed_hltv_demo.header["game"] == "cs2"
+        assert parsed_hltv_demo.header["frame_rate"] <= 64
+
+    def test_parse_header_versions(self):
+        """Test that the demo and game versions are parsed from the header."""
+        header = parse_header(
+            {
+                "demo_file_stamp": "PBDEMS2\x00",
+                "network_protocol": "13992",
+                "allow_clientside_entities": "true",
+            }
+        )
+        assert header["demo_protocol"] == "PBDEMS2"
+        assert header["network_protocol"] == 13992
+        assert "build_number" not in header
+        assert header["allow_clientside_entities"] is True
+        assert parse_header({})["network_protocol"] is None
+
+    def test_wall_times(self, parsed_hltv_demo: Demo):
+        """Test that rounds are aligned with the recording time."""
+        recording_end = pd.Timestamp("2024-03-01T12:00:00Z")
+        assert pd.Timestamp(parsed_hltv_demo.header["recording_end"]) == recording_end
+        assert (parsed_hltv_demo.rounds["end_wall_time"] <= recording_end).all()
+        assert parsed_hltv_demo.rounds["start_wall_time"].is_monotonic_increasing
+
+    def test_timing(self, parsed_hltv_demo: Demo):
+        """Test that the tick timing diagnostics are filled in from the ticks."""
+        timing = parsed_hltv_demo.timing
+        assert timing["n_frames"] > 0
+        assert timing["max_drift_ticks"] >= 0
+        assert 0 <= timing["irregular_frame_share"] <= 1
+
+    def test_parse_stats(self, parsed_hltv_demo: Demo):
+        """Test that parse statistics are collected."""
+        parse_stats = parsed_hltv_demo.parse_stats
+        assert parse_stats["wall_time"] > 0
+        assert parse_stats["ticks_per_second"] > 0
+        assert parse_stats["event_counts"]["player_death"] > 0
+        assert parse_stats["recovered_errors"] == 0
+
+    def test_recovered_errors(self, cut_off_checkpoint: Path):
+        """Test that errors the parse recovers from are counted."""
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            ticks=False,
+            options=DemoOptions(checkpoint=cut_off_checkpoint),
+        )
+        assert demo.parse_stats["recovered_errors"] == 1
+        assert demo.checkpoint.has("grenades")
+
+    def test_workers(self, parsed_hltv_demo: Demo):
+        """Test that parsing in several processes gives the same output."""
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            options=replace(parsed_hltv_demo.options, workers=2),
+        )
+        assert demo.ticks.shape == parsed_hltv_demo.ticks.shape
+        assert demo.grenades.shape == parsed_hltv_demo.grenades.shape
+        assert demo.scopes.shape == parsed_hltv_demo.scopes.shape
+        assert demo.kills.equals(parsed_hltv_demo.kills)
+
+    def test_round_range(self):
+        """Test that only the requested rounds are kept."""
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            options=DemoOptions(round_range="5-7"),
+        )
+        assert demo.rounds["round"].tolist() == [5, 6, 7]
+        assert set(demo.kills["round"].unique()) <= {5, 6, 7}
+        assert demo.ticks["tick"].min() >= demo.rounds["start"].min()
+        assert demo.ticks["tick"].max() <= demo.rounds["official_end"].max()
+
+    def test_players(self, parsed_hltv_demo: Demo):
+        """Test that only events and ticks with the requested players are kept."""
+        steamid = parsed_hltv_demo.kills["attacker_steamid"].iloc[0]
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            options=DemoOptions(players=[steamid]),
+        )
+        assert (
+            (demo.kills["attacker_steamid"] == steamid)
+            | (demo.kills["victim_steamid"] == steamid)
+            | (demo.kills["assister_steamid"] == steamid)
+        ).all()
+        assert set(demo.ticks["steamid"].unique()) == {steamid}
+        assert demo.rounds.shape == parsed_hltv_demo.rounds.shape
+
+    def test_anonymize(self):
+        """Test that player identities are replaced in every dataframe."""
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            ticks=False,
+            options=DemoOptions(anonymize=True, salt="awpy"),
+        )
+        assert demo.kills["attacker_name"].dropna().str.startswith("Player_").all()
+        assert set(demo.kills["attacker_steamid"].dropna()) <= set(
+            demo.keyframes["steamid"]
+        )
+        assert demo.events["player_death"]["attacker_name"].str.startswith(
+            "Player_"
+        ).all()
+
+    def test_redact(self, tmp_path: Path):
+        """Test that a redaction policy is applied to the whole parse."""
+        policy_path = tmp_path / "policy.json"
+        policy_path.write_text(json.dumps({"chat": "drop", "salt": "awpy"}))
+        demo = Demo(
+            path="tests/spirit-vs-mouz-m1-vertigo.dem",
+            ticks=False,
+            options=DemoOptions(redact=policy_path),
+        )
+        assert demo.kills["attacker_name"].dropna().str.startswith("Player_").all()
+        assert demo.chat.shape[0] == 0
+        assert demo.header["server_name"] is None
+        assert demo.header["map_name"] == "de_vertigo"
+
+    def test_keyframes(self):
+        """Test that keyframes are parsed even when ticks are not."""
+        demo = Demo(path="tests/spirit-vs-mouz-m1-vertigo.dem", ticks=False)
+        assert demo.ticks is None
+        assert set(demo.keyframes["keyframe"].unique()) == {"freeze_end", "end"}
+        assert demo.keyframes["round"].nunique() == demo.rounds.shape[0]
+        assert (demo.keyframes["equipment_value"] >= 0).all()
 
     def test_compress(self, parsed_hltv_demo: Demo):
         """Test that the demo is zipped."""
@@ -65,7 +517,12 @@ class TestDemo:
                 "rounds.data",
                 "grenades.data",
                 "ticks.data",
+                "team_shapes.data",
+                "ranks.data",
                 "header.json",
+                "warnings.json",
+                "parse_stats.json",
+                "timing.json",
             ]
             zipped_files = [Path(file).name for file in zipf.namelist()]
             assert all(Path(file).name in zipped_files for file in expected_files)
@@ -110,3 +567,10 @@ class TestDemo:
             with zipf.open("header.json") as f:
                 header = json.load(f)
                 assert header["map_name"] == "de_vertigo"
+
+    def test_remote_outpath(self):
+        """Test that only cloud storage URLs are remote outputs."""
+        assert is_remote_path("s3://bucket/demos")
+        assert is_remote_path("gs://bucket/demos")
+        assert not is_remote_path(Path("s3:/bucket/demos"))
+        assert not is_remote_path("demos")
@@ -1,12 +1,128 @@
 """Test the parser methods."""
 
+import json
+from pathlib import Path
+
+import numpy as np
 import pandas as pd
 import pytest
 from demoparser2 import DemoParser
 
-from awpy.parsers.events import parse_damages, parse_kills
-from awpy.parsers.rounds import parse_rounds
-from awpy.parsers.ticks import remove_nonplay_ticks
+from awpy.parsers.anonymize import anonymize_players, hash_steamid
+from awpy.parsers.chat import classify_chat, parse_admin_events
+from awpy.parsers.clock import (
+    parse_c4_timer,
+    parse_phases,
+    parse_tick_gaps,
+    parse_times,
+    parse_timing,
+    parse_wall_times,
+)
+from awpy.parsers.convars import parse_convar_changes
+from awpy.parsers.economy import (
+    build_buy_log,
+    build_economy_seeds,
+    forecast_economy,
+    get_economy_settings,
+    get_game_version,
+    get_half_start_money,
+    get_prices,
+    parse_equipment_values,
+    parse_victim_equipment,
+    parse_zeus_purchases,
+)
+from awpy.parsers.events import (
+    build_bomb_drops,
+    build_bomb_states,
+    build_dropped_weapons,
+    find_bomb_warnings,
+    label_inferno_grenades,
+    parse_attacker_ammo,
+    parse_blinds,
+    parse_bomb_markers,
+    parse_bomb_state,
+    parse_bursts,
+    parse_damages,
+    parse_death_recaps,
+    parse_defuse_progress,
+    parse_defuses,
+    parse_grenade_effects,
+    parse_kill_contributions,
+    parse_kills,
+    parse_melee_kills,
+    parse_scopes,
+    parse_utility_timings,
+)
+from awpy.parsers.executes import find_executes
+from awpy.parsers.highlights import parse_kill_cameras, to_hlae_script
+from awpy.parsers.places import (
+    extract_place_polygons,
+    fill_place_names,
+    load_place_polygons,
+    summarize_place_times,
+    summarize_zones,
+    to_geojson,
+    to_place_json,
+)
+from awpy.parsers.players import (
+    apply_team_keys,
+    get_spectating_steamids,
+    parse_agents,
+    parse_alive_counts,
+    parse_kill_advantages,
+    parse_network,
+    parse_spawns,
+    parse_spectators,
+    parse_survival,
+    parse_team_keys,
+    summarize_activity,
+)
+from awpy.parsers.positions import (
+    find_near_misses,
+    parse_crosshair_offsets,
+    parse_line_through_smoke,
+    parse_nearest_players,
+    parse_smoke_places,
+    parse_supporting_teammates,
+    parse_team_shapes,
+    parse_victim_positions,
+    parse_view_rays,
+)
+from awpy.parsers.redact import (
+    REDACTED_TEXT,
+    load_redaction_policy,
+    redact_chat,
+    redact_header,
+)
+from awpy.parsers.rounds import (
+    get_tick_window,
+    parse_map_segments,
+    parse_restart_delays,
+    parse_round_range,
+    parse_round_summaries,
+    parse_rounds,
+    parse_tick_range,
+    parse_win_streaks,
+)
+from awpy.parsers.sanitize import sanitize_positions
+from awpy.parsers.scrim import drop_junk_rounds, flag_junk_rounds
+from awpy.parsers.ticks import (
+    KILL_TICK_PROPS,
+    delta_decode_ticks,
+    delta_encode_ticks,
+    downsample_ticks,
+    parse_frame_interval,
+    parse_kill_ticks,
+    parse_view_velocity,
+    remove_nonplay_ticks,
+)
+from awpy.parsers.utils import find_unknown_weapons, get_events_in_range
+from awpy.utils import (
+    apply_round_num,
+    convert_field_case,
+    downcast_numbers,
+    to_camel_case,
+)
 
 
 @pytest.fixture(scope="class")
@@ -62,6 +178,8 @@ def hltv_events() -> dict[str, pd.DataFrame]:
                 "yaw",
                 "team_name",
                 "team_clan_name",
+                "duck_amount",
+                "velocity_Z",
             ],
             other=[
                 # Bomb
@@ -110,6 +228,8 @@ def faceit_events() -> dict[str, pd.DataFrame]:
                 "yaw",
                 "team_name",
                 "team_clan_name",
+                "duck_amount",
+                "velocity_Z",
             ],
             other=[
                 # Bomb
@@ -153,6 +273,70 @@ def parsed_state() -> pd.DataFrame:
     return pd.DataFrame(data, columns=columns)
 
 
+@pytest.fixture(scope="class")
+def defuse_events() -> dict[str, pd.DataFrame]:
+    """Creates mock events for a killed defuse and a completed defuse."""
+    state = {
+        "is_freeze_period": False,
+        "is_warmup_period": False,
+        "is_terrorist_timeout": False,
+        "is_ct_timeout": False,
+        "is_technical_timeout": False,
+        "is_waiting_for_resume": False,
+        "is_match_started": True,
+        "game_phase": 2,
+    }
+    begin_defuse = pd.DataFrame(
+        [
+            {**state, "tick": 100, "user_name": "ct1", "user_steamid": 1},
+            {**state, "tick": 500, "user_name": "ct2", "user_steamid": 2},
+        ]
+    )
+    begin_defuse["haskit"] = [False, True]
+    return {
+        "bomb_begindefuse": begin_defuse,
+        "bomb_defused": pd.DataFrame({"tick": [820], "user_steamid": [2]}),
+        "player_death": pd.DataFrame({"tick": [420], "user_steamid": [1]}),
+        "player_hurt": pd.DataFrame(
+            {
+                "tick": [50, 200, 400],
+                "user_steamid": [1, 1, 1],
+                "dmg_health": [10, 27, 100],
+            }
+        ),
+    }
+
+
+@pytest.fixture(scope="class")
+def blind_events() -> dict[str, pd.DataFrame]:
+    """Creates mock player_blind events with overlapping flashes."""
+    columns = [
+        "is_freeze_period",
+        "is_warmup_period",
+        "is_terrorist_timeout",
+        "is_ct_timeout",
+        "is_technical_timeout",
+        "is_waiting_for_resume",
+        "is_match_started",
+        "game_phase",
+        "tick",
+        "blind_duration",
+        "user_name",
+        "user_steamid",
+        "user_team_name",
+        "attacker_name",
+        "attacker_steamid",
+        "attacker_team_name",
+    ]
+    state = [False, False, False, False, False, False, True, 2]
+    data = [
+        [*state, 100, 2.0, "victim", 1, "CT", "flasher1", 2, "TERRORIST"],
+        [*state, 150, 3.0, "victim", 1, "CT", "flasher2", 3, "TERRORIST"],
+        [*state, 1000, 1.0, "victim", 1, "CT", "flasher1", 2, "TERRORIST"],
+    ]
+    return {"player_blind": pd.DataFrame(data, columns=columns)}
+
+
 class TestParsers:
     """Tests parser methods."""
 
@@ -170,6 +354,1634 @@ class TestParsers:
         assert "event1" in filtered_df["other_data"].to_numpy()
         assert "event2" in filtered_df["other_data"].to_numpy()
 
+    def test_convert_field_case(self):
+        """Tests that snake_case columns can be renamed to camelCase."""
+        assert to_camel_case("victim_steamid") == "victimSteamid"
+        assert to_camel_case("victim_X") == "victimX"
+        assert to_camel_case("tick") == "tick"
+        df = pd.DataFrame({"attacker_name": ["a"], "n_shots": [1]})
+        assert convert_field_case(df).columns.tolist() == ["attacker_name", "n_shots"]
+        assert convert_field_case(df, "camel").columns.tolist() == [
+            "attackerName",
+            "nShots",
+        ]
+        with pytest.raises(ValueError, match="Unknown field case"):
+            convert_field_case(df, "kebab")
+
+    def test_downcast_numbers(self):
+        """Tests that numbers are saved as 32-bit types when they fit."""
+        df = pd.DataFrame(
+            {
+                "X": [1.5, -2.25],
+                "tick": [0, 128],
+                "steamid_int": [76561198000000000, 76561198000000001],
+                "end_tick": pd.array([1, None], dtype="Int64"),
+                "name": ["a", "b"],
+            }
+        )
+        downcast = downcast_numbers(df)
+        assert downcast.dtypes.astype(str).tolist() == [
+            "float32",
+            "int32",
+            "int64",
+            "Int32",
+            "object",
+        ]
+        assert downcast["X"].tolist() == [1.5, -2.25]
+
+    def test_find_unknown_weapons(self):
+        """Tests that we count weapons missing from the equipment mapping."""
+        weapons_df = pd.DataFrame(
+            {
+                "weapon": [
+                    "ak47",
+                    "weapon_awp",
+                    "knife_karambit",
+                    "new_gun",
+                    "new_gun",
+                    None,
+                ]
+            }
+        )
+        assert find_unknown_weapons(weapons_df) == {"new_gun": 2}
+        assert find_unknown_weapons(pd.DataFrame()) == {}
+
+    def test_classify_chat(self):
+        """Tests that chat messages are classified by sender."""
+        chat = pd.DataFrame(
+            {
+                "sender_steamid": [None, 1, 2, 3, 4],
+                "sender_team_name": [None, "CT", "TERRORIST", "SPECTATOR", "SPECTATOR"],
+                "sender_is_alive": [None, True, False, False, False],
+                "coaching_team": [None, None, None, 3, None],
+            }
+        )
+        chat = classify_chat(chat)
+        assert chat["chat_type"].tolist() == [
+            "server",
+            "player",
+            "player",
+            "coach",
+            "spectator",
+        ]
+        assert chat["sender_team_name"].tolist()[1:4] == ["CT", "TERRORIST", "CT"]
+        assert chat["is_dead_chat"].tolist() == [False, False, True, False, False]
+
+    def test_parse_admin_events(self):
+        """Tests that bot commands are parsed from chat messages."""
+        chat = pd.DataFrame(
+            {
+                "tick": [1, 2, 3, 4],
+                "message": [".ready", "!PAUSE now", "gl hf", ".unknown"],
+                "sender_name": ["a", "b", "c", "d"],
+                "sender_steamid": ["1", "2", "3", "4"],
+                "sender_team_name": ["CT", "TERRORIST", "CT", "CT"],
+            }
+        )
+        admin_events = parse_admin_events(chat)
+        assert admin_events["action"].tolist() == ["ready", "pause"]
+        assert admin_events["args"].tolist() == ["", "now"]
+        assert parse_admin_events(chat.iloc[0:0]).shape[0] == 0
+
+    def test_parse_kill_contributions(self):
+        """Tests that every damage dealer is credited on a kill."""
+        kills = pd.DataFrame(
+            {
+                "kill_feed_index": [0],
+                "tick": [100],
+                "round": [1],
+                "victim_name": ["v"],
+                "victim_steamid": ["1"],
+                "attacker_name": ["a"],
+                "attacker_steamid": ["2"],
+                "attacker_team_name": ["CT"],
+                "assister_name": ["b"],
+                "assister_steamid": ["3"],
+                "assister_team_name": ["CT"],
+                "assistedflash": [True],
+            }
+        )
+        damages = pd.DataFrame(
+            {
+                "tick": [10, 50, 90, 100, 200],
+                "round": [1, 1, 1, 1, 1],
+                "victim_steamid": ["1", "1", "1", "1", "1"],
+                "attacker_name": ["c", "a", "c", "a", "d"],
+                "attacker_steamid": ["4", "2", "4", "2", "5"],
+                "attacker_team_name": ["CT", "CT", "CT", "CT", "CT"],
+                "dmg_health_real": [10, 27, 20, 43, 5],
+            }
+        )
+        contributions = parse_kill_contributions(kills, damages)
+        assert contributions["contributor_name"].tolist() == ["a", "b", "c"]
+        assert contributions["role"].tolist() == [
+            "attacker",
+            "flash_assister",
+            "damage",
+        ]
+        assert contributions["damage"].tolist() == [70, 0, 30]
+
+    def test_label_inferno_grenades(self):
+        """Tests that infernos take the type of their thrower's last throw."""
+        infernos = pd.DataFrame(
+            {
+                "entity_id": [1, 2, 3],
+                "start_tick": [200, 150, 300],
+                "thrower_steamid": ["1", "2", "3"],
+            }
+        )
+        weapon_fires = pd.DataFrame(
+            {
+                "tick": [50, 100, 120, 250],
+                "user_steamid": ["1", "1", "2", "1"],
+                "weapon": [
+                    "weapon_incgrenade",
+                    "weapon_molotov",
+                    "weapon_incgrenade",
+                    "weapon_ak47",
+                ],
+            }
+        )
+        infernos = label_inferno_grenades(infernos, weapon_fires)
+        assert infernos["entity_id"].tolist() == [1, 2, 3]
+        assert infernos["grenade_type"].tolist()[:2] == ["molotov", "incgrenade"]
+        assert pd.isna(infernos["grenade_type"].iloc[2])
+        assert label_inferno_grenades(infernos, None)["grenade_type"].isna().all()
+
+    def test_parse_grenade_effects(self):
+        """Tests that blinds and damage are credited to the right grenade."""
+        events = {
+            "flashbang_detonate": pd.DataFrame(
+                {
+                    "entityid": [10],
+                    "tick": [100],
+                    "user_name": ["a"],
+                    "user_steamid": ["1"],
+                    "user_team_name": ["CT"],
+                }
+            ),
+            "hegrenade_detonate": pd.DataFrame(
+                {
+                    "entityid": [11],
+                    "tick": [200],
+                    "user_name": ["a"],
+                    "user_steamid": ["1"],
+                    "user_team_name": ["CT"],
+                }
+            ),
+            "player_blind": pd.DataFrame(
+                {
+                    "tick": [100, 100, 100],
+                    "user_steamid": ["2", "3", "1"],
+                    "user_team_name": ["TERRORIST", "CT", "CT"],
+                    "attacker_steamid": ["1", "1", "1"],
+                    "attacker_team_name": ["CT", "CT", "CT"],
+                }
+            ),
+        }
+        damages = pd.DataFrame(
+            {
+                "tick": [200, 200, 350, 500],
+                "weapon": ["hegrenade", "hegrenade", "inferno", "inferno"],
+                "attacker_steamid": ["1", "1", "4", "4"],
+                "attacker_team_name": ["CT", "CT", "TERRORIST", "TERRORIST"],
+                "victim_steamid": ["2", "5", "6", "6"],
+                "victim_team_name": ["TERRORIST", "TERRORIST", "CT", "CT"],
+                "dmg_health_real": [50, 20, 8, 8],
+            }
+        )
+        infernos = pd.DataFrame(
+            {
+                "entity_id": [12],
+                "start_tick": [300],
+                "end_tick": [400],
+                "thrower_name": ["d"],
+                "thrower_steamid": ["4"],
+                "thrower_team_name": ["TERRORIST"],
+                "grenade_type": ["molotov"],
+            }
+        )
+        effects = parse_grenade_effects(events, damages, infernos)
+        assert effects["grenade_type"].tolist() == ["flashbang", "hegrenade", "molotov"]
+        assert effects["enemies_blinded"].tolist() == [["2"], [], []]
+        assert effects["teammates_blinded"].tolist() == [["3"], [], []]
+        assert effects["n_enemies_damaged"].tolist() == [0, 2, 1]
+        assert effects["enemy_damage"].tolist() == [0, 70, 8]
+        assert effects["team_damage"].tolist() == [0, 0, 0]
+
+    def test_parse_death_recaps(self):
+        """Tests that each death lists the hits taken during that life."""
+        kills = pd.DataFrame(
+            {
+                "kill_feed_index": [0, 1],
+                "tick": [100, 300],
+                "round": [1, 2],
+                "victim_name": ["v", "v"],
+                "victim_steamid": ["1", "1"],
+            }
+        )
+        damages = pd.DataFrame(
+            {
+                "tick": [90, 50, 120, 250, 300],
+                "round": [1, 1, 1, 2, 2],
+                "victim_name": ["v", "v", "v", "v", "v"],
+                "victim_steamid": ["1", "1", "1", "1", "1"],
+                "attacker_name": ["a", "b", "c", "a", "a"],
+                "attacker_steamid": ["2", "3", "4", "2", "2"],
+                "attacker_team_name": ["CT", "CT", "CT", "CT", "CT"],
+                "weapon": ["ak47", "glock", "m4a1", "awp", "awp"],
+                "hitgroup": ["head", "chest", "legs", "chest", "head"],
+                "dmg_health_real": [70, 30, 5, 60, 40],
+                "dmg_armor_real": [0, 10, 0, 5, 0],
+            }
+        )
+        recaps = parse_death_recaps(kills, damages)
+        assert recaps["kill_feed_index"].tolist() == [0, 0, 1, 1]
+        assert recaps["attacker_name"].tolist() == ["b", "a", "a", "a"]
+        assert recaps["damage"].tolist() == [30, 70, 60, 40]
+        assert recaps["armor_damage"].tolist() == [10, 0, 5, 0]
+        assert recaps["death_tick"].tolist() == [100, 100, 300, 300]
+        assert parse_death_recaps(kills, damages.iloc[0:0]).shape[0] == 0
+
+    def test_anonymize_players(self):
+        """Tests that players get the same alias in every column."""
+        kills = pd.DataFrame(
+            {
+                "attacker_name": ["a", "b"],
+                "attacker_steamid": ["1", "2"],
+                "victim_name": ["b", "a"],
+                "victim_steamid": ["2", "1"],
+                "assister_name": [None, "c"],
+                "assister_steamid": ["None", "3"],
+            }
+        )
+        kills = anonymize_players(kills, salt="salt")
+        assert kills["attacker_steamid"][0] == kills["victim_steamid"][1]
+        assert kills["attacker_name"][0] == kills["victim_name"][1]
+        assert kills["attacker_steamid"][0] == hash_steamid("1", "salt")
+        assert kills["attacker_steamid"][0] != hash_steamid("1", "pepper")
+        assert kills["attacker_name"][0].startswith("Player_")
+        assert kills["assister_name"][0] is None
+
+    def test_redaction_policy(self, tmp_path: Path):
+        """Tests that redaction policies fill in defaults and are validated."""
+        assert load_redaction_policy()["chat"] == "drop"
+        policy_path = tmp_path / "policy.json"
+        policy_path.write_text(json.dumps({"chat": "redact", "salt": "org"}))
+        policy = load_redaction_policy(policy_path)
+        assert policy["chat"] == "redact"
+        assert policy["salt"] == "org"
+        assert policy["anonymize"]
+
+        policy_path.write_text(json.dumps({"chat": "blur"}))
+        with pytest.raises(ValueError, match="chat mode"):
+            load_redaction_policy(policy_path)
+        policy_path.write_text(json.dumps({"voice": "drop"}))
+        with pytest.raises(ValueError, match="Unknown redaction policy keys"):
+            load_redaction_policy(policy_path)
+
+    def test_redact_chat(self):
+        """Tests that chat text is redacted or dropped."""
+        chat = pd.DataFrame({"tick": [1, 2], "message": ["gg", None]})
+        assert redact_chat(chat, "keep")["message"].tolist() == ["gg", None]
+        assert redact_chat(chat, "redact")["message"].tolist() == [REDACTED_TEXT, None]
+        assert redact_chat(chat, "drop").shape[0] == 0
+        assert list(redact_chat(chat, "drop").columns) == ["tick", "message"]
+        assert chat["message"][0] == "gg"
+
+        header = redact_header(
+            {"map_name": "de_dust2", "server_name": "x"}, ["server_name"]
+        )
+        assert header == {"map_name": "de_dust2", "server_name": None}
+
+    def test_parse_blinds(self, blind_events: dict[str, pd.DataFrame]):
+        """Tests that overlapping flashes are merged into one interval."""
+        blinds = parse_blinds(blind_events)
+        assert blinds["start_tick"].tolist() == [100, 1000]
+        assert blinds["end_tick"].tolist() == [342, 1064]
+        assert blinds["n_flashes"].tolist() == [2, 1]
+        assert blinds["flasher_name"].tolist() == ["flasher2", "flasher1"]
+
+    def test_parse_bomb_markers(self):
+        """Tests that warnings are only added while the bomb is ticking."""
+        events = {
+            "bomb_planted": pd.DataFrame(
+                {"tick": [1000, 10000], "user_name": ["a", "b"], "user_steamid": [1, 2]}
+            ),
+            "bomb_beep": pd.DataFrame({"tick": [1064]}),
+            "bomb_defused": pd.DataFrame(
+                {"tick": [11500], "user_name": ["c"], "user_steamid": [3]}
+            ),
+            "bomb_exploded": pd.DataFrame({"tick": [3560]}),
+        }
+        markers = parse_bomb_markers(events, tick_rate=64, c4_timer=40)
+        assert markers["tick"].tolist() == [1000, 1064, 2920, 3240, 3560, 10000, 11500]
+        assert markers["marker"].tolist() == [
+            "plant",
+            "beep",
+            "ten_second_warning",
+            "five_second_warning",
+            "exploded",
+            "plant",
+            "defused",
+        ]
+        assert parse_bomb_markers({}).shape[0] == 0
+
+    def test_parse_defuses(self, defuse_events: dict[str, pd.DataFrame]):
+        """Tests that defuse attempts are matched to how they ended."""
+        defuses = parse_defuses(defuse_events)
+        assert defuses["end_tick"].tolist() == [420, 820]
+        assert defuses["outcome"].tolist() == ["killed", "defused"]
+        assert defuses["is_interrupted"].tolist() == [True, False]
+        assert defuses["required_seconds"].tolist() == [10, 5]
+        assert defuses["progress"].tolist() == [0.5, 1.0]
+        assert defuses["n_hits_taken"].tolist() == [2, 0]
+        assert defuses["damage_taken"].tolist() == [127, 0]
+
+    def test_parse_defuse_progress(self, defuse_events: dict[str, pd.DataFrame]):
+        """Tests that defuse progress is only set while the player is defusing."""
+        ticks = pd.DataFrame(
+            {"tick": [50, 100, 420, 420, 740], "steamid": [1, 1, 1, 2, 2]}
+        )
+        ticks = parse_defuse_progress(ticks, parse_defuses(defuse_events))
+        assert ticks["defuse_progress"].isna().tolist() == [
+            True,
+            False,
+            False,
+            True,
+            False,
+        ]
+        assert ticks["defuse_progress"].tolist()[1:3] == [0.0, 0.5]
+        assert ticks["defuse_progress"].tolist()[4] == 0.75
+
+    def test_flag_junk_rounds(self):
+        """Tests that practice plugin rounds are flagged and dropped."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1, 2, 3, 4, 5],
+                "start": [0, 250, 450, 2000, 10000],
+                "freeze_end": [10, 260, 1200, 3000, 11000],
+                "end": [200, 400, 1210, 9000, 15000],
+            }
+        )
+        events = {
+            "server_cvar": pd.DataFrame(
+                {
+                    "tick": [0, 9500],
+                    "cvarname": ["sv_cheats", "sv_cheats"],
+                    "cvarvalue": ["0", "1"],
+                }
+            )
+        }
+        rounds = flag_junk_rounds(rounds, events)
+        assert rounds["is_rapid_restart"].tolist() == [True, True, False, False, False]
+        assert rounds["is_instant_round"].tolist() == [False, False, True, False, False]
+        assert rounds["is_cheats_enabled"].tolist() == [
+            False,
+            False,
+            False,
+            False,
+            True,
+        ]
+        real_rounds = drop_junk_rounds(rounds)
+        assert real_rounds["start"].tolist() == [2000]
+        assert real_rounds["round"].tolist() == [1]
+
+    def test_parse_bursts(self):
+        """Tests that shots close together with the same gun make a burst."""
+        weapon_fires = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1, 1, 1],
+                "tick": [100, 106, 112, 200, 206, 210],
+                "player_name": ["a", "a", "a", "a", "a", "b"],
+                "player_steamid": ["1", "1", "1", "1", "1", "2"],
+                "weapon": [
+                    "weapon_ak47",
+                    "weapon_ak47",
+                    "weapon_ak47",
+                    "weapon_ak47",
+                    "weapon_deagle",
+                    "weapon_knife",
+                ],
+                "player_yaw": [179.0, -179.0, -178.0, 0.0, 0.0, 0.0],
+                "player_pitch": [0.0, 1.0, 3.0, 0.0, 0.0, 0.0],
+            }
+        )
+        bursts = parse_bursts(weapon_fires)
+        assert bursts["start_tick"].tolist() == [100, 200, 206]
+        assert bursts["n_shots"].tolist() == [3, 1, 1]
+        assert bursts["yaw_drift"].tolist() == [3.0, 0.0, 0.0]
+        assert bursts["pitch_drift"].tolist() == [-3.0, 0.0, 0.0]
+
+    def test_parse_utility_timings(self):
+        """Tests that utility throws are summarized by when they were thrown."""
+        weapon_fires = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1, 1],
+                "player_team_name": ["CT", "CT", "CT", "TERRORIST", "TERRORIST"],
+                "weapon": [
+                    "weapon_smokegrenade",
+                    "weapon_flashbang",
+                    "weapon_ak47",
+                    "weapon_molotov",
+                    "weapon_hegrenade",
+                ],
+                "ticks_since_freeze_time_end": pd.array(
+                    [640, 1280, 1280, 3200, 6400], dtype=pd.Int64Dtype()
+                ),
+                "time_remaining": [105.0, 95.0, 95.0, 65.0, 15.0],
+            }
+        )
+        timings = parse_utility_timings(weapon_fires)
+        assert timings["team_name"].tolist() == ["CT", "TERRORIST"]
+        assert timings["n_utility_early"].tolist() == [2, 0]
+        assert timings["utility_value_early"].tolist() == [500, 0]
+        assert timings["n_utility_mid"].tolist() == [0, 1]
+        assert timings["utility_value_late"].tolist() == [0, 300]
+
+    def test_sanitize_positions(self):
+        """Tests that we flag and remove invalid positions."""
+        positions = pd.DataFrame(
+            {
+                "tick": [1, 2, 3, 4, 5],
+                "steamid": ["1", "1", "1", "1", "1"],
+                "X": [100.0, 0.0, 102.0, 1000.0, 99999.0],
+                "Y": [100.0, 0.0, 102.0, 1000.0, 100.0],
+                "Z": [0.0, 0.0, 0.0, 0.0, 0.0],
+            }
+        )
+        sanitized = sanitize_positions(positions, "de_dust2", fix=True)
+        assert sanitized["position_flag"].tolist() == [
+            None,
+            "zero",
+            None,
+            "teleport",
+            "out_of_bounds",
+        ]
+        assert sanitized["X"].isna().sum() == 3
+
+    def test_parse_phases(self):
+        """Tests that each event gets the phase it happened in."""
+        events = pd.DataFrame(
+            {
+                "tick": [50, 200, 400, 700, 200],
+                "round": [1, 1, 1, 1, 2],
+                "start": [0, 0, 0, 0, 0],
+                "freeze_end": [100, 100, 100, 100, 100],
+                "end": [600, 600, 600, 600, 600],
+                "bomb_plant": pd.array([300, 300, 300, 300, None], dtype="Int64"),
+            }
+        )
+        phases = parse_phases(events)
+        assert phases["phase"].tolist() == [
+            "freeze_time",
+            "pre_plant",
+            "post_plant",
+            "post_round",
+            "pre_plant",
+        ]
+        assert phases["phase_seconds"].tolist() == [
+            50 / 64,
+            100 / 64,
+            100 / 64,
+            100 / 64,
+            100 / 64,
+        ]
+
+    def test_parse_c4_timer(self):
+        """Tests that the C4 timer is estimated from bomb explosions."""
+        rounds = pd.DataFrame(
+            {
+                "bomb_plant": pd.array([100, None, 1000], dtype=pd.Int64Dtype()),
+                "bomb_explode": pd.array([2340, None, None], dtype=pd.Int64Dtype()),
+            }
+        )
+        assert parse_c4_timer(rounds) == 35
+        assert parse_c4_timer(rounds.iloc[1:]) == 40
+
+    def test_parse_times_c4_timer(self):
+        """Tests that the bomb times count down from a non-default C4 timer."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1],
+                "start": [0],
+                "freeze_end": [1280],
+                "end": [6400],
+                "bomb_plant": pd.array([3200], dtype=pd.Int64Dtype()),
+            }
+        )
+        df = pd.DataFrame({"round": [1], "tick": [3840]})
+        times = parse_times(df, rounds, c4_timer=35)
+        assert times["time_remaining"].tolist() == [25.0]
+        assert times["bomb_time_remaining"].tolist() == [25.0]
+        assert times["clock"].tolist() == ["00:25"]
+
+    def test_parse_wall_times(self):
+        """Tests that round wall times are counted back from the recording end."""
+        rounds = pd.DataFrame({"start": [0, 640], "end": [320, 1280]})
+        recording_end = pd.Timestamp("2024-03-01 12:00:00", tz="UTC")
+        rounds = parse_wall_times(rounds, recording_end, last_tick=1280)
+        assert rounds["start_wall_time"].tolist() == [
+            pd.Timestamp("2024-03-01 11:59:40", tz="UTC"),
+            pd.Timestamp("2024-03-01 11:59:50", tz="UTC"),
+        ]
+        assert rounds["end_wall_time"].iloc[1] == recording_end
+        rounds = parse_wall_times(rounds, None, last_tick=1280)
+        assert rounds["start_wall_time"].isna().all()
+
+    def test_delta_encode_ticks(self):
+        """Tests that unchanged fields are blanked between keyframes."""
+        ticks = pd.DataFrame(
+            {
+                "tick": [0, 0, 8, 8, 16, 16],
+                "steamid": ["1", "2", "1", "2", "1", "2"],
+                "name": ["a", "b", "a", "b", "a", "b"],
+                "health": [100, 100, 100, 73, 100, 73],
+                "inventory": [["ak47"], ["awp"], ["ak47"], ["awp"], [], ["awp"]],
+            }
+        )
+        delta = delta_encode_ticks(ticks, keyframe_ticks=16)
+        assert delta["is_keyframe"].tolist() == [True, False, True] * 2
+        assert delta["name"].tolist() == ["a", None, "a", "b", None, "b"]
+        assert delta["health"].tolist() == [100, None, 100, 100, 73, 73]
+        assert delta["inventory"].tolist()[:3] == [["ak47"], None, []]
+        decoded = delta_decode_ticks(delta)
+        pd.testing.assert_frame_equal(decoded, ticks)
+
+    def test_parse_frame_interval(self):
+        """Tests that frame intervals can be given in ticks or Hz."""
+        assert parse_frame_interval(16) == 16
+        assert parse_frame_interval("16") == 16
+        assert parse_frame_interval("4hz") == 16
+        assert parse_frame_interval("8Hz", tick_rate=128) == 16
+        with pytest.raises(ValueError, match="Invalid frame interval"):
+            parse_frame_interval("fast")
+        with pytest.raises(ValueError, match="Frame interval must be positive"):
+            parse_frame_interval(0)
+
+    def test_parse_team_shapes(self):
+        """Tests the centroid, spread and hull area of alive teammates."""
+        ticks = pd.DataFrame(
+            {
+                "tick": [1, 1, 1, 1, 1, 1],
+                "team_name": ["CT", "CT", "CT", "CT", "TERRORIST", "TERRORIST"],
+                "health": [100, 100, 100, 0, 100, 100],
+                "X": [0.0, 3.0, 0.0, 500.0, 0.0, 0.0],
+                "Y": [0.0, 0.0, 4.0, 500.0, 0.0, 10.0],
+            }
+        )
+        shapes = parse_team_shapes(ticks)
+        assert shapes["n_alive"].tolist() == [3, 2]
+        assert shapes["centroid_X"].tolist() == [1.0, 0.0]
+        assert shapes["spread"].tolist() == [4.0, 10.0]
+        assert shapes["hull_area"].tolist() == [6.0, 0.0]
+
+    def test_parse_nearest_players(self):
+        """Tests the nearest alive teammate and enemy of each player."""
+        ticks = pd.DataFrame(
+            {
+                "tick": [1, 1, 1, 1, 2],
+                "steamid": ["a", "b", "c", "d", "a"],
+                "team_name": ["CT", "CT", "TERRORIST", "TERRORIST", "CT"],
+                "health": [100, 100, 100, 0, 100],
+                "X": [0.0, 3.0, 0.0, 1.0, 0.0],
+                "Y": [0.0, 4.0, 10.0, 0.0, 0.0],
+                "Z": [0.0, 0.0, 0.0, 0.0, 0.0],
+            }
+        )
+        ticks = parse_nearest_players(ticks, chunk_size=1)
+        assert ticks["nearest_teammate_distance"].tolist()[:2] == [5.0, 5.0]
+        assert ticks["nearest_teammate_steamid"].tolist()[:3] == ["b", "a", None]
+        assert ticks["nearest_enemy_steamid"].tolist()[:3] == ["c", "c", "b"]
+        assert ticks["nearest_enemy_distance"][0] == 10.0
+        assert ticks[["nearest_enemy_distance"]].iloc[3:].isna().all().all()
+
+    def test_parse_view_rays(self):
+        """Tests the eye position, view direction and field of view of players."""
+        ticks = pd.DataFrame(
+            {
+                "team_name": ["CT", "TERRORIST", "CT", "SPECTATOR"],
+                "health": [100, 100, 0, 100],
+                "X": [0.0, 10.0, 0.0, 0.0],
+                "Y": [0.0, 0.0, 0.0, 0.0],
+                "Z": [0.0, 0.0, 0.0, 0.0],
+                "pitch": [0.0, 90.0, 0.0, 0.0],
+                "yaw": [90.0, 0.0, 0.0, 0.0],
+                "duck_amount": [0.0, 1.0, 0.0, 0.0],
+                "zoom_lvl": [0, 1, 0, 0],
+            }
+        )
+        ticks = parse_view_rays(ticks)
+        assert ticks["view_origin_Z"].tolist()[:2] == [64.0, 46.0]
+        assert ticks["view_dir_X"][0] == pytest.approx(0.0, abs=1e-9)
+        assert ticks["view_dir_Y"][0] == pytest.approx(1.0)
+        assert ticks["view_dir_Z"][1] == pytest.approx(-1.0)
+        assert ticks["view_fov"].tolist()[:2] == [90.0, 40.0]
+        assert ticks[["view_dir_X", "view_fov"]].iloc[2:].isna().all().all()
+
+    def test_parse_crosshair_offsets(self):
+        """Tests the angles between the attacker's view and the victim's head."""
+        kills = pd.DataFrame(
+            {
+                "attacker_X": [0.0, 0.0, 0.0],
+                "attacker_Y": [0.0, 0.0, 0.0],
+                "attacker_Z": [0.0, 0.0, 0.0],
+                "attacker_pitch": [0.0, 10.0, 0.0],
+                "attacker_yaw": [0.0, 0.0, 170.0],
+                "victim_X": [100.0, 100.0, -100.0],
+                "victim_Y": [0.0, 0.0, 0.0],
+                "victim_Z": [0.0, 0.0, 0.0],
+                "victim_duck_amount": [0.0, 0.0, 0.0],
+            }
+        )
+        kills = parse_crosshair_offsets(kills)
+        assert kills["crosshair_yaw_offset"].tolist() == pytest.approx([0, 0, 10])
+        assert kills["crosshair_pitch_offset"].tolist() == pytest.approx([0, -10, 0])
+        assert kills["crosshair_angle"].tolist() == pytest.approx([0, 10, 10])
+
+        # Crouching victims have their heads lower
+        kills["victim_duck_amount"] = 1.0
+        kills = parse_crosshair_offsets(kills)
+        assert kills["crosshair_pitch_offset"].iloc[0] > 0
+
+    def test_find_near_misses(self):
+        """Tests that shots aimed just past an enemy are near misses."""
+        weapon_fires = pd.DataFrame(
+            {
+                "round": [1, 1, 1],
+                "tick": [100, 200, 300],
+                "player_name": ["a"] * 3,
+                "player_steamid": ["1"] * 3,
+                "player_team_name": ["CT"] * 3,
+                "player_X": [0.0] * 3,
+                "player_Y": [0.0] * 3,
+                "player_Z": [0.0] * 3,
+                "player_pitch": [0.0] * 3,
+                "player_yaw": [1.0, 1.0, 45.0],
+                "weapon": ["weapon_ak47", "weapon_ak47", "weapon_ak47"],
+            }
+        )
+        players = pd.DataFrame(
+            {
+                "tick": [100, 100, 200, 300],
+                "name": ["a", "b", "b", "b"],
+                "steamid": [1, 2, 2, 2],
+                "team_name": ["CT", "TERRORIST", "TERRORIST", "TERRORIST"],
+                "X": [0.0, 1000.0, 1000.0, 1000.0],
+                "Y": [0.0] * 4,
+                "Z": [28.0] * 4,
+                "health": [100, 100, 100, 100],
+            }
+        )
+        damages = pd.DataFrame(
+            {"tick": [201], "attacker_steamid": ["1"], "victim_steamid": ["2"]}
+        )
+        near_misses = find_near_misses(weapon_fires, players, damages)
+        assert near_misses["tick"].tolist() == [100]
+        assert near_misses["target_steamid"].tolist() == ["2"]
+        assert near_misses["angle"].iloc[0] == pytest.approx(1.0)
+        assert near_misses["distance"].iloc[0] == pytest.approx(1000.0)
+
+    def test_parse_line_through_smoke(self):
+        """Tests that lines crossing an active smoke are flagged."""
+        damages = pd.DataFrame(
+            {
+                "tick": [100, 100, 500],
+                "attacker_X": [0.0, 0.0, 0.0],
+                "attacker_Y": [0.0, 0.0, 0.0],
+                "attacker_Z": [0.0, 0.0, 0.0],
+                "victim_X": [1000.0, 0.0, 1000.0],
+                "victim_Y": [0.0, 1000.0, 0.0],
+                "victim_Z": [0.0, 0.0, 0.0],
+            }
+        )
+        smokes = pd.DataFrame(
+            {
+                "start_tick": [50],
+                "end_tick": [400],
+                "X": [500.0],
+                "Y": [0.0],
+                "Z": [0.0],
+            }
+        )
+        damages = parse_line_through_smoke(damages, smokes)
+        assert damages["line_through_smoke"].tolist() == [True, False, False]
+
+    def test_parse_smoke_places(self):
+        """Tests that smokes take the place of the closest player position."""
+        smokes = pd.DataFrame({"X": [0.0, 1000.0], "Y": [0.0, 0.0], "Z": [0.0, 0.0]})
+        ticks = pd.DataFrame(
+            {
+                "X": [10.0, 12.0, 400.0],
+                "Y": [0.0, 0.0, 0.0],
+                "Z": [0.0, 0.0, 0.0],
+                "last_place_name": ["TopofMid", "TopofMid", "Connector"],
+            }
+        )
+        smokes = parse_smoke_places(smokes, ticks, "de_mirage")
+        assert smokes["place"].tolist() == ["TopofMid", None]
+        assert smokes["smoke_label"].tolist() == ["de_mirage TopofMid smoke", None]
+
+    def test_parse_round_range(self):
+        """Tests that round ranges can be given as strings, ints or tuples."""
+        assert parse_round_range("5-12") == (5, 12)
+        assert parse_round_range(" 7 ") == (7, 7)
+        assert parse_round_range(3) == (3, 3)
+        assert parse_round_range((2, 4)) == (2, 4)
+        for bad_range in ["12-5", "0-3", "a-b", "1-2-3"]:
+            with pytest.raises(ValueError, match="Invalid round range"):
+                parse_round_range(bad_range)
+
+    def test_parse_tick_range(self):
+        """Tests that tick ranges are given as start and end ticks."""
+        assert parse_tick_range("1000:2000") == (1000, 2000)
+        for bad_range in ["2000:1000", "1000", "a:b", "-1:5"]:
+            with pytest.raises(ValueError, match="Invalid tick range"):
+                parse_tick_range(bad_range)
+
+    def test_get_events_in_range(self):
+        """Tests that raw events in a tick range are gathered in tick order."""
+        events = {
+            "round_start": pd.DataFrame({"tick": [50, 150], "timelimit": [115, 115]}),
+            "player_death": pd.DataFrame(
+                {"tick": [120, 300], "weapon": ["ak47", "awp"], "assister": [None, 1]}
+            ),
+            "server_cvar": pd.DataFrame({"name": ["sv_cheats"]}),
+        }
+        events_in_range = get_events_in_range(events, 100, 200)
+        assert events_in_range["tick"].tolist() == [120, 150]
+        assert events_in_range["event"].tolist() == ["player_death", "round_start"]
+        assert events_in_range["fields"].tolist() == [
+            {"weapon": "ak47"},
+            {"timelimit": 115},
+        ]
+
+    def test_parse_alive_counts(self):
+        """Tests that alive counts drop on the first death or disconnect."""
+        spawns = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1],
+                "tick": [100, 100, 100, 100],
+                "name": ["a", "b", "c", "d"],
+                "steamid": [1, 2, 3, 4],
+                "team_name": ["CT", "CT", "TERRORIST", "TERRORIST"],
+            }
+        )
+        kills = pd.DataFrame(
+            {
+                "round": [0, 1, 1, 1],
+                "tick": [50, 300, 300, 500],
+                "victim_name": ["a", "a", "c", "d"],
+                "victim_steamid": ["1", "1", "3", "4"],
+            }
+        )
+        disconnects = pd.DataFrame(
+            {
+                "round": [1, 1],
+                "tick": [200, 400],
+                "user_name": ["c", "b"],
+                "user_steamid": [3, 2],
+            }
+        )
+        alive_counts = parse_alive_counts(spawns, kills, disconnects)
+        assert alive_counts["tick"].tolist() == [100, 200, 300, 400, 500]
+        assert alive_counts["ct_alive"].tolist() == [2, 2, 1, 0, 0]
+        assert alive_counts["t_alive"].tolist() == [2, 1, 1, 1, 0]
+
+    def test_parse_alive_counts_bots(self):
+        """Tests that each bot death counts, although bots share a Steam ID."""
+        spawns = pd.DataFrame(
+            {
+                "round": [1, 1, 1],
+                "tick": [100, 100, 100],
+                "name": ["BOT Alex", "BOT Brad", "player"],
+                "steamid": [0, 0, 1],
+                "team_name": ["CT", "CT", "TERRORIST"],
+            }
+        )
+        kills = pd.DataFrame(
+            {
+                "round": [1, 1],
+                "tick": [200, 300],
+                "victim_name": ["BOT Brad", "BOT Alex"],
+                "victim_steamid": ["0", "0"],
+            }
+        )
+        alive_counts = parse_alive_counts(spawns, kills)
+        assert alive_counts["tick"].tolist() == [100, 200, 300]
+        assert alive_counts["ct_alive"].tolist() == [2, 1, 0]
+        assert alive_counts["t_alive"].tolist() == [1, 1, 1]
+
+    def test_parse_kill_advantages(self):
+        """Tests that kills see the alive counts from before their tick."""
+        alive_counts = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1],
+                "tick": [100, 300, 400, 500],
+                "ct_alive": [2, 1, 1, 0],
+                "t_alive": [2, 2, 1, 1],
+            }
+        )
+        kills = pd.DataFrame(
+            {
+                "round": [1, 1, 1],
+                "tick": [300, 400, 500],
+                "attacker_team_name": ["TERRORIST", "CT", "TERRORIST"],
+                "victim_team_name": ["CT", "TERRORIST", "CT"],
+            }
+        )
+        kills = parse_kill_advantages(kills, alive_counts)
+        assert kills["man_advantage"].tolist() == ["2v2", "1v2", "1v1"]
+        assert kills["is_clutch"].tolist() == [False, True, True]
+        assert kills["victim_side_alive"].tolist() == [2, 2, 1]
+
+    def test_parse_spectators(self):
+        """Tests that spectator stints end on a team change or disconnect."""
+        events = {
+            "player_team": pd.DataFrame(
+                {
+                    "tick": [10, 20, 30, 40, 50],
+                    "user_name": ["GOTV", "caster", "player", "caster", "caster"],
+                    "user_steamid": [0, 1, 2, 1, 1],
+                    "team": [1, 1, 3, 2, 1],
+                    "isbot": [True, False, False, False, False],
+                    "disconnect": [False, False, False, False, False],
+                }
+            ),
+            "player_disconnect": pd.DataFrame(
+                {"tick": [70], "user_name": ["caster"], "user_steamid": [1]}
+            ),
+        }
+        spectators = parse_spectators(events)
+        assert spectators["name"].tolist() == ["GOTV", "caster", "caster"]
+        assert spectators["is_gotv"].tolist() == [True, False, False]
+        assert spectators["start_tick"].tolist() == [10, 20, 50]
+        assert spectators["end_tick"].isna().tolist() == [True, False, False]
+        assert spectators["end_tick"].iloc[1:].tolist() == [40, 70]
+        assert get_spectating_steamids(spectators, 45) == {"0"}
+        assert get_spectating_steamids(spectators, 60) == {"0", "1"}
+        assert parse_spectators({}).shape[0] == 0
+
+    def test_parse_team_keys(self):
+        """Tests that teams keep their roster key when switching sides."""
+        spawns = pd.DataFrame(
+            {
+                "round": [12, 12, 13, 13],
+                "steamid": ["1", "2", "1", "2"],
+                "team_name": ["CT", "TERRORIST", "TERRORIST", "CT"],
+            }
+        )
+        team_keys = parse_team_keys(spawns)
+        assert team_keys["round"].tolist() == [12, 12, 13, 13]
+        assert team_keys["team_name"].tolist() == ["CT", "TERRORIST"] * 2
+        keys = team_keys["team_key"].tolist()
+        assert keys[0] == keys[3]
+        assert keys[1] == keys[2]
+        assert keys[0] != keys[1]
+
+        kills = pd.DataFrame(
+            {
+                "round": [13, 0],
+                "attacker_team_name": ["CT", "CT"],
+                "victim_team_name": ["TERRORIST", "TERRORIST"],
+            }
+        )
+        kills = apply_team_keys(kills, team_keys)
+        assert kills["attacker_team_key"].tolist()[0] == keys[2]
+        assert kills["victim_team_key"].tolist()[0] == keys[3]
+        assert kills["attacker_team_key"].isna().tolist() == [False, True]
+
+    def test_summarize_activity(self):
+        """Tests that players who never move or fire are AFK."""
+        samples = pd.DataFrame(
+            {
+                "round": [1] * 9,
+                "tick": [100, 100, 100, 200, 200, 200, 300, 300, 300],
+                "name": ["afk", "mover", "bot_user"] * 3,
+                "steamid": [1, 2, 3] * 3,
+                "team_name": ["CT"] * 9,
+                "X": [0, 0, 0, 10, 500, 0, 20, 900, 0],
+                "Y": [0] * 9,
+                "Z": [0] * 9,
+                "is_alive": [True, True, True, True, True, False, True, True, False],
+                "is_controlling_bot": [False] * 5 + [True, False, False, True],
+            }
+        )
+        weapon_fires = pd.DataFrame(
+            {"round": [1], "player_name": ["bot_user"], "player_steamid": ["3"]}
+        )
+        activity = summarize_activity(samples, weapon_fires)
+        assert activity["name"].tolist() == ["afk", "bot_user", "mover"]
+        assert activity["max_distance"].tolist() == [20, 0, 900]
+        assert activity["n_shots"].tolist() == [0, 1, 0]
+        assert activity["is_afk"].tolist() == [True, False, False]
+        assert activity["is_bot_controlled"].tolist() == [False, True, False]
+        assert not activity["is_bot"].any()
+
+    def test_summarize_activity_bots(self):
+        """Tests that bots, which share the Steam ID 0, each get their own row."""
+        samples = pd.DataFrame(
+            {
+                "round": [1] * 4,
+                "tick": [100, 100, 200, 200],
+                "name": ["BOT Alex", "BOT Brad"] * 2,
+                "steamid": [0] * 4,
+                "team_name": ["TERRORIST"] * 4,
+                "X": [0, 100, 10, 900],
+                "Y": [0] * 4,
+                "Z": [0] * 4,
+                "is_alive": [True] * 4,
+                "is_controlling_bot": [False] * 4,
+            }
+        )
+        weapon_fires = pd.DataFrame(
+            {"round": [1], "player_name": ["BOT Brad"], "player_steamid": ["0"]}
+        )
+        activity = summarize_activity(samples, weapon_fires)
+        assert activity["name"].tolist() == ["BOT Alex", "BOT Brad"]
+        assert activity["max_distance"].tolist() == [10, 800]
+        assert activity["n_shots"].tolist() == [0, 1]
+        assert activity["is_afk"].tolist() == [True, False]
+        assert activity["is_bot"].all()
+
+    def test_parse_timing(self):
+        """Tests that drift is measured from the typical tick offset."""
+        ticks = pd.DataFrame(
+            {
+                "tick": [100, 100, 104, 108, 116],
+                "game_time": [0.0, 0.0, 4 / 64, 8 / 64, 15 / 64],
+            }
+        )
+        timing = parse_timing(ticks, 64)
+        assert timing["n_frames"] == 4
+        assert timing["tick_offset"] == 100
+        assert timing["max_drift_ticks"] == 1
+        assert timing["irregular_frame_share"] == 1 / 3
+        assert parse_timing(ticks[["tick"]], 64)["n_frames"] == 0
+
+    def test_parse_tick_gaps(self):
+        """Tests that dropped frames during a round are reported as gaps."""
+        rounds = pd.DataFrame({"start": [0, 1000], "official_end": [900, 2000]})
+        ticks = pd.DataFrame(
+            {"tick": [*range(100, 300, 2), 310, *range(400, 500, 2), 1100, 1102]}
+        )
+        gaps = parse_tick_gaps(ticks, rounds)
+        assert gaps["round"].tolist() == [1, 1]
+        assert gaps["start_tick"].tolist() == [298, 310]
+        assert gaps["end_tick"].tolist() == [310, 400]
+        assert gaps["missing_frames"].tolist() == [5, 44]
+        assert parse_tick_gaps(ticks, rounds, min_missing_frames=10).shape[0] == 1
+        assert parse_tick_gaps(pd.DataFrame(), rounds).shape[0] == 0
+
+    def test_parse_kill_cameras(self):
+        """Tests that close kills by one attacker are merged into one clip."""
+        kills = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1, 2],
+                "tick": [1000, 1100, 1500, 2000, 5000],
+                "attacker_name": ["a", "a", "b", None, "a"],
+                "attacker_steamid": [
+                    "76561197960265729",
+                    "76561197960265729",
+                    "76561197960265730",
+                    "None",
+                    "76561197960265729",
+                ],
+                "victim_steamid": ["1", "2", "3", "4", "76561197960265729"],
+            }
+        )
+        cameras = parse_kill_cameras(kills, tick_rate=100)
+        assert cameras["start_tick"].tolist() == [500, 1000]
+        assert cameras["end_tick"].tolist() == [1300, 1700]
+        assert cameras["n_kills"].tolist() == [2, 1]
+        assert cameras["duration_seconds"].tolist() == [8.0, 7.0]
+
+        script = to_hlae_script(cameras).splitlines()
+        assert script == [
+            "mirv_cmd clear",
+            'mirv_cmd addAtTick 500 "spec_player_by_accountid 1"',
+            'mirv_cmd addAtTick 1300 "demo_gototick 1000"',
+            'mirv_cmd addAtTick 1000 "spec_player_by_accountid 2"',
+            'mirv_cmd addAtTick 1700 "demo_pause"',
+            "demo_gototick 500",
+        ]
+
+    def test_parse_map_segments(self):
+        """Tests that a restart between rounds starts a new map segment."""
+        rounds = pd.DataFrame(
+            {"start": [0, 1000, 3000, 4000], "official_end": [900, 2000, 3900, 5000]}
+        )
+        events = {"begin_new_match": pd.DataFrame({"tick": [0, 2500]})}
+        rounds = parse_map_segments(rounds, events)
+        assert rounds["map_segment"].tolist() == [1, 1, 2, 2]
+        rounds = parse_map_segments(rounds, {})
+        assert rounds["map_segment"].tolist() == [1, 1, 1, 1]
+
+    def test_parse_convar_changes(self):
+        """Tests that only convar announcements that change a value are kept."""
+        events = {
+            "server_cvar": pd.DataFrame(
+                {
+                    "tick": [0, 0, 500, 900],
+                    "cvarname": [
+                        "mp_maxrounds",
+                        "mp_team_timeout_time",
+                        "mp_maxrounds",
+                        "mp_team_timeout_time",
+                    ],
+                    "cvarvalue": ["24", "30", "24", "60"],
+                }
+            )
+        }
+        changes = parse_convar_changes(events)
+        assert changes["tick"].tolist() == [0, 0, 900]
+        assert changes["new_value"].tolist() == ["24", "30", "60"]
+        assert changes["old_value"].tolist()[2] == "30"
+        assert parse_convar_changes({}).shape[0] == 0
+
+    def test_parse_restart_delays(self):
+        """Tests that missing official ends follow the restart delay convar."""
+        rounds = pd.DataFrame(
+            {
+                "start": [0, 2000, 3200],
+                "end": [1000, 3000, 4000],
+                "official_end": pd.array([1000, 3000, 4000], dtype="Int32"),
+            }
+        )
+        events = {
+            "server_cvar": pd.DataFrame(
+                {
+                    "tick": [1500],
+                    "cvarname": ["mp_round_restart_delay"],
+                    "cvarvalue": ["3"],
+                }
+            )
+        }
+        rounds = parse_restart_delays(rounds, events)
+        assert rounds["restart_delay"].tolist() == [7.0, 3.0, 3.0]
+        assert rounds["official_end"].tolist() == [1448, 3100, 4000]
+
+    def test_parse_win_streaks(self):
+        """Tests that streaks follow the team across halftime."""
+        rounds = pd.DataFrame(
+            {
+                "winner": ["CT", "CT", "T", "CT", "T"],
+                "ct_team_key": ["a", "a", "b", "b", "b"],
+                "t_team_key": ["b", "b", "a", "a", "a"],
+                "map_segment": [1, 1, 1, 1, 2],
+            }
+        )
+        rounds = parse_win_streaks(rounds)
+        assert rounds["win_streak"].tolist() == [1, 2, 3, 1, 1]
+        assert rounds["broke_streak"].tolist() == [False, False, False, True, False]
+        rounds = parse_win_streaks(rounds.drop(columns=["ct_team_key", "t_team_key"]))
+        assert rounds["win_streak"].tolist() == [1, 2, 1, 1, 1]
+
+    def test_parse_round_summaries(self):
+        """Tests that round summaries read the round's events in order."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1, 2],
+                "winner": ["CT", "TERRORIST"],
+                "reason": ["bomb_defused", "t_win"],
+            }
+        )
+        kills = pd.DataFrame(
+            {
+                "round": [1, 1, 2],
+                "tick": [300, 200, 100],
+                "attacker_name": ["b", "a", None],
+                "victim_name": ["c", "d", "e"],
+                "victim_last_place_name": ["Long", "TopofMid", ""],
+            }
+        )
+        bomb = pd.DataFrame(
+            {
+                "round": [1, 1],
+                "event": ["planted", "defused"],
+                "user_last_place_name": ["BombsiteA", "BombsiteA"],
+            }
+        )
+        smokes = pd.DataFrame(
+            {"round": [1, 1, 1], "place": ["TopofMid", "TopofMid", "Long"]}
+        )
+        executes = pd.DataFrame({"round": [1], "site": ["A"]})
+        rounds = parse_round_summaries(rounds, kills, bomb, smokes, executes)
+        assert rounds["summary_text"].tolist() == [
+            "CT win by defuse; opening kill a vs d TopofMid; 2 smokes TopofMid; "
+            "1 smoke Long; A execute; planted BombsiteA",
+            "T win by elimination; opening kill world vs e",
+        ]
+
+    def test_load_place_polygons(self, tmp_path: Path):
+        """Tests that place polygons load, and that degenerate ones raise."""
+        polygons_path = tmp_path / "places.json"
+        polygons_path.write_text(
+            json.dumps([{"name": "Mid", "points": [[0, 0], [100, 0], [0, 100]]}])
+        )
+        polygons = load_place_polygons(polygons_path)
+        assert polygons[0][0] == "Mid"
+        assert polygons[0][1].shape == (3, 2)
+
+        polygons_path.write_text(
+            json.dumps([{"name": "Mid", "points": [[0, 0], [100, 0]]}])
+        )
+        with pytest.raises(ValueError, match="at least 3"):
+            load_place_polygons(polygons_path)
+
+    def test_fill_place_names(self):
+        """Tests that only empty places inside a polygon are filled in."""
+        square = np.array([[0, 0], [100, 0], [100, 100], [0, 100]], dtype=float)
+        df = pd.DataFrame(
+            {
+                "last_place_name": ["", None, "BombsiteA", ""],
+                "X": [50.0, 150.0, 50.0, 10.0],
+                "Y": [50.0, 50.0, 50.0, 90.0],
+                "attacker_last_place_name": ["", "", "", ""],
+                "attacker_X": [-10.0, 99.0, 1.0, 50.0],
+                "attacker_Y": [50.0, 1.0, 1.0, 200.0],
+            }
+        )
+        df = fill_place_names(df, [("Mid", square)])
+        assert df["last_place_name"].tolist() == ["Mid", None, "BombsiteA", "Mid"]
+        assert df["attacker_last_place_name"].tolist() == ["", "Mid", "Mid", ""]
+
+    def test_extract_place_polygons(self):
+        """Tests that places are outlined by the hull of snapped positions."""
+        ticks = pd.DataFrame(
+            {
+                "X": [1.0, 63.0, 65.0, 2.0, 30.0, 33.0, 500.0, 600.0, 0.0],
+                "Y": [0.0, 1.0, 62.0, 64.0, 33.0, 1.0, 500.0, 600.0, 0.0],
+                "last_place_name": ["Mid"] * 6 + ["Long", "Long", ""],
+            }
+        )
+        polygons = extract_place_polygons(ticks)
+        assert [name for name, _ in polygons] == ["Mid"]
+        assert polygons[0][1].tolist() == [[0, 0], [64, 0], [64, 64], [0, 64]]
+        assert to_place_json(polygons)[0]["name"] == "Mid"
+        ring = to_geojson(polygons)["features"][0]["geometry"]["coordinates"][0]
+        assert ring[0] == ring[-1]
+
+    def test_summarize_place_times(self):
+        """Tests that only alive samples with a place are counted."""
+        samples = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1, 2],
+                "steamid": ["a", "a", "a", "a", "a"],
+                "name": ["A", "A", "A", "A", "A"],
+                "team_name": ["CT", "CT", "CT", "CT", "TERRORIST"],
+                "is_alive": [True, True, True, False, True],
+                "last_place_name": ["Mid", "Mid", "Long", "Long", ""],
+            }
+        )
+        place_times = summarize_place_times(samples, sample_seconds=0.5)
+        assert place_times["place"].tolist() == ["Mid", "Long"]
+        assert place_times["seconds"].tolist() == [1.0, 0.5]
+        assert summarize_place_times(samples.iloc[[3, 4]]).shape[0] == 0
+
+    def test_find_executes(self):
+        """Tests that utility near one site within the window is an execute."""
+        zones = pd.DataFrame(
+            {
+                "zone": ["bombsite_a", "bombsite_b"],
+                "min_X": [0.0, 3000.0],
+                "max_X": [500.0, 3500.0],
+                "min_Y": [0.0, 0.0],
+                "max_Y": [500.0, 500.0],
+            }
+        )
+        utility = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 1, 1, 2, 2],
+                "tick": [100, 200, 300, 5000, 300, 100, 200],
+                "grenade_type": [
+                    "smoke",
+                    "flash",
+                    "molotov",
+                    "smoke",
+                    "smoke",
+                    "smoke",
+                    "smoke",
+                ],
+                "thrower_steamid": ["1", "2", "3", "1", "4", "1", "2"],
+                "X": [100.0, 600.0, 200.0, 100.0, 3200.0, 100.0, 100.0],
+                "Y": [100.0, 100.0, 200.0, 100.0, 100.0, 100.0, 100.0],
+            }
+        )
+        bomb = pd.DataFrame(
+            {
+                "round": [1],
+                "tick": [900],
+                "event": ["planted"],
+                "user_X": [250.0],
+                "user_Y": [250.0],
+            }
+        )
+        rounds = pd.DataFrame({"round": [1, 2], "winner": ["T", "CT"]})
+        executes = find_executes(utility, zones, bomb, rounds)
+        assert executes.shape[0] == 1
+        execute = executes.iloc[0]
+        assert execute["site"] == "A"
+        assert (execute["start_tick"], execute["end_tick"]) == (100, 300)
+        assert execute["grenade_types"] == ["smoke", "flash", "molotov"]
+        assert execute["planted"]
+        assert execute["won"]
+
+    def test_summarize_zones(self):
+        """Tests that zones are bounded by the positions inside them."""
+        positions = pd.DataFrame(
+            {
+                "X": [0.0, 10.0, 100.0, 500.0, 600.0],
+                "Y": [0.0, 20.0, 100.0, 500.0, 600.0],
+                "Z": [0.0, 0.0, 5.0, 10.0, 10.0],
+                "team_name": ["CT", "CT", "TERRORIST", "TERRORIST", "CT"],
+                "in_buy_zone": [True, True, True, False, False],
+                "which_bomb_zone": [0, 0, 0, 1, 0],
+            }
+        )
+        zones = summarize_zones(positions).set_index("zone")
+        assert zones.index.tolist() == ["bombsite_a", "ct_buyzone", "t_buyzone"]
+        assert zones.loc["ct_buyzone", "max_Y"] == 20.0
+        assert zones.loc["ct_buyzone", "n_positions"] == 2
+        assert zones.loc["bombsite_a", "min_X"] == 500.0
+        assert summarize_zones(positions.iloc[[4]]).shape[0] == 0
+
+    def test_get_tick_window(self):
+        """Tests that round ranges and tick bounds are combined."""
+        rounds = pd.DataFrame(
+            {"round": [1, 2, 3], "start": [0, 100, 200], "official_end": [90, 190, 290]}
+        )
+        assert get_tick_window(rounds, (2, 3)) == (100, 290)
+        assert get_tick_window(rounds, (2, 3), from_tick=150) == (150, 290)
+        assert get_tick_window(rounds, to_tick=50)[1] == 50
+        assert get_tick_window(rounds, (5, 6))[1] == -1
+
+    def test_downsample_ticks(self):
+        """Tests that we keep the first recorded tick in each interval."""
+        ticks = pd.DataFrame({"tick": [0, 0, 2, 4, 6, 8, 10, 12, 14, 16, 18]})
+        downsampled = downsample_ticks(ticks, 5)
+        assert downsampled["tick"].tolist() == [0, 0, 6, 10, 16]
+
+    def test_parse_view_velocity(self):
+        """Tests that yaw keeps counting past the wrap, per player."""
+        ticks = pd.DataFrame(
+            {
+                "tick": [0, 0, 1, 1, 3],
+                "steamid": ["a", "b", "a", "b", "a"],
+                "yaw": [170.0, 0.0, -170.0, 10.0, -150.0],
+                "pitch": [0.0, 0.0, 0.0, 5.0, 10.0],
+            }
+        )
+        ticks = parse_view_velocity(ticks, tick_rate=64)
+        assert ticks["yaw_unwrapped"].tolist() == [170.0, 0.0, 190.0, 10.0, 210.0]
+        assert ticks["yaw_velocity"][[0, 1]].isna().all()
+        assert ticks["yaw_velocity"][2] == 20.0 * 64
+        assert ticks["yaw_velocity"][4] == 10.0 * 64
+        assert ticks["pitch_velocity"][3] == 5.0 * 64
+
+    def test_parse_equipment_values(self):
+        """Tests that equipment value is split into loadout categories."""
+        players = pd.DataFrame(
+            {
+                "inventory": [
+                    ["Knife", "Glock-18", "AK-47"],
+                    ["Knife", "USP-S", "MP9", "Smoke Grenade", "Zeus x27"],
+                ],
+                "armor_value": [0, 100],
+                "has_helmet": [False, True],
+                "has_defuser": [False, True],
+            }
+        )
+        players = parse_equipment_values(players)
+        assert players["primary_value"].tolist() == [2700, 1250]
+        assert players["secondary_value"].tolist() == [200, 200]
+        assert players["armor_equipment_value"].tolist() == [0, 1000]
+        assert players["utility_value"].tolist() == [0, 500]
+        assert players["kit_value"].tolist() == [0, 400]
+        assert players["equipment_value"].tolist() == [2900, 3350]
+        with pytest.raises(KeyError, match="inventory column not found"):
+            parse_equipment_values(pd.DataFrame({"armor_value": [100]}))
+
+    def test_get_half_start_money(self):
+        """Tests that money resets at the start of each half and overtime half."""
+        assert get_half_start_money(1) == 800
+        assert get_half_start_money(12) == 0
+        assert get_half_start_money(13) == 800
+        assert get_half_start_money(25) == 12500
+        assert get_half_start_money(28) == 12500
+        assert get_half_start_money(16, game="csgo") == 800
+        assert get_half_start_money(31, game="csgo") == 10000
+
+    def test_forecast_economy(self):
+        """Tests that the next round money adds the win and loss bonuses."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1, 2],
+                "start": [0, 100],
+                "end": [100, 200],
+                "winner": ["T", "CT"],
+                "reason": ["ct_killed", "bomb_defused"],
+                "bomb_plant": pd.array([None, 150], dtype=pd.Int64Dtype()),
+            }
+        )
+        balances = pd.DataFrame(
+            {
+                "tick": [100, 100, 200, 200],
+                "team_name": ["CT", "TERRORIST", "CT", "TERRORIST"],
+                "balance": [0, 200, 15000, 100],
+            }
+        )
+        convar_changes = pd.DataFrame(columns=["tick", "name", "new_value"])
+        economy = forecast_economy(balances, rounds, convar_changes)
+        assert economy["round_won"].tolist() == [False, True, True, False]
+        assert economy["loss_streak"].tolist() == [2, 0, 1, 1]
+        assert economy["round_bonus"].tolist() == [1900, 3250, 3500, 2200]
+        assert economy["next_round_money"].tolist() == [1900, 3450, 16000, 2300]
+
+        # Servers can raise the loss bonus
+        convar_changes = pd.DataFrame(
+            {"tick": [0], "name": ["cash_team_loser_bonus"], "new_value": ["2400"]}
+        )
+        economy = forecast_economy(balances, rounds, convar_changes)
+        assert economy["round_bonus"].tolist() == [2900, 3250, 3500, 3200]
+        assert economy["next_round_money"].tolist() == [2900, 3450, 16000, 3300]
+
+    def test_get_economy_settings(self):
+        """Tests that economy convars override the defaults once they are set."""
+        convar_changes = pd.DataFrame(
+            {
+                "tick": [0, 500, 500],
+                "name": ["mp_maxmoney", "mp_startmoney", "mp_team_timeout_time"],
+                "new_value": ["16000", "10000", "60"],
+            }
+        )
+        assert get_economy_settings(convar_changes, 100)["start_money"] == 800
+        settings = get_economy_settings(convar_changes, 500)
+        assert settings["start_money"] == 10000
+        assert settings["max_money"] == 16000
+        assert settings["win_bonus"] == 3250
+
+    def test_build_economy_seeds(self):
+        """Tests that each player's money is paired with the round's inputs."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1],
+                "start": [0],
+                "end": [100],
+                "winner": ["CT"],
+                "reason": ["t_killed"],
+                "bomb_plant": pd.array([None], dtype=pd.Int64Dtype()),
+            }
+        )
+        balances = pd.DataFrame(
+            {
+                "tick": [0, 0, 0, 100, 100],
+                "steamid": ["1", "2", "3", "1", "2"],
+                "name": ["a", "b", "c", "a", "b"],
+                "team_name": ["CT", "TERRORIST", "SPECTATOR", "CT", "TERRORIST"],
+                "balance": [800, 800, 0, 200, 0],
+            }
+        )
+        convar_changes = pd.DataFrame(
+            {"tick": [0], "name": ["mp_startmoney"], "new_value": ["800"]}
+        )
+        economy = forecast_economy(balances, rounds, convar_changes)
+        seeds = build_economy_seeds(balances, rounds, economy, convar_changes)
+        assert seeds["steamid"].tolist() == ["1", "2"]
+        assert seeds["start_money"].tolist() == [800, 800]
+        assert seeds["end_money"].tolist() == [200, 0]
+        assert seeds["round_bonus"].tolist() == [3250, 1900]
+        assert (seeds["max_money"] == 16000).all()
+
+        # The round bonus and the settings come from the same convars
+        convar_changes = pd.DataFrame(
+            {"tick": [0], "name": ["cash_team_loser_bonus"], "new_value": ["2400"]}
+        )
+        economy = forecast_economy(balances, rounds, convar_changes)
+        seeds = build_economy_seeds(balances, rounds, economy, convar_changes)
+        assert seeds["round_bonus"].tolist() == [3250, 2900]
+        assert (seeds["loss_bonus"] == 2400).all()
+
+    def test_parse_zeus_purchases(self):
+        """Tests that Zeus buys are counted per player, less refunds."""
+        seeds = pd.DataFrame({"round": [1, 1, 2], "steamid": ["1", "2", "1"]})
+        buy_log = pd.DataFrame(
+            {
+                "round": [1, 1, 1, 2],
+                "steamid": ["1", "1", "2", "1"],
+                "action": ["buy", "buy", "buy", "refund"],
+                "weapon": ["taser", "ak47", "taser", "taser"],
+            }
+        )
+        seeds = parse_zeus_purchases(seeds, buy_log)
+        assert seeds["zeus_purchases"].tolist() == [1, 1, -1]
+
+    def test_parse_melee_kills(self):
+        """Tests knife types, backstabs and Zeus kills."""
+        kills = pd.DataFrame(
+            {
+                "weapon": ["knife_t", "knife_karambit", "bayonet", "taser", "ak47"],
+                "attacker_X": [0.0, 0.0, 0.0, 0.0, 0.0],
+                "attacker_Y": [0.0, 0.0, 0.0, 0.0, 0.0],
+                "victim_X": [50.0, 50.0, 50.0, 50.0, 50.0],
+                "victim_Y": [0.0, 0.0, 0.0, 0.0, 0.0],
+                "victim_yaw": [0.0, 180.0, 30.0, 0.0, 0.0],
+            }
+        )
+        kills = parse_melee_kills(kills)
+        assert kills["knife_type"].tolist()[:3] == ["default", "karambit", "bayonet"]
+        assert kills["knife_type"].iloc[3:].isna().all()
+        assert kills["is_backstab"].tolist() == [True, False, True, False, False]
+        assert kills["is_zeus_kill"].tolist() == [False, False, False, True, False]
+
+    def test_build_dropped_weapons(self):
+        """Tests that dropped guns stay on the ground until picked up."""
+        rounds = pd.DataFrame({"round": [1], "official_end": [1000]})
+        kills = pd.DataFrame(
+            {
+                "round": [1, 1],
+                "tick": [100, 200],
+                "victim_name": ["a", "b"],
+                "victim_steamid": ["1", "2"],
+                "victim_X": [10.0, 20.0],
+                "victim_Y": [0.0, 0.0],
+                "victim_Z": [0.0, 0.0],
+            }
+        )
+        inventories = pd.DataFrame(
+            {
+                "tick": [99, 199],
+                "steamid": [1, 2],
+                "inventory": [
+                    ["Knife", "Glock-18", "AK-47", "Flashbang"],
+                    ["Knife", "USP-S"],
+                ],
+            }
+        )
+        pickups = pd.DataFrame(
+            {
+                "round": [1, 1],
+                "tick": [150, 300],
+                "item": ["awp", "ak47"],
+                "user_name": ["c", "c"],
+                "user_steamid": [3, 3],
+            }
+        )
+        dropped = build_dropped_weapons(kills, inventories, pickups, rounds)
+        assert dropped["weapon"].tolist() == ["ak47", "usp_silencer"]
+        assert dropped["previous_owner_name"].tolist() == ["a", "b"]
+        assert dropped["picked_up_by_name"].tolist() == ["c", None]
+        assert dropped["remove_tick"].tolist() == [300, 1000]
+
+    def test_parse_bomb_state(self):
+        """Tests that the bomb carrier and state follow the bomb events."""
+        rounds = pd.DataFrame({"round": [1], "start": [0], "official_end": [1000]})
+        ticks = pd.DataFrame(
+            {
+                "tick": [100, 100, 200, 200, 300, 300, 400, 400],
+                "steamid": ["1", "2"] * 4,
+                "inventory": [
+                    ["Knife", "C4 Explosive"],
+                    ["Knife"],
+                    ["Knife"],
+                    ["Knife"],
+                    ["Knife"],
+                    ["Knife", "C4 Explosive"],
+                    ["Knife"],
+                    ["Knife"],
+                ],
+            }
+        )
+        events = {
+            "bomb_dropped": pd.DataFrame({"tick": [150]}),
+            "bomb_pickup": pd.DataFrame({"tick": [250]}),
+            "bomb_planted": pd.DataFrame({"tick": [350]}),
+        }
+        ticks = parse_bomb_state(ticks, events, rounds)
+        states = ticks.drop_duplicates("tick")
+        assert states["bomb_state"].tolist() == [
+            "carried",
+            "dropped",
+            "carried",
+            "planted",
+        ]
+        assert states["bomb_carrier_steamid"].tolist() == ["1", None, "2", None]
+        assert states["is_bomb_ticking"].tolist() == [False, False, False, True]
+
+    def test_bomb_warnings(self):
+        """Tests that neglected bomb drops are flagged with their durations."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1, 2],
+                "start": [0, 5000],
+                "freeze_end": [500, 5500],
+                "end": [4000, 9000],
+                "official_end": [4500, 9500],
+            }
+        )
+        events = {
+            "bomb_dropped": pd.DataFrame(
+                {
+                    "tick": [100, 1000, 6000],
+                    "user_name": ["a", "a", "b"],
+                    "user_steamid": ["1", "1", "2"],
+                    "user_X": [0.0, 0.0, 0.0],
+                    "user_Y": [0.0, 0.0, 0.0],
+                    "user_Z": [0.0, 0.0, 0.0],
+                }
+            ),
+            "bomb_pickup": pd.DataFrame({"tick": [600, 1500]}),
+        }
+        drops = build_bomb_drops(build_bomb_states(events, rounds), rounds)
+        assert drops["start_tick"].tolist() == [500, 1000, 6000]
+        assert drops["end_tick"].tolist() == [600, 1500, 9000]
+        assert drops["end_reason"].tolist() == ["pickup", "pickup", "round_end"]
+
+        # The Terrorists leave the bomb of round 2 behind after 5 seconds
+        sample_ticks = list(range(6000, 9001, 64))
+        players = pd.DataFrame(
+            {
+                "tick": sample_ticks,
+                "team_name": "TERRORIST",
+                "health": 100,
+                "X": [0.0 if tick < 6320 else 2000.0 for tick in sample_ticks],
+                "Y": 0.0,
+                "Z": 0.0,
+            }
+        )
+        warnings = find_bomb_warnings(drops, players, tick_rate=64)
+        assert warnings["warning"].tolist() == ["unattended", "left_behind"]
+        assert warnings["round"].tolist() == [2, 2]
+        assert warnings["duration_seconds"][0] == 3000 / 64
+        assert warnings["duration_seconds"][1] == len(sample_ticks) - 5
+        assert warnings["dropper_name"].tolist() == ["b", "b"]
+
+    def test_build_buy_log(self):
+        """Tests that refunds are matched to the latest buy of the same price."""
+        rounds = pd.DataFrame(
+            {
+                "round": [1, 2],
+                "start": [0, 1000],
+                "freeze_end": [100, 1100],
+                "official_end": [900, 2000],
+            }
+        )
+        purchases = pd.DataFrame(
+            {
+                "tick": [10, 20, 30, 500, 1010],
+                "user_name": ["a", "a", "a", "a", "a"],
+                "user_steamid": [1, 1, 1, 1, 1],
+                "weapon": [
+                    "weapon_ak47",
+                    "item_assaultsuit",
+                    "weapon_ak47",
+                    "weapon_awp",
+                    "weapon_flashbang",
+                ],
+            }
+        )
+        spent = pd.DataFrame(
+            {
+                "tick": [5, 10, 20, 30, 40, 1005, 1010],
+                "name": ["a"] * 7,
+                "steamid": [1] * 7,
+                "cash_spent_this_round": [0, 2700, 3700, 6400, 3700, 0, 200],
+            }
+        )
+        buy_log = build_buy_log(purchases, spent, rounds)
+        assert buy_log["tick"].tolist() == [10, 20, 30, 40, 1010]
+        assert buy_log["action"].tolist() == ["buy", "buy", "buy", "refund", "buy"]
+        assert buy_log["weapon"].tolist() == [
+            "ak47",
+            "assaultsuit",
+            "ak47",
+            "ak47",
+            "flashbang",
+        ]
+        assert buy_log["price"].tolist() == [2700, 1000, 2700, 2700, 200]
+        assert buy_log["round"].tolist() == [1, 1, 1, 1, 2]
+
+        # Without purchase events, buys are found from the money spent
+        buy_log = build_buy_log(None, spent, rounds)
+        assert buy_log["action"].tolist() == [
+            "buy",
+            "buy",
+            "buy",
+            "refund",
+            "buy",
+        ]
+        assert buy_log["weapon"].isna().all()
+
+    def test_get_prices(self):
+        """Tests that prices depend on the game version."""
+        assert get_prices()["incgrenade"] == 500
+        assert get_prices("csgo")["incgrenade"] == 600
+        assert get_prices("csgo")["ak47"] == get_prices("cs2")["ak47"]
+        assert get_game_version({"demo_file_stamp": "HL2DEMO"}) == "csgo"
+        assert get_game_version({"demo_file_stamp": "PBDEMS2"}) == "cs2"
+        with pytest.raises(ValueError, match="Unknown game version"):
+            get_prices("cs3")
+
     def test_hltv_rounds(
         self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
     ):
@@ -201,9 +2013,125 @@ class TestParsers:
             "t_killed",
         ]
 
+    def test_hltv_scopes(self, hltv_parser: DemoParser):
+        """Tests that we can get scope events from HLTV demos."""
+        hltv_scopes = parse_scopes(hltv_parser)
+        assert hltv_scopes.shape[0] > 0
+        assert set(hltv_scopes["event"].unique()) <= {"scope_in", "scope_out"}
+
+    def test_hltv_kill_ticks(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that players are parsed on HLTV kill ticks and the ticks before."""
+        kills = parse_kills(hltv_events)
+        kill_ticks = parse_kill_ticks(hltv_parser, kills)
+        assert set(kills["tick"]) <= set(kill_ticks["tick"])
+        assert set(kills["tick"] - 1) <= set(kill_ticks["tick"])
+        assert set(KILL_TICK_PROPS) <= set(kill_ticks.columns)
+
+    def test_hltv_supporting_teammates(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that supporting teammates are counted on HLTV kills."""
+        kills = parse_kills(hltv_events)
+        hltv_kills = parse_supporting_teammates(
+            parse_kill_ticks(hltv_parser, kills), kills
+        )
+        assert hltv_kills["supporting_teammates"].between(0, 4).all()
+        assert (
+            hltv_kills["supporting_teammates"]
+            == hltv_kills["supporting_teammate_steamids"].map(len)
+        ).all()
+        assert hltv_kills["supporting_teammates"].sum() > 0
+
+    def test_hltv_victim_equipment(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that victims' loadouts are valued on HLTV kills."""
+        kills = parse_kills(hltv_events)
+        hltv_kills = parse_victim_equipment(parse_kill_ticks(hltv_parser, kills), kills)
+        assert hltv_kills.shape[0] == parse_kills(hltv_events).shape[0]
+        assert hltv_kills["victim_equipment_value"].notna().all()
+        assert hltv_kills["victim_has_primary"].sum() > 0
+        assert (
+            hltv_kills["victim_has_primary"] == (hltv_kills["victim_primary_value"] > 0)
+        ).all()
+
+    def test_hltv_victim_positions(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that victims' last alive positions are close to the kill event's."""
+        kills = parse_kills(hltv_events)
+        hltv_kills = parse_victim_positions(parse_kill_ticks(hltv_parser, kills), kills)
+        assert hltv_kills.shape[0] == parse_kills(hltv_events).shape[0]
+        assert hltv_kills["victim_alive_X"].notna().all()
+        assert (hltv_kills["victim_position_discrepancy"] >= 0).all()
+        assert hltv_kills["victim_position_discrepancy"].median() < 50
+
+    def test_hltv_attacker_ammo(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that attackers' ammo is read on HLTV kills."""
+        kills = parse_kills(hltv_events)
+        hltv_kills = parse_attacker_ammo(parse_kill_ticks(hltv_parser, kills), kills)
+        assert hltv_kills.shape[0] == parse_kills(hltv_events).shape[0]
+        gun_kills = hltv_kills[hltv_kills["weapon"].isin(["ak47", "m4a1", "awp"])]
+        assert gun_kills["attacker_ammo_clip"].notna().all()
+        assert (gun_kills["attacker_ammo_clip"] >= 0).all()
+        assert (gun_kills["attacker_ammo_reserve"] >= 0).all()
+
+    def test_hltv_survival(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that players either die once or survive each HLTV round."""
+        hltv_rounds = parse_rounds(hltv_parser, hltv_events)
+        hltv_kills = apply_round_num(hltv_rounds, parse_kills(hltv_events))
+        survival = parse_survival(
+            hltv_parser,
+            hltv_rounds,
+            parse_spawns(hltv_parser, hltv_rounds),
+            hltv_kills,
+        )
+        assert survival.groupby("round").size().max() <= 10
+        assert survival.loc[survival["survived"], "death_place"].isna().all()
+        assert survival.loc[~survival["survived"], "death_X"].notna().all()
+        assert not survival.loc[~survival["survived"], "saved_weapon"].any()
+        assert (survival["survival_seconds"] >= 0).all()
+
+    def test_hltv_network(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that the ping timeline only keeps changes in ping."""
+        hltv_rounds = parse_rounds(hltv_parser, hltv_events)
+        network = parse_network(hltv_parser, hltv_rounds)
+        assert network["steamid"].nunique() == 10
+        assert (network["ping"] >= 0).all()
+        assert (network.groupby("steamid")["ping"].diff().dropna() != 0).all()
+        assert parse_network(hltv_parser, hltv_rounds.iloc[0:0]).shape[0] == 0
+
+    def test_hltv_agents(
+        self, hltv_parser: DemoParser, hltv_events: dict[str, pd.DataFrame]
+    ):
+        """Tests that each player's agent is kept only when it changes."""
+        hltv_rounds = parse_rounds(hltv_parser, hltv_events)
+        agents = parse_agents(hltv_parser, hltv_rounds)
+        assert agents["steamid"].nunique() == 10
+        assert agents["agent"].notna().all()
+        assert not agents.duplicated(["steamid", "round"]).any()
+        assert agents.shape[0] < 10 * hltv_rounds.shape[0]
+        assert parse_agents(hltv_parser, hltv_rounds.iloc[0:0]).shape[0] == 0
+
     def test_hltv_kills(self, hltv_events: dict[str, pd.DataFrame]):
         """Tests that we can get correct kills from HLTV demos."""
         hltv_kills = parse_kills(hltv_events)
+        assert hltv_kills["kill_feed_index"].is_monotonic_increasing
+        assert hltv_kills["tick"].is_monotonic_increasing
+        assert (hltv_kills["tick_kill_index"] >= 0).all()
+        assert set(hltv_kills["victim_stance"].dropna().unique()) <= {
+            "standing",
+            "crouching",
+            "airborne",
+        }
         # Checks kills and headshots
         assert hltv_kills.shape[0] == 159
         assert (
@@ -248,6 +2176,11 @@ class TestParsers:
     def test_hltv_damages(self, hltv_events: dict[str, pd.DataFrame]):
         """Tests that we can get correct damages from HLTV demos."""
         hltv_damage = parse_damages(hltv_events)
+        assert (hltv_damage["victim_health_after"] >= 0).all()
+        assert (
+            hltv_damage["victim_health_before"] - hltv_damage["victim_health_after"]
+            == hltv_damage["dmg_health_real"]
+        ).all()
         hltv_damage_total = round(
             hltv_damage[
                 hltv_damage["attacker_team_name"] != hltv_damage["victim_team_name"]